@@ -0,0 +1,99 @@
+package gantt
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultASCIIWidth is the number of columns the time axis of an ASCII chart
+// spans unless told otherwise
+const DefaultASCIIWidth = 72
+
+// WriteASCII draws the chart as text for a terminal, one row per lane above a
+// time axis that spans width columns, or DefaultASCIIWidth if width is not
+// positive. Segments are separated by | and run segments show their label as
+// far as it fits. Context switches are filled with # and idle time with .,
+// and segments shorter than a column may not show at all
+func (c *Chart) WriteASCII(w io.Writer, width int) error {
+	if width <= 0 {
+		width = DefaultASCIIWidth
+	}
+	unit, unitName := timeUnit(c.End)
+
+	column := func(t time.Duration) int {
+		if c.End == 0 {
+			return 0
+		}
+		return int(math.Round(float64(t) / float64(c.End) * float64(width)))
+	}
+
+	nameWidth := utf8.RuneCountInString(unitName)
+	for _, lane := range c.Lanes {
+		nameWidth = max(nameWidth, utf8.RuneCountInString(lane.Name))
+	}
+
+	var b strings.Builder
+	kinds := make(map[SegmentKind]bool)
+	for _, lane := range c.Lanes {
+		row := []rune(strings.Repeat(" ", width+1))
+		for _, s := range lane.Segments {
+			kinds[s.Kind] = true
+			from, to := column(s.Start), column(s.End)
+			for i := from + 1; i < to; i++ {
+				switch s.Kind {
+				case Switch:
+					row[i] = '#'
+				case Idle:
+					row[i] = '.'
+				}
+			}
+			if s.Kind == Run {
+				label := []rune(s.Label)
+				copy(row[from+1:max(to, from+1)], label)
+			}
+		}
+		for _, s := range lane.Segments {
+			row[column(s.Start)] = '|'
+			row[column(s.End)] = '|'
+		}
+		if len(lane.Segments) == 0 {
+			row[0] = '|'
+		}
+		fmt.Fprintf(&b, "%s %s\n", pad(lane.Name, nameWidth), strings.TrimRight(string(row), " "))
+	}
+
+	// Label as many boundaries as fit without running into each other
+	var axis []rune
+	for _, t := range c.boundaries() {
+		at := column(t)
+		if at < len(axis) {
+			continue
+		}
+		axis = append(axis, []rune(strings.Repeat(" ", at-len(axis)))...)
+		axis = append(axis, []rune(formatTime(t, unit)+" ")...)
+	}
+	fmt.Fprintf(&b, "%s %s\n", pad(unitName, nameWidth), strings.TrimRight(string(axis), " "))
+
+	var legend []string
+	if kinds[Switch] {
+		legend = append(legend, "# context switch")
+	}
+	if kinds[Idle] {
+		legend = append(legend, ". idle")
+	}
+	if len(legend) > 0 {
+		fmt.Fprintf(&b, "%s %s\n", pad("", nameWidth), strings.Join(legend, "  "))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// pad right pads s with spaces to width runes
+func pad(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-utf8.RuneCountInString(s), 0))
+}
@@ -0,0 +1,81 @@
+package gantt
+
+import (
+	"bytes"
+	"cpu-scheduling/core/internal/queue"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChart_WriteASCII(t *testing.T) {
+	t.Run("should draw the textbook FCFS chart", func(t *testing.T) {
+		c := FromSimulation(simulate(t, queue.NewFCFSQueue(), 0, job("P1", 0, 24), job("P2", 0, 3), job("P3", 0, 3)))
+
+		var buf bytes.Buffer
+		if err := c.WriteASCII(&buf, 30); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := "" +
+			"CPU 0 |P1                     |P2|P3|\n" +
+			"ms    0                       24 27 30\n"
+		if buf.String() != expected {
+			t.Errorf("expected\n%s\ngot\n%s", expected, buf.String())
+		}
+	})
+
+	t.Run("should fill context switches and idle time", func(t *testing.T) {
+		c := FromSimulation(simulate(t, queue.NewFCFSQueue(), ms(2), job("A", 0, 4), job("B", 0, 4), job("C", 20, 4)))
+
+		var buf bytes.Buffer
+		c.WriteASCII(&buf, 26)
+		expected := "" +
+			"CPU 0 |A  |#|B  |.........|#|C  |\n" +
+			"ms    0   4 6   10        20    26\n" +
+			"      # context switch  . idle\n"
+		if buf.String() != expected {
+			t.Errorf("expected\n%s\ngot\n%s", expected, buf.String())
+		}
+	})
+
+	t.Run("should draw a lane per core and skip crowded labels", func(t *testing.T) {
+		c := &Chart{
+			End: 100 * time.Microsecond,
+			Lanes: []Lane{
+				{Name: "CPU 0", Segments: []Segment{
+					{Kind: Run, PID: 1, Label: "long-name", Start: 0, End: 2 * time.Microsecond},
+					{Kind: Run, PID: 2, Label: "P2", Start: 2 * time.Microsecond, End: 100 * time.Microsecond},
+				}},
+				{Name: "CPU 1", Segments: []Segment{
+					{Kind: Idle, Start: 0, End: 100 * time.Microsecond},
+				}},
+			},
+		}
+
+		var buf bytes.Buffer
+		c.WriteASCII(&buf, 20)
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		if len(lines) != 4 {
+			t.Fatalf("expected two lanes, an axis and a legend, got\n%s", buf.String())
+		}
+		if lines[0] != "CPU 0 |P2                 |" {
+			t.Errorf("expected the short segment to vanish, got %q", lines[0])
+		}
+		if lines[1] != "CPU 1 |...................|" {
+			t.Errorf("expected an idle lane, got %q", lines[1])
+		}
+		if lines[2] != "µs    0                   100" {
+			t.Errorf("expected the crowded 2µs label to be left out, got %q", lines[2])
+		}
+	})
+
+	t.Run("should default the width", func(t *testing.T) {
+		c := FromSimulation(simulate(t, queue.NewFCFSQueue(), 0, job("P1", 0, 1)))
+
+		var buf bytes.Buffer
+		c.WriteASCII(&buf, 0)
+		if line := strings.Split(buf.String(), "\n")[0]; len(line) != len("CPU 0 ")+DefaultASCIIWidth+1 {
+			t.Errorf("expected a lane %d columns wide, got %q", DefaultASCIIWidth, line)
+		}
+	})
+}
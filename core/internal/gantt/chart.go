@@ -0,0 +1,201 @@
+package gantt
+
+import (
+	"cpu-scheduling/core/internal/scheduler"
+	"cpu-scheduling/core/internal/simulation"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// SegmentKind tells what a CPU was doing during a segment
+type SegmentKind int
+
+const (
+	// Run is a process running on the CPU
+	Run SegmentKind = iota
+	// Switch is context switch overhead paid before a process runs
+	Switch
+	// Idle is a CPU with nothing to run
+	Idle
+)
+
+func (k SegmentKind) String() string {
+	switch k {
+	case Run:
+		return "run"
+	case Switch:
+		return "switch"
+	case Idle:
+		return "idle"
+	default:
+		return fmt.Sprintf("SegmentKind(%d)", int(k))
+	}
+}
+
+// Segment is a stretch of time on one CPU. PID and Label name the process of
+// Run and Switch segments
+type Segment struct {
+	Kind  SegmentKind
+	PID   int
+	Label string
+	Start time.Duration
+	End   time.Duration
+}
+
+// Lane is the timeline of one CPU. Its segments cover the chart from 0 to its
+// end without gaps, idle time included
+type Lane struct {
+	Name     string
+	Segments []Segment
+}
+
+// Chart is a Gantt chart of a completed run with one lane per CPU. Times are
+// relative to the start of the run
+type Chart struct {
+	Lanes []Lane
+	End   time.Duration
+}
+
+// FromSimulation builds the chart of a simulated run on its single CPU.
+// Processes are labelled by job name, or by PID for jobs without one
+func FromSimulation(result *simulation.Result) *Chart {
+	b := newBuilder(1)
+	for _, s := range result.Timeline {
+		label := result.Jobs[s.Job].Name
+		if label == "" {
+			label = pidLabel(s.PID)
+		}
+		b.add(0, s.PID, label, s.Start, s.Switch, s.End)
+	}
+	return b.chart(result.Makespan)
+}
+
+// FromDispatches builds the chart of a run on a dispatcher or machine from
+// its timeline, with a lane for each of its cpus, idle or not, as given by
+// len(machine.Cores()) or 1 for a dispatcher. Times are counted from origin,
+// or from the first dispatch when origin is zero
+func FromDispatches(events []scheduler.DispatchEvent, cpus int, origin time.Time) *Chart {
+	if origin.IsZero() {
+		for _, e := range events {
+			if origin.IsZero() || e.Start.Before(origin) {
+				origin = e.Start
+			}
+		}
+	}
+
+	cpus = max(cpus, 1)
+	for _, e := range events {
+		cpus = max(cpus, e.CPU+1)
+	}
+
+	b := newBuilder(cpus)
+	for _, e := range events {
+		b.add(e.CPU, e.PID, pidLabel(e.PID), e.Start.Sub(origin), e.Overhead, e.End.Sub(origin))
+	}
+	return b.chart(0)
+}
+
+func pidLabel(pid int) string {
+	return fmt.Sprintf("P%d", pid)
+}
+
+// builder collects the busy segments of every lane before the idle gaps
+// between them are filled in
+type builder struct {
+	lanes [][]Segment
+}
+
+func newBuilder(cpus int) *builder {
+	return &builder{lanes: make([][]Segment, cpus)}
+}
+
+// add records a slice that held the CPU from start to end, the first overhead
+// of which went to the context switch
+func (b *builder) add(cpu, pid int, label string, start, overhead, end time.Duration) {
+	switched := min(start+overhead, end)
+	if switched > start {
+		b.lanes[cpu] = append(b.lanes[cpu], Segment{Kind: Switch, PID: pid, Label: label, Start: start, End: switched})
+	}
+	if end > switched {
+		b.lanes[cpu] = append(b.lanes[cpu], Segment{Kind: Run, PID: pid, Label: label, Start: switched, End: end})
+	}
+}
+
+// chart fills the gaps of every lane with idle time up to the end of the last
+// segment, or up to end if that is later
+func (b *builder) chart(end time.Duration) *Chart {
+	for _, segments := range b.lanes {
+		for _, s := range segments {
+			end = max(end, s.End)
+		}
+	}
+
+	c := &Chart{Lanes: make([]Lane, len(b.lanes)), End: end}
+	for cpu, segments := range b.lanes {
+		sort.SliceStable(segments, func(i, j int) bool {
+			return segments[i].Start < segments[j].Start
+		})
+
+		lane := Lane{Name: fmt.Sprintf("CPU %d", cpu)}
+		var at time.Duration
+		for _, s := range segments {
+			// Slices recorded on a real clock may overlap by a hair
+			s.Start = max(s.Start, at)
+			if s.End <= s.Start {
+				continue
+			}
+			if s.Start > at {
+				lane.Segments = append(lane.Segments, Segment{Kind: Idle, Start: at, End: s.Start})
+			}
+			lane.Segments = append(lane.Segments, s)
+			at = s.End
+		}
+		if at < end {
+			lane.Segments = append(lane.Segments, Segment{Kind: Idle, Start: at, End: end})
+		}
+		c.Lanes[cpu] = lane
+	}
+	return c
+}
+
+// boundaries returns the distinct times at which a segment of any lane starts
+// or ends, in order
+func (c *Chart) boundaries() []time.Duration {
+	seen := map[time.Duration]bool{0: true, c.End: true}
+	for _, lane := range c.Lanes {
+		for _, s := range lane.Segments {
+			seen[s.Start] = true
+			seen[s.End] = true
+		}
+	}
+
+	times := make([]time.Duration, 0, len(seen))
+	for t := range seen {
+		times = append(times, t)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	return times
+}
+
+// timeUnit picks the unit the axis of a chart ending at end is labelled in
+func timeUnit(end time.Duration) (time.Duration, string) {
+	switch {
+	case end >= 10*time.Second:
+		return time.Second, "s"
+	case end >= 10*time.Millisecond:
+		return time.Millisecond, "ms"
+	case end >= 10*time.Microsecond:
+		return time.Microsecond, "µs"
+	default:
+		return time.Nanosecond, "ns"
+	}
+}
+
+// formatTime writes t in the unit with at most two decimals
+func formatTime(t, unit time.Duration) string {
+	v := math.Round(float64(t)/float64(unit)*100) / 100
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
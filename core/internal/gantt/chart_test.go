@@ -0,0 +1,154 @@
+package gantt
+
+import (
+	"cpu-scheduling/core/internal/process"
+	"cpu-scheduling/core/internal/queue"
+	"cpu-scheduling/core/internal/scheduler"
+	"cpu-scheduling/core/internal/simulation"
+	"cpu-scheduling/core/internal/types"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func ms(n float64) time.Duration {
+	return time.Duration(n * float64(time.Millisecond))
+}
+
+func simulate(t *testing.T, q types.SchedulingQueue, contextSwitch time.Duration, jobs ...simulation.Job) *simulation.Result {
+	t.Helper()
+
+	s, err := simulation.New(simulation.Config{Queue: q, Jobs: jobs, ContextSwitch: contextSwitch})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := s.Run()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+// job is a job with a single CPU burst, with times in milliseconds
+func job(name string, arrival, burst float64) simulation.Job {
+	return simulation.Job{Name: name, Arrival: ms(arrival), Bursts: []simulation.Burst{{CPU: ms(burst)}}}
+}
+
+func TestFromSimulation(t *testing.T) {
+	t.Run("should chart runs, context switches and idle gaps", func(t *testing.T) {
+		result := simulate(t, queue.NewFCFSQueue(), ms(1), job("A", 0, 4), job("", 0, 2), job("C", 10, 3))
+
+		c := FromSimulation(result)
+		expected := []Segment{
+			{Kind: Run, PID: 1, Label: "A", Start: 0, End: ms(4)},
+			{Kind: Switch, PID: 2, Label: "P2", Start: ms(4), End: ms(5)},
+			{Kind: Run, PID: 2, Label: "P2", Start: ms(5), End: ms(7)},
+			{Kind: Idle, Start: ms(7), End: ms(10)},
+			{Kind: Switch, PID: 3, Label: "C", Start: ms(10), End: ms(11)},
+			{Kind: Run, PID: 3, Label: "C", Start: ms(11), End: ms(14)},
+		}
+		if len(c.Lanes) != 1 || c.Lanes[0].Name != "CPU 0" || c.End != ms(14) {
+			t.Fatalf("expected a single lane up to 14ms, got %+v", c)
+		}
+		if !reflect.DeepEqual(c.Lanes[0].Segments, expected) {
+			t.Errorf("expected segments\n%+v\ngot\n%+v", expected, c.Lanes[0].Segments)
+		}
+	})
+
+	t.Run("should start with idle time until the first arrival", func(t *testing.T) {
+		c := FromSimulation(simulate(t, queue.NewFCFSQueue(), 0, job("late", 5, 1)))
+
+		segments := c.Lanes[0].Segments
+		if len(segments) != 2 || segments[0].Kind != Idle || segments[0].End != ms(5) {
+			t.Errorf("expected idle time before the arrival, got %+v", segments)
+		}
+	})
+}
+
+func TestFromDispatches(t *testing.T) {
+	t.Run("should give every CPU a lane", func(t *testing.T) {
+		origin := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		at := func(n float64) time.Time { return origin.Add(ms(n)) }
+		events := []scheduler.DispatchEvent{
+			{CPU: 0, PID: 1, Start: at(1), End: at(5), Outcome: types.SliceExpired},
+			{CPU: 2, PID: 2, Start: at(2), End: at(6), Overhead: ms(1), Outcome: types.SliceCompleted},
+			{CPU: 0, PID: 1, Start: at(5), End: at(8), Outcome: types.SliceCompleted},
+		}
+
+		c := FromDispatches(events, 3, origin)
+		if len(c.Lanes) != 3 || c.End != ms(8) {
+			t.Fatalf("expected 3 lanes up to 8ms, got %+v", c)
+		}
+
+		expected := [][]Segment{
+			{
+				{Kind: Idle, Start: 0, End: ms(1)},
+				{Kind: Run, PID: 1, Label: "P1", Start: ms(1), End: ms(5)},
+				{Kind: Run, PID: 1, Label: "P1", Start: ms(5), End: ms(8)},
+			},
+			{
+				{Kind: Idle, Start: 0, End: ms(8)},
+			},
+			{
+				{Kind: Idle, Start: 0, End: ms(2)},
+				{Kind: Switch, PID: 2, Label: "P2", Start: ms(2), End: ms(3)},
+				{Kind: Run, PID: 2, Label: "P2", Start: ms(3), End: ms(6)},
+				{Kind: Idle, Start: ms(6), End: ms(8)},
+			},
+		}
+		for i, lane := range c.Lanes {
+			if !reflect.DeepEqual(lane.Segments, expected[i]) {
+				t.Errorf("lane %d: expected\n%+v\ngot\n%+v", i, expected[i], lane.Segments)
+			}
+		}
+
+		if c := FromDispatches(events, 3, time.Time{}); c.End != ms(7) || c.Lanes[0].Segments[0].Kind != Run {
+			t.Errorf("expected the chart to start at the first dispatch without an origin, got %+v", c)
+		}
+	})
+
+	t.Run("should draw lanes for cores that never ran anything", func(t *testing.T) {
+		origin := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		events := []scheduler.DispatchEvent{
+			{CPU: 0, PID: 1, Start: origin, End: origin.Add(ms(4)), Outcome: types.SliceCompleted},
+		}
+
+		c := FromDispatches(events, 4, origin)
+		if len(c.Lanes) != 4 {
+			t.Fatalf("expected a lane per core, got %d", len(c.Lanes))
+		}
+		for i, lane := range c.Lanes[1:] {
+			expected := []Segment{{Kind: Idle, Start: 0, End: ms(4)}}
+			if !reflect.DeepEqual(lane.Segments, expected) || lane.Name != fmt.Sprintf("CPU %d", i+1) {
+				t.Errorf("expected CPU %d to be idle throughout, got %+v", i+1, lane)
+			}
+		}
+	})
+
+	t.Run("should chart the timeline of a dispatcher", func(t *testing.T) {
+		manager := process.NewManager()
+		d, _ := scheduler.NewDispatcher(queue.NewFCFSQueue(), manager)
+		for i := 0; i < 2; i++ {
+			p, _ := manager.CreateProcess(&types.SimpleTask{ExecuteFn: func() (any, error) {
+				time.Sleep(5 * time.Millisecond)
+				return nil, nil
+			}})
+			d.Submit(p)
+		}
+		d.Start()
+		d.Wait()
+		d.Stop()
+
+		c := FromDispatches(d.Timeline(), 1, time.Time{})
+		var runs []int
+		for _, s := range c.Lanes[0].Segments {
+			if s.Kind == Run {
+				runs = append(runs, s.PID)
+			}
+		}
+		if !reflect.DeepEqual(runs, []int{1, 2}) || c.End < 10*time.Millisecond {
+			t.Errorf("expected both processes to run in order for at least 10ms, got %v over %v", runs, c.End)
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package gantt
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultSVGWidth is the width in pixels of an SVG chart unless told
+// otherwise
+const DefaultSVGWidth = 800
+
+const (
+	svgLaneHeight = 28
+	svgLaneGap    = 8
+	svgMargin     = 10
+	svgAxisHeight = 24
+	// svgCharWidth approximates the width of a character of the 12px
+	// monospace font, to tell whether a label fits
+	svgCharWidth = 7.2
+)
+
+// svgPalette colours processes by PID, so a process keeps its colour across
+// its slices and lanes
+var svgPalette = []string{
+	"#4e79a7", "#f28e2b", "#e15759", "#76b7b2", "#59a14f",
+	"#edc948", "#b07aa1", "#ff9da7", "#9c755f", "#bab0ac",
+}
+
+// WriteSVG draws the chart as an SVG image width pixels wide, or
+// DefaultSVGWidth if width is not positive, with one lane per CPU above a
+// time axis. Run segments are coloured by process, context switches are dark
+// grey and idle time light grey, and every segment has a tooltip with its
+// exact times
+func (c *Chart) WriteSVG(w io.Writer, width int) error {
+	if width <= 0 {
+		width = DefaultSVGWidth
+	}
+	unit, unitName := timeUnit(c.End)
+
+	nameWidth := 0
+	for _, lane := range c.Lanes {
+		nameWidth = max(nameWidth, utf8.RuneCountInString(lane.Name))
+	}
+	left := float64(svgMargin) + float64(nameWidth)*svgCharWidth + svgMargin
+	plot := max(float64(width)-left-2*svgMargin, 1)
+	height := svgMargin + len(c.Lanes)*(svgLaneHeight+svgLaneGap) + svgAxisHeight + svgMargin
+
+	x := func(t float64) float64 {
+		if c.End == 0 {
+			return left
+		}
+		return left + t/float64(c.End)*plot
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="monospace" font-size="12">`+"\n",
+		width, height, width, height)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="white"/>`+"\n", width, height)
+
+	for i, lane := range c.Lanes {
+		y := svgMargin + i*(svgLaneHeight+svgLaneGap)
+		mid := y + svgLaneHeight/2
+		fmt.Fprintf(&b, `<text x="%d" y="%d" dominant-baseline="middle">%s</text>`+"\n", svgMargin, mid, html.EscapeString(lane.Name))
+
+		for _, s := range lane.Segments {
+			x0, x1 := x(float64(s.Start)), x(float64(s.End))
+			fill, title := "#eeeeee", fmt.Sprintf("idle %v to %v", s.Start, s.End)
+			switch s.Kind {
+			case Run:
+				fill = svgPalette[s.PID%len(svgPalette)]
+				title = fmt.Sprintf("%s %v to %v", s.Label, s.Start, s.End)
+			case Switch:
+				fill = "#555555"
+				title = fmt.Sprintf("switch to %s %v to %v", s.Label, s.Start, s.End)
+			}
+			fmt.Fprintf(&b, `<rect x="%.2f" y="%d" width="%.2f" height="%d" fill="%s" stroke="white"><title>%s</title></rect>`+"\n",
+				x0, y, x1-x0, svgLaneHeight, fill, html.EscapeString(title))
+
+			if s.Kind == Run && float64(utf8.RuneCountInString(s.Label))*svgCharWidth+4 <= x1-x0 {
+				fmt.Fprintf(&b, `<text x="%.2f" y="%d" text-anchor="middle" dominant-baseline="middle" fill="white">%s</text>`+"\n",
+					(x0+x1)/2, mid, html.EscapeString(s.Label))
+			}
+		}
+	}
+
+	// Label as many boundaries as fit without running into each other
+	axis := svgMargin + len(c.Lanes)*(svgLaneHeight+svgLaneGap)
+	fmt.Fprintf(&b, `<line x1="%.2f" y1="%d" x2="%.2f" y2="%d" stroke="black"/>`+"\n", left, axis, left+plot, axis)
+	free := 0.0
+	for _, t := range c.boundaries() {
+		at := x(float64(t))
+		fmt.Fprintf(&b, `<line x1="%.2f" y1="%d" x2="%.2f" y2="%d" stroke="black"/>`+"\n", at, axis, at, axis+4)
+
+		label := formatTime(t, unit)
+		half := float64(utf8.RuneCountInString(label)) * svgCharWidth / 2
+		if at-half < free {
+			continue
+		}
+		fmt.Fprintf(&b, `<text x="%.2f" y="%d" text-anchor="middle">%s</text>`+"\n", at, axis+16, label)
+		free = at + half + svgCharWidth
+	}
+	fmt.Fprintf(&b, `<text x="%d" y="%d">%s</text>`+"\n", svgMargin, axis+16, unitName)
+	b.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
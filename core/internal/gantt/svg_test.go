@@ -0,0 +1,91 @@
+package gantt
+
+import (
+	"bytes"
+	"cpu-scheduling/core/internal/queue"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// svgElements parses an SVG document and counts its elements by name, with
+// the text of every text element
+func svgElements(t *testing.T, data []byte) (map[string]int, []string) {
+	t.Helper()
+
+	counts := make(map[string]int)
+	var texts []string
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var inText bool
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("expected well formed SVG, got %v in\n%s", err, data)
+		}
+		switch tok := token.(type) {
+		case xml.StartElement:
+			counts[tok.Name.Local]++
+			inText = tok.Name.Local == "text"
+		case xml.CharData:
+			if inText {
+				texts = append(texts, string(tok))
+			}
+		case xml.EndElement:
+			inText = false
+		}
+	}
+	return counts, texts
+}
+
+func TestChart_WriteSVG(t *testing.T) {
+	t.Run("should draw a rectangle per segment with labels and an axis", func(t *testing.T) {
+		c := FromSimulation(simulate(t, queue.NewFCFSQueue(), ms(1), job("A", 0, 24), job("B", 0, 3), job("C", 40, 3)))
+
+		var buf bytes.Buffer
+		if err := c.WriteSVG(&buf, 0); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(buf.String(), `width="800"`) {
+			t.Error("expected the default width")
+		}
+
+		counts, texts := svgElements(t, buf.Bytes())
+		// A background and A, a switch, B, idle, a switch and C
+		if counts["rect"] != 7 || counts["title"] != 6 {
+			t.Errorf("expected a rectangle with a tooltip per segment, got %v", counts)
+		}
+		joined := strings.Join(texts, " ")
+		for _, want := range []string{"CPU 0", "A", "B", "C", "0", "24", "44", "ms"} {
+			if !strings.Contains(" "+joined+" ", " "+want+" ") {
+				t.Errorf("expected text %q, got %q", want, joined)
+			}
+		}
+	})
+
+	t.Run("should escape labels", func(t *testing.T) {
+		c := FromSimulation(simulate(t, queue.NewFCFSQueue(), 0, job("<a&b>", 0, 10)))
+
+		var buf bytes.Buffer
+		c.WriteSVG(&buf, 400)
+		_, texts := svgElements(t, buf.Bytes())
+		if !strings.Contains(strings.Join(texts, " "), "<a&b>") {
+			t.Errorf("expected the label to survive escaping, got %v", texts)
+		}
+	})
+
+	t.Run("should draw a lane per core", func(t *testing.T) {
+		c := &Chart{Lanes: []Lane{{Name: "CPU 0"}, {Name: "CPU 1"}, {Name: "CPU 2"}}}
+
+		var buf bytes.Buffer
+		c.WriteSVG(&buf, 300)
+		_, texts := svgElements(t, buf.Bytes())
+		if joined := strings.Join(texts, " "); !strings.Contains(joined, "CPU 0 CPU 1 CPU 2") {
+			t.Errorf("expected three lanes, got %q", joined)
+		}
+	})
+}
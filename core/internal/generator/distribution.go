@@ -0,0 +1,204 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Distribution draws random durations from a statistical model
+type Distribution interface {
+	Sample(rng *rand.Rand) time.Duration
+}
+
+// validator is implemented by the built-in distributions to check their
+// parameters before anything is drawn
+type validator interface {
+	validate() error
+}
+
+// Constant always draws the same duration
+type Constant struct {
+	Value time.Duration
+}
+
+func (d Constant) Sample(rng *rand.Rand) time.Duration {
+	return d.Value
+}
+
+func (d Constant) validate() error {
+	if d.Value < 0 {
+		return fmt.Errorf("constant cannot be negative")
+	}
+	return nil
+}
+
+// Uniform draws durations evenly between Min and Max
+type Uniform struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+func (d Uniform) Sample(rng *rand.Rand) time.Duration {
+	return d.Min + time.Duration(rng.Float64()*float64(d.Max-d.Min))
+}
+
+func (d Uniform) validate() error {
+	if d.Min < 0 || d.Max < d.Min {
+		return fmt.Errorf("uniform needs 0 <= min <= max, got %v and %v", d.Min, d.Max)
+	}
+	return nil
+}
+
+// Exponential draws memoryless durations with the given mean, the classic
+// model of CPU bursts and of the gaps between Poisson arrivals
+type Exponential struct {
+	Mean time.Duration
+}
+
+func (d Exponential) Sample(rng *rand.Rand) time.Duration {
+	return time.Duration(rng.ExpFloat64() * float64(d.Mean))
+}
+
+func (d Exponential) validate() error {
+	if d.Mean <= 0 {
+		return fmt.Errorf("exponential needs a positive mean, got %v", d.Mean)
+	}
+	return nil
+}
+
+// Phase is one exponential branch of a hyperexponential distribution, taken
+// with the given probability
+type Phase struct {
+	Probability float64
+	Mean        time.Duration
+}
+
+// HyperExponential mixes exponential phases, which models bursts that are
+// mostly short with occasional long ones. The probabilities must add up to 1
+type HyperExponential struct {
+	Phases []Phase
+}
+
+func (d HyperExponential) Sample(rng *rand.Rand) time.Duration {
+	u := rng.Float64()
+	for _, phase := range d.Phases {
+		if u < phase.Probability {
+			return Exponential{Mean: phase.Mean}.Sample(rng)
+		}
+		u -= phase.Probability
+	}
+	return Exponential{Mean: d.Phases[len(d.Phases)-1].Mean}.Sample(rng)
+}
+
+func (d HyperExponential) validate() error {
+	if len(d.Phases) == 0 {
+		return fmt.Errorf("hyperexponential needs at least one phase")
+	}
+
+	total := 0.0
+	for i, phase := range d.Phases {
+		if phase.Probability < 0 || phase.Mean <= 0 {
+			return fmt.Errorf("phase %d needs a non-negative probability and a positive mean", i)
+		}
+		total += phase.Probability
+	}
+	if math.Abs(total-1) > 1e-9 {
+		return fmt.Errorf("phase probabilities must add up to 1, got %v", total)
+	}
+	return nil
+}
+
+// Pareto draws heavy tailed durations of at least Scale. The smaller Shape
+// is, the heavier the tail, and for Shape <= 1 the mean is infinite. Max caps
+// the samples unless it is zero, in which case they stop at the longest
+// duration
+type Pareto struct {
+	Scale time.Duration
+	Shape float64
+	Max   time.Duration
+}
+
+func (d Pareto) Sample(rng *rand.Rand) time.Duration {
+	// Inverse transform of the CDF 1 - (scale/x)^shape, with 1-u in (0, 1]
+	x := float64(d.Scale) / math.Pow(1-rng.Float64(), 1/d.Shape)
+	if d.Max > 0 && x > float64(d.Max) {
+		return d.Max
+	}
+	if x >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(x)
+}
+
+func (d Pareto) validate() error {
+	if d.Scale <= 0 || d.Shape <= 0 {
+		return fmt.Errorf("pareto needs a positive scale and shape, got %v and %v", d.Scale, d.Shape)
+	}
+	if d.Max != 0 && d.Max < d.Scale {
+		return fmt.Errorf("pareto max %v is below its scale %v", d.Max, d.Scale)
+	}
+	return nil
+}
+
+// PriorityDistribution draws static priorities for generated processes
+type PriorityDistribution interface {
+	Sample(rng *rand.Rand) int
+}
+
+// UniformPriority draws priorities evenly from Min to Max, both included
+type UniformPriority struct {
+	Min int
+	Max int
+}
+
+func (d UniformPriority) Sample(rng *rand.Rand) int {
+	return d.Min + rng.Intn(d.Max-d.Min+1)
+}
+
+func (d UniformPriority) validate() error {
+	if d.Max < d.Min {
+		return fmt.Errorf("uniform priority needs min <= max, got %d and %d", d.Min, d.Max)
+	}
+	return nil
+}
+
+// WeightedPriority draws each priority with a probability proportional to
+// its weight
+type WeightedPriority map[int]float64
+
+func (d WeightedPriority) Sample(rng *rand.Rand) int {
+	// Walk the priorities in order so the same seed gives the same draw
+	priorities := make([]int, 0, len(d))
+	total := 0.0
+	for priority, weight := range d {
+		priorities = append(priorities, priority)
+		total += weight
+	}
+	sort.Ints(priorities)
+
+	u := rng.Float64() * total
+	for _, priority := range priorities {
+		if u < d[priority] {
+			return priority
+		}
+		u -= d[priority]
+	}
+	return priorities[len(priorities)-1]
+}
+
+func (d WeightedPriority) validate() error {
+	total := 0.0
+	for priority, weight := range d {
+		if weight < 0 {
+			return fmt.Errorf("priority %d has a negative weight", priority)
+		}
+		total += weight
+	}
+	if total <= 0 {
+		return fmt.Errorf("weighted priority needs a positive total weight")
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package generator
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// mean averages n samples of d
+func mean(d Distribution, n int) time.Duration {
+	rng := rand.New(rand.NewSource(7))
+	var total time.Duration
+	for i := 0; i < n; i++ {
+		total += d.Sample(rng)
+	}
+	return total / time.Duration(n)
+}
+
+func within(got, want time.Duration, tolerance float64) bool {
+	return math.Abs(float64(got-want)) <= tolerance*float64(want)
+}
+
+func TestDistributions(t *testing.T) {
+	t.Run("should draw samples with the expected mean", func(t *testing.T) {
+		tests := []struct {
+			name string
+			dist Distribution
+			mean time.Duration
+		}{
+			{"constant", Constant{Value: 3 * time.Millisecond}, 3 * time.Millisecond},
+			{"uniform", Uniform{Min: 2 * time.Millisecond, Max: 4 * time.Millisecond}, 3 * time.Millisecond},
+			{"exponential", Exponential{Mean: 5 * time.Millisecond}, 5 * time.Millisecond},
+			{"hyperexponential", HyperExponential{Phases: []Phase{
+				{Probability: 0.8, Mean: time.Millisecond},
+				{Probability: 0.2, Mean: 21 * time.Millisecond},
+			}}, 5 * time.Millisecond},
+			// Scale * Shape / (Shape - 1)
+			{"pareto", Pareto{Scale: 2 * time.Millisecond, Shape: 3}, 3 * time.Millisecond},
+		}
+
+		for _, tt := range tests {
+			if got := mean(tt.dist, 50000); !within(got, tt.mean, 0.05) {
+				t.Errorf("%s: expected mean near %v, got %v", tt.name, tt.mean, got)
+			}
+		}
+	})
+
+	t.Run("should keep pareto samples between scale and max", func(t *testing.T) {
+		d := Pareto{Scale: time.Millisecond, Shape: 0.8, Max: 50 * time.Millisecond}
+		rng := rand.New(rand.NewSource(1))
+		capped := 0
+		for i := 0; i < 10000; i++ {
+			s := d.Sample(rng)
+			if s < d.Scale || s > d.Max {
+				t.Fatalf("sample %v outside [%v, %v]", s, d.Scale, d.Max)
+			}
+			if s == d.Max {
+				capped++
+			}
+		}
+		if capped == 0 {
+			t.Error("expected the heavy tail to reach the cap")
+		}
+	})
+
+	t.Run("should clamp uncapped pareto samples to the longest duration", func(t *testing.T) {
+		d := Pareto{Scale: time.Millisecond, Shape: 0.05}
+		rng := rand.New(rand.NewSource(1))
+		clamped := 0
+		for i := 0; i < 1000; i++ {
+			s := d.Sample(rng)
+			if s < d.Scale {
+				t.Fatalf("sample %v below the scale %v", s, d.Scale)
+			}
+			if s == math.MaxInt64 {
+				clamped++
+			}
+		}
+		if clamped == 0 {
+			t.Error("expected the heavy tail to reach the longest duration")
+		}
+	})
+
+	t.Run("should reject invalid parameters", func(t *testing.T) {
+		invalid := []validator{
+			Constant{Value: -1},
+			Uniform{Min: 2, Max: 1},
+			Exponential{},
+			HyperExponential{},
+			HyperExponential{Phases: []Phase{{Probability: 0.5, Mean: time.Millisecond}}},
+			Pareto{Scale: time.Millisecond},
+			Pareto{Scale: time.Millisecond, Shape: 2, Max: time.Microsecond},
+			UniformPriority{Min: 5, Max: 4},
+			WeightedPriority{},
+			WeightedPriority{1: -1, 2: 2},
+		}
+		for _, d := range invalid {
+			if err := d.validate(); err == nil {
+				t.Errorf("expected error for %#v", d)
+			}
+		}
+	})
+}
+
+func TestPriorityDistributions(t *testing.T) {
+	t.Run("should draw uniform priorities within bounds", func(t *testing.T) {
+		d := UniformPriority{Min: 10, Max: 12}
+		rng := rand.New(rand.NewSource(1))
+		seen := make(map[int]bool)
+		for i := 0; i < 1000; i++ {
+			p := d.Sample(rng)
+			if p < 10 || p > 12 {
+				t.Fatalf("priority %d out of bounds", p)
+			}
+			seen[p] = true
+		}
+		if len(seen) != 3 {
+			t.Errorf("expected all three priorities, got %v", seen)
+		}
+	})
+
+	t.Run("should draw weighted priorities in proportion", func(t *testing.T) {
+		d := WeightedPriority{5: 1, 20: 3}
+		rng := rand.New(rand.NewSource(1))
+		counts := make(map[int]int)
+		for i := 0; i < 10000; i++ {
+			counts[d.Sample(rng)]++
+		}
+		if ratio := float64(counts[20]) / float64(counts[5]); ratio < 2.7 || ratio > 3.3 {
+			t.Errorf("expected about three times as many 20s as 5s, got %v", counts)
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package generator
+
+import (
+	"cpu-scheduling/core/internal/simulation"
+	"cpu-scheduling/core/internal/types"
+	"cpu-scheduling/core/internal/workload"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Profile describes one kind of process: how many CPU bursts it runs and how
+// long its CPU bursts and the I/O between them are
+type Profile struct {
+	Name      string
+	MinBursts int
+	MaxBursts int
+	CPU       Distribution
+	IO        Distribution
+}
+
+// Config configures a synthetic workload. Processes arrive as a Poisson
+// process with ArrivalRate arrivals per second, and each one is I/O bound with
+// probability IOBoundFraction and CPU bound otherwise. Priorities are left at
+// the process default when nil. The same Seed always generates the same
+// workload
+type Config struct {
+	Seed            int64
+	Count           int
+	ArrivalRate     float64
+	CPUBound        Profile
+	IOBound         Profile
+	IOBoundFraction float64
+	Priorities      PriorityDistribution
+}
+
+// DefaultConfig returns a mix of long, heavy tailed CPU bound processes and
+// interactive processes with short bursts and long waits for I/O
+func DefaultConfig() Config {
+	return Config{
+		Seed:        1,
+		Count:       20,
+		ArrivalRate: 100,
+		CPUBound: Profile{
+			Name:      "cpu",
+			MinBursts: 1,
+			MaxBursts: 3,
+			CPU:       Pareto{Scale: 5 * time.Millisecond, Shape: 1.5, Max: 200 * time.Millisecond},
+			IO:        Exponential{Mean: 2 * time.Millisecond},
+		},
+		IOBound: Profile{
+			Name:      "io",
+			MinBursts: 3,
+			MaxBursts: 8,
+			CPU: HyperExponential{Phases: []Phase{
+				{Probability: 0.9, Mean: 500 * time.Microsecond},
+				{Probability: 0.1, Mean: 5 * time.Millisecond},
+			}},
+			IO: Exponential{Mean: 10 * time.Millisecond},
+		},
+		IOBoundFraction: 0.5,
+		Priorities:      UniformPriority{Min: 10, Max: 30},
+	}
+}
+
+// Generate draws a workload from the config. Durations are rounded to the
+// microsecond so that the workload survives a round trip through a file, and
+// priorities are clamped to the valid range
+func Generate(config Config) (*workload.Workload, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	rng := rand.New(rand.NewSource(config.Seed))
+	arrivals := Exponential{Mean: time.Duration(float64(time.Second) / config.ArrivalRate)}
+
+	w := &workload.Workload{Processes: make([]workload.Spec, config.Count)}
+	var arrival time.Duration
+	for i := range w.Processes {
+		if i > 0 {
+			arrival += arrivals.Sample(rng)
+		}
+
+		profile := config.CPUBound
+		if rng.Float64() < config.IOBoundFraction {
+			profile = config.IOBound
+		}
+
+		spec := workload.Spec{
+			Name:    fmt.Sprintf("%s-%d", profile.Name, i),
+			Arrival: arrival.Round(time.Microsecond),
+			Bursts:  make([]simulation.Burst, profile.MinBursts+rng.Intn(profile.MaxBursts-profile.MinBursts+1)),
+		}
+		for j := range spec.Bursts {
+			spec.Bursts[j].CPU = max(profile.CPU.Sample(rng).Round(time.Microsecond), time.Microsecond)
+			if j < len(spec.Bursts)-1 {
+				spec.Bursts[j].IO = profile.IO.Sample(rng).Round(time.Microsecond)
+			}
+		}
+		if config.Priorities != nil {
+			priority := min(max(config.Priorities.Sample(rng), types.HighestPriority), types.LowestPriority)
+			spec.Priority = &priority
+		}
+		w.Processes[i] = spec
+	}
+	return w, nil
+}
+
+func (c Config) validate() error {
+	if c.Count <= 0 {
+		return fmt.Errorf("count must be positive, got %d", c.Count)
+	}
+	if c.ArrivalRate <= 0 {
+		return fmt.Errorf("arrival rate must be positive, got %v", c.ArrivalRate)
+	}
+	if c.IOBoundFraction < 0 || c.IOBoundFraction > 1 {
+		return fmt.Errorf("I/O bound fraction must be between 0 and 1, got %v", c.IOBoundFraction)
+	}
+	if c.IOBoundFraction < 1 {
+		if err := c.CPUBound.validate(); err != nil {
+			return fmt.Errorf("CPU bound profile: %v", err)
+		}
+	}
+	if c.IOBoundFraction > 0 {
+		if err := c.IOBound.validate(); err != nil {
+			return fmt.Errorf("I/O bound profile: %v", err)
+		}
+	}
+	if v, ok := c.Priorities.(validator); ok {
+		if err := v.validate(); err != nil {
+			return fmt.Errorf("priorities: %v", err)
+		}
+	}
+	return nil
+}
+
+func (p Profile) validate() error {
+	if p.MinBursts <= 0 || p.MaxBursts < p.MinBursts {
+		return fmt.Errorf("needs 0 < min bursts <= max bursts, got %d and %d", p.MinBursts, p.MaxBursts)
+	}
+	if p.CPU == nil {
+		return fmt.Errorf("needs a CPU burst distribution")
+	}
+	if p.IO == nil && p.MaxBursts > 1 {
+		return fmt.Errorf("needs an I/O distribution for processes with several bursts")
+	}
+	for _, d := range []Distribution{p.CPU, p.IO} {
+		if v, ok := d.(validator); ok {
+			if err := v.validate(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package generator
+
+import (
+	"cpu-scheduling/core/internal/process"
+	"cpu-scheduling/core/internal/queue"
+	"cpu-scheduling/core/internal/simulation"
+	"cpu-scheduling/core/internal/types"
+	"cpu-scheduling/core/internal/workload"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Run("should generate the same workload for the same seed", func(t *testing.T) {
+		first, err := Generate(DefaultConfig())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		second, _ := Generate(DefaultConfig())
+		if !reflect.DeepEqual(first, second) {
+			t.Error("expected identical workloads for the same seed")
+		}
+
+		config := DefaultConfig()
+		config.Seed = 2
+		other, _ := Generate(config)
+		if reflect.DeepEqual(first, other) {
+			t.Error("expected a different workload for another seed")
+		}
+	})
+
+	t.Run("should generate valid processes in arrival order", func(t *testing.T) {
+		config := DefaultConfig()
+		config.Count = 200
+		w, err := Generate(config)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(w.Processes) != 200 {
+			t.Fatalf("expected 200 processes, got %d", len(w.Processes))
+		}
+
+		var last time.Duration
+		for _, s := range w.Processes {
+			if s.Arrival < last {
+				t.Fatalf("%s arrives at %v, before %v", s.Name, s.Arrival, last)
+			}
+			last = s.Arrival
+
+			profile := config.CPUBound
+			if strings.HasPrefix(s.Name, "io-") {
+				profile = config.IOBound
+			}
+			if len(s.Bursts) < profile.MinBursts || len(s.Bursts) > profile.MaxBursts {
+				t.Errorf("%s has %d bursts", s.Name, len(s.Bursts))
+			}
+			for _, b := range s.Bursts {
+				if b.CPU < time.Microsecond || b.CPU%time.Microsecond != 0 || b.IO%time.Microsecond != 0 {
+					t.Errorf("%s has burst %+v, expected whole microseconds", s.Name, b)
+				}
+			}
+			if s.Priority == nil || *s.Priority < 10 || *s.Priority > 30 {
+				t.Errorf("%s has priority %v, expected 10 to 30", s.Name, s.Priority)
+			}
+		}
+	})
+
+	t.Run("should follow the arrival rate and the I/O bound fraction", func(t *testing.T) {
+		config := DefaultConfig()
+		config.Count = 5000
+		config.ArrivalRate = 1000
+		config.IOBoundFraction = 0.3
+		w, _ := Generate(config)
+
+		gap := w.Processes[len(w.Processes)-1].Arrival / time.Duration(len(w.Processes)-1)
+		if gap < 950*time.Microsecond || gap > 1050*time.Microsecond {
+			t.Errorf("expected a mean gap near 1ms, got %v", gap)
+		}
+
+		io := 0
+		for _, s := range w.Processes {
+			if strings.HasPrefix(s.Name, "io-") {
+				io++
+			}
+		}
+		if fraction := float64(io) / float64(len(w.Processes)); fraction < 0.27 || fraction > 0.33 {
+			t.Errorf("expected about 30%% I/O bound processes, got %v", fraction)
+		}
+	})
+
+	t.Run("should clamp priorities and leave them unset without a distribution", func(t *testing.T) {
+		config := DefaultConfig()
+		config.Priorities = UniformPriority{Min: -10, Max: 50}
+		w, _ := Generate(config)
+		for _, s := range w.Processes {
+			if *s.Priority < types.HighestPriority || *s.Priority > types.LowestPriority {
+				t.Errorf("%s has priority %d out of range", s.Name, *s.Priority)
+			}
+		}
+
+		config.Priorities = nil
+		w, _ = Generate(config)
+		for _, s := range w.Processes {
+			if s.Priority != nil {
+				t.Errorf("%s has priority %d, expected none", s.Name, *s.Priority)
+			}
+		}
+	})
+
+	t.Run("should return error for invalid configs", func(t *testing.T) {
+		tests := map[string]func(*Config){
+			"no processes":     func(c *Config) { c.Count = 0 },
+			"no arrivals":      func(c *Config) { c.ArrivalRate = 0 },
+			"fraction above 1": func(c *Config) { c.IOBoundFraction = 1.5 },
+			"no bursts":        func(c *Config) { c.CPUBound.MinBursts = 0 },
+			"no CPU":           func(c *Config) { c.IOBound.CPU = nil },
+			"no I/O":           func(c *Config) { c.IOBound.IO = nil },
+			"bad distribution": func(c *Config) { c.CPUBound.CPU = Exponential{} },
+			"bad priorities":   func(c *Config) { c.Priorities = UniformPriority{Min: 2, Max: 1} },
+			"max below min":    func(c *Config) { c.CPUBound.MaxBursts = 0 },
+		}
+		for name, modify := range tests {
+			config := DefaultConfig()
+			modify(&config)
+			if _, err := Generate(config); err == nil {
+				t.Errorf("%s: expected error", name)
+			}
+		}
+	})
+
+	t.Run("should ignore the profile that is never drawn", func(t *testing.T) {
+		config := DefaultConfig()
+		config.IOBoundFraction = 0
+		config.IOBound = Profile{}
+		if _, err := Generate(config); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestGenerate_Feeds(t *testing.T) {
+	t.Run("should create processes in a manager", func(t *testing.T) {
+		w, _ := Generate(DefaultConfig())
+		manager := process.NewManager()
+		for _, s := range w.Processes {
+			p, err := s.Create(manager)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.GetPriority() != *s.Priority {
+				t.Errorf("expected priority %d, got %d", *s.Priority, p.GetPriority())
+			}
+		}
+		if created := manager.GetProcessesByState(types.NEW); len(created) != len(w.Processes) {
+			t.Errorf("expected %d new processes, got %d", len(w.Processes), len(created))
+		}
+	})
+
+	t.Run("should run in the simulator", func(t *testing.T) {
+		w, _ := Generate(DefaultConfig())
+		s, err := simulation.New(simulation.Config{Queue: queue.NewRoundRobinQueue(4 * time.Millisecond), Jobs: w.Jobs()})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		result, err := s.Run()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var cpu time.Duration
+		for _, s := range w.Processes {
+			for _, b := range s.Bursts {
+				cpu += b.CPU
+			}
+		}
+		if result.BusyTime != cpu {
+			t.Errorf("expected %v of CPU time, got %v", cpu, result.BusyTime)
+		}
+	})
+
+	t.Run("should save a workload that replays the same", func(t *testing.T) {
+		w, _ := Generate(DefaultConfig())
+		path := filepath.Join(t.TempDir(), "generated.yaml")
+		if err := w.Save(path); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		loaded, err := workload.Load(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for i := range loaded.Processes {
+			loaded.Processes[i].Line = 0
+		}
+		if !reflect.DeepEqual(loaded, w) {
+			t.Error("expected the saved workload to load back unchanged")
+		}
+	})
+}
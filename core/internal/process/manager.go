@@ -10,19 +10,27 @@ import (
 type Manager struct {
 	processes map[int]types.Process
 	nextPID   int
+	clock     types.Clock
 	mu        sync.RWMutex
 }
 
 // NewManager creates a new process manager
 func NewManager() *Manager {
+	return NewManagerWithClock(types.RealClock{})
+}
+
+// NewManagerWithClock creates a process manager whose processes tell time by
+// the given clock
+func NewManagerWithClock(clock types.Clock) *Manager {
 	return &Manager{
 		processes: make(map[int]types.Process),
 		nextPID:   1,
+		clock:     clock,
 	}
 }
 
-// CreateProcess creates a new process with the given task
-func (m *Manager) CreateProcess(task types.Task) (types.Process, error) {
+// CreateProcess creates a new process with the given task and options
+func (m *Manager) CreateProcess(task types.Task, opts ...ProcessOption) (types.Process, error) {
 	if task == nil {
 		return nil, fmt.Errorf("cannot create process with nil task")
 	}
@@ -30,10 +38,15 @@ func (m *Manager) CreateProcess(task types.Task) (types.Process, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	pcb := NewPCBWithClock(m.nextPID, task, m.clock)
+	for _, opt := range opts {
+		if err := opt(pcb); err != nil {
+			return nil, fmt.Errorf("invalid process option: %v", err)
+		}
+	}
+
 	pid := m.nextPID
 	m.nextPID++
-
-	pcb := NewPCB(pid, task)
 	m.processes[pid] = pcb
 
 	return pcb, nil
@@ -3,6 +3,7 @@ package process
 import (
 	"cpu-scheduling/core/internal/types"
 	"testing"
+	"time"
 )
 
 func TestNewManager(t *testing.T) {
@@ -40,6 +41,49 @@ func TestManager_CreateProcess(t *testing.T) {
 		}
 	})
 
+	t.Run("should apply process options", func(t *testing.T) {
+		manager := NewManager()
+		task := &types.SimpleTask{
+			ExecuteFn: func() (any, error) { return nil, nil },
+		}
+
+		process, err := manager.CreateProcess(task, WithExpectedBurst(5*time.Millisecond))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if process.GetExpectedBurst() != 5*time.Millisecond {
+			t.Errorf("expected burst 5ms, got %v", process.GetExpectedBurst())
+		}
+	})
+
+	t.Run("should assign the process class on creation", func(t *testing.T) {
+		manager := NewManager()
+		task := &types.SimpleTask{
+			ExecuteFn: func() (any, error) { return nil, nil },
+		}
+
+		process, _ := manager.CreateProcess(task, WithClass(types.BACKGROUND))
+		if process.GetClass() != types.BACKGROUND {
+			t.Errorf("expected class BACKGROUND, got %v", process.GetClass())
+		}
+	})
+
+	t.Run("should reject invalid options without consuming a PID", func(t *testing.T) {
+		manager := NewManager()
+		task := &types.SimpleTask{
+			ExecuteFn: func() (any, error) { return nil, nil },
+		}
+
+		if _, err := manager.CreateProcess(task, WithExpectedBurst(-1)); err == nil {
+			t.Error("expected error for invalid option")
+		}
+
+		process, _ := manager.CreateProcess(task)
+		if process.GetPID() != 1 {
+			t.Errorf("expected PID 1, got %d", process.GetPID())
+		}
+	})
+
 	t.Run("should return error for nil task", func(t *testing.T) {
 		manager := NewManager()
 		process, err := manager.CreateProcess(nil)
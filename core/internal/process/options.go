@@ -0,0 +1,115 @@
+package process
+
+import (
+	"cpu-scheduling/core/internal/types"
+	"fmt"
+	"time"
+)
+
+// ProcessOption configures a process when it is created through the Manager
+type ProcessOption func(p *PCB) error
+
+// WithExpectedBurst declares the CPU time the process is expected to need per burst
+func WithExpectedBurst(burst time.Duration) ProcessOption {
+	return func(p *PCB) error {
+		return p.SetExpectedBurst(burst)
+	}
+}
+
+// WithPriority sets the static base priority of the process
+func WithPriority(priority int) ProcessOption {
+	return func(p *PCB) error {
+		return p.SetPriority(priority)
+	}
+}
+
+// WithClass assigns the process to a scheduling class
+func WithClass(class types.ProcessClass) ProcessOption {
+	return func(p *PCB) error {
+		return p.SetClass(class)
+	}
+}
+
+// WithAffinity restricts the process to the given CPUs
+func WithAffinity(cpus ...int) ProcessOption {
+	return func(p *PCB) error {
+		mask, err := types.NewCPUMask(cpus...)
+		if err != nil {
+			return err
+		}
+		return p.SetAffinity(mask)
+	}
+}
+
+// WithHomeNode places the memory of the process on a NUMA node
+func WithHomeNode(node int) ProcessOption {
+	return func(p *PCB) error {
+		if node < 0 {
+			return fmt.Errorf("home node cannot be negative, got %d", node)
+		}
+		return p.SetHomeNode(node)
+	}
+}
+
+// WithOwner sets the user the process runs as and its group
+func WithOwner(user, group string) ProcessOption {
+	return func(p *PCB) error {
+		return p.SetOwner(user, group)
+	}
+}
+
+// WithNice sets the nice value of the process
+func WithNice(nice int) ProcessOption {
+	return func(p *PCB) error {
+		return p.SetNice(nice)
+	}
+}
+
+// WithTickets sets how many lottery tickets the process holds
+func WithTickets(tickets int) ProcessOption {
+	return func(p *PCB) error {
+		return p.SetTickets(tickets)
+	}
+}
+
+// WithCurrency issues the tickets of the process in the named currency
+func WithCurrency(currency string) ProcessOption {
+	return func(p *PCB) error {
+		return p.SetCurrency(currency)
+	}
+}
+
+// WithRequestedSlice sets the CPU time the process asks for per turn
+func WithRequestedSlice(slice time.Duration) ProcessOption {
+	return func(p *PCB) error {
+		return p.SetRequestedSlice(slice)
+	}
+}
+
+// WithPeriod makes the process periodic, releasing a job every period
+func WithPeriod(period time.Duration) ProcessOption {
+	return func(p *PCB) error {
+		return p.SetPeriod(period)
+	}
+}
+
+// WithDeadline sets how long after its release a job has to finish
+func WithDeadline(deadline time.Duration) ProcessOption {
+	return func(p *PCB) error {
+		return p.SetRelativeDeadline(deadline)
+	}
+}
+
+// WithWCET declares the worst case execution time of a job
+func WithWCET(wcet time.Duration) ProcessOption {
+	return func(p *PCB) error {
+		return p.SetWCET(wcet)
+	}
+}
+
+// WithJobs bounds how many jobs a periodic process releases
+func WithJobs(jobs int) ProcessOption {
+	return func(p *PCB) error {
+		return p.SetJobLimit(jobs)
+	}
+}
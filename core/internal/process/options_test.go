@@ -0,0 +1,298 @@
+package process
+
+import (
+	"cpu-scheduling/core/internal/types"
+	"testing"
+	"time"
+)
+
+func TestWithExpectedBurst(t *testing.T) {
+	t.Run("should set the expected burst", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if err := WithExpectedBurst(20 * time.Millisecond)(pcb); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if pcb.GetExpectedBurst() != 20*time.Millisecond {
+			t.Errorf("expected burst 20ms, got %v", pcb.GetExpectedBurst())
+		}
+	})
+
+	t.Run("should reject negative bursts", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if err := WithExpectedBurst(-time.Millisecond)(pcb); err == nil {
+			t.Error("expected error for negative burst")
+		}
+	})
+}
+
+func TestWithPriority(t *testing.T) {
+	t.Run("should set base and effective priority", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if err := WithPriority(5)(pcb); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if pcb.GetPriority() != 5 || pcb.GetEffectivePriority() != 5 {
+			t.Errorf("expected priority 5, got base %d effective %d", pcb.GetPriority(), pcb.GetEffectivePriority())
+		}
+	})
+
+	t.Run("should reject out of range priorities", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if err := WithPriority(types.LowestPriority + 1)(pcb); err == nil {
+			t.Error("expected error for out of range priority")
+		}
+	})
+}
+
+func TestWithClass(t *testing.T) {
+	t.Run("should assign the class", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if pcb.GetClass() != types.INTERACTIVE {
+			t.Errorf("expected default class INTERACTIVE, got %v", pcb.GetClass())
+		}
+		if err := WithClass(types.BATCH)(pcb); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if pcb.GetClass() != types.BATCH {
+			t.Errorf("expected class BATCH, got %v", pcb.GetClass())
+		}
+	})
+
+	t.Run("should reject unknown classes", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if err := WithClass(types.ProcessClass(42))(pcb); err == nil {
+			t.Error("expected error for unknown class")
+		}
+	})
+}
+
+func TestWithAffinity(t *testing.T) {
+	t.Run("should restrict the process to the given CPUs", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if err := WithAffinity(0, 2)(pcb); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if mask := pcb.GetAffinity(); !mask.Has(0) || mask.Has(1) || !mask.Has(2) || mask.Count() != 2 {
+			t.Errorf("expected CPUs 0 and 2, got %v", mask.CPUs())
+		}
+	})
+
+	t.Run("should default to all CPUs", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if pcb.GetAffinity() != types.AllCPUs {
+			t.Errorf("expected all CPUs, got %v", pcb.GetAffinity().CPUs())
+		}
+	})
+
+	t.Run("should reject empty masks and CPUs out of range", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if err := WithAffinity()(pcb); err == nil {
+			t.Error("expected error for empty mask")
+		}
+		if err := WithAffinity(types.MaxCPUs)(pcb); err == nil {
+			t.Error("expected error for CPU out of range")
+		}
+	})
+}
+
+func TestWithHomeNode(t *testing.T) {
+	t.Run("should place the process on the node", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if pcb.GetHomeNode() != types.NoHomeNode {
+			t.Errorf("expected no home node by default, got %d", pcb.GetHomeNode())
+		}
+		if err := WithHomeNode(1)(pcb); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if pcb.GetHomeNode() != 1 {
+			t.Errorf("expected home node 1, got %d", pcb.GetHomeNode())
+		}
+	})
+
+	t.Run("should reject negative nodes", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if err := WithHomeNode(-1)(pcb); err == nil {
+			t.Error("expected error for negative node")
+		}
+	})
+}
+
+func TestWithOwner(t *testing.T) {
+	t.Run("should set user and group", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if err := WithOwner("alice", "/research/ml")(pcb); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if owner := pcb.GetOwner(); owner.User != "alice" || owner.Group != "/research/ml" {
+			t.Errorf("expected alice in /research/ml, got %+v", owner)
+		}
+	})
+
+	t.Run("should default to the root user and group", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if owner := pcb.GetOwner(); owner.User != types.DefaultUser || owner.Group != types.RootGroup {
+			t.Errorf("expected default owner, got %+v", owner)
+		}
+	})
+
+	t.Run("should reject empty users and relative groups", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if err := WithOwner("", "/")(pcb); err == nil {
+			t.Error("expected error for empty user")
+		}
+		if err := WithOwner("alice", "research")(pcb); err == nil {
+			t.Error("expected error for relative group")
+		}
+	})
+}
+
+func TestWithNice(t *testing.T) {
+	t.Run("should set the nice value", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if err := WithNice(-5)(pcb); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if pcb.GetNice() != -5 {
+			t.Errorf("expected nice -5, got %d", pcb.GetNice())
+		}
+	})
+
+	t.Run("should reject out of range nice values", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if err := WithNice(types.MaxNice + 1)(pcb); err == nil {
+			t.Error("expected error for out of range nice value")
+		}
+	})
+}
+
+func TestWithTickets(t *testing.T) {
+	t.Run("should set tickets and currency", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if err := WithTickets(300)(pcb); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := WithCurrency("alice")(pcb); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if pcb.GetTickets() != 300 || pcb.GetCurrency() != "alice" {
+			t.Errorf("expected 300 tickets in alice, got %d in %q", pcb.GetTickets(), pcb.GetCurrency())
+		}
+	})
+
+	t.Run("should reject non-positive ticket counts", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if err := WithTickets(0)(pcb); err == nil {
+			t.Error("expected error for zero tickets")
+		}
+	})
+}
+
+func TestWithRequestedSlice(t *testing.T) {
+	t.Run("should set the requested slice", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if err := WithRequestedSlice(2 * time.Millisecond)(pcb); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if pcb.GetRequestedSlice() != 2*time.Millisecond {
+			t.Errorf("expected slice of 2ms, got %v", pcb.GetRequestedSlice())
+		}
+	})
+
+	t.Run("should reject negative slices", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if err := WithRequestedSlice(-time.Millisecond)(pcb); err == nil {
+			t.Error("expected error for negative slice")
+		}
+	})
+}
+
+func TestRealtimeOptions(t *testing.T) {
+	t.Run("should set period, deadline and WCET", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		for _, opt := range []ProcessOption{
+			WithPeriod(10 * time.Millisecond),
+			WithDeadline(8 * time.Millisecond),
+			WithWCET(2 * time.Millisecond),
+		} {
+			if err := opt(pcb); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		if pcb.GetPeriod() != 10*time.Millisecond || pcb.GetRelativeDeadline() != 8*time.Millisecond ||
+			pcb.GetWCET() != 2*time.Millisecond {
+			t.Errorf("unexpected parameters: period %v, deadline %v, WCET %v",
+				pcb.GetPeriod(), pcb.GetRelativeDeadline(), pcb.GetWCET())
+		}
+		if want := pcb.GetReleaseTime().Add(8 * time.Millisecond); !pcb.GetAbsoluteDeadline().Equal(want) {
+			t.Errorf("expected absolute deadline %v, got %v", want, pcb.GetAbsoluteDeadline())
+		}
+	})
+
+	t.Run("should default the deadline to the period", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+		WithPeriod(10 * time.Millisecond)(pcb)
+
+		if pcb.GetRelativeDeadline() != 10*time.Millisecond {
+			t.Errorf("expected implicit deadline of 10ms, got %v", pcb.GetRelativeDeadline())
+		}
+	})
+
+	t.Run("should report no deadline by default", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if !pcb.GetAbsoluteDeadline().IsZero() {
+			t.Errorf("expected zero deadline, got %v", pcb.GetAbsoluteDeadline())
+		}
+	})
+
+	t.Run("should reject negative values", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if WithPeriod(-1)(pcb) == nil || WithDeadline(-1)(pcb) == nil || WithWCET(-1)(pcb) == nil {
+			t.Error("expected errors for negative parameters")
+		}
+	})
+}
+
+func TestWithJobs(t *testing.T) {
+	t.Run("should set the job limit", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if err := WithJobs(5)(pcb); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if pcb.GetJobLimit() != 5 {
+			t.Errorf("expected job limit 5, got %d", pcb.GetJobLimit())
+		}
+	})
+
+	t.Run("should reject negative limits", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if err := WithJobs(-1)(pcb); err == nil {
+			t.Error("expected error for negative job limit")
+		}
+	})
+}
@@ -1,29 +1,91 @@
 package process
 
 import (
+	"context"
 	"cpu-scheduling/core/internal/types"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 )
 
+// stepBudget is the number of work units a SteppableTask performs between
+// checks for the end of its time slice
+const stepBudget = 64
+
 type PCB struct {
 	pid             int
 	state           types.ProcessState
 	createdAt       time.Time
 	lastStateChange time.Time
-	context         types.ProcessContext
-	task            types.Task
+	// clock stamps state changes, the wall clock unless the process runs in
+	// a simulation
+	clock   types.Clock
+	context types.ProcessContext
+	task    types.Task
+	mu      sync.RWMutex
+
+	// CPU burst accounting
+	expectedBurst time.Duration
+	currentBurst  time.Duration
+	cpuTime       time.Duration
+	burstHistory  []time.Duration
+	// waitTime is the time spent in READY before the current state
+	waitTime time.Duration
+
+	// Static base priority and the priority schedulers currently use for it
+	priority          int
+	effectivePriority int
+
+	class types.ProcessClass
+	nice  int
+	owner types.Owner
+	// affinity is the set of CPUs the process may run on
+	affinity types.CPUMask
+	// homeNode is the NUMA node that holds the memory of the process
+	homeNode int
+	// tickets are held in currency for proportional share schedulers
+	tickets  int
+	currency string
+	// slice is the CPU time the process asks for per turn, zero leaves it to
+	// the scheduler
+	slice time.Duration
+
+	// Real-time parameters. The current job was released at release and has
+	// to finish within deadline of it
+	period   time.Duration
+	deadline time.Duration
+	wcet     time.Duration
+	release  time.Time
+	// job counts the jobs released so far, up to jobLimit unless that is zero
+	job      int
+	jobLimit int
 }
 
 func NewPCB(pid int, task types.Task) *PCB {
-	now := time.Now()
+	return NewPCBWithClock(pid, task, types.RealClock{})
+}
+
+// NewPCBWithClock creates a process that tells time by the given clock
+func NewPCBWithClock(pid int, task types.Task, clock types.Clock) *PCB {
+	now := clock.Now()
 	return &PCB{
-		pid:             pid,
-		state:           types.NEW,
-		createdAt:       now,
-		lastStateChange: now,
-		context:         NewProcessContext(),
-		task:            task,
+		pid:               pid,
+		state:             types.NEW,
+		createdAt:         now,
+		lastStateChange:   now,
+		clock:             clock,
+		context:           NewProcessContext(),
+		task:              task,
+		priority:          types.DefaultPriority,
+		effectivePriority: types.DefaultPriority,
+		class:             types.INTERACTIVE,
+		owner:             types.Owner{User: types.DefaultUser, Group: types.RootGroup},
+		affinity:          types.AllCPUs,
+		homeNode:          types.NoHomeNode,
+		tickets:           types.DefaultTickets,
+		release:           now,
+		job:               1,
 	}
 }
 
@@ -32,10 +94,16 @@ func (p *PCB) GetPID() int {
 }
 
 func (p *PCB) GetState() types.ProcessState {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
 	return p.state
 }
 
 func (p *PCB) SetState(state types.ProcessState) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	if p.state == state {
 		return fmt.Errorf("process is already in state %d", state)
 	}
@@ -60,8 +128,26 @@ func (p *PCB) SetState(state types.ProcessState) error {
 		return fmt.Errorf("process cannot be set from WAITING state to RUNNING state, must go through READY state first, current state is %d", p.state)
 	}
 
+	now := p.now()
+
+	if p.state == types.READY {
+		p.waitTime += now.Sub(p.lastStateChange)
+	}
+
+	// A burst spans every slice the process runs until it blocks or exits
+	if p.state == types.RUNNING {
+		ran := now.Sub(p.lastStateChange)
+		p.currentBurst += ran
+		p.cpuTime += ran
+
+		if state == types.WAITING || state == types.TERMINATED {
+			p.burstHistory = append(p.burstHistory, p.currentBurst)
+			p.currentBurst = 0
+		}
+	}
+
 	p.state = state
-	p.lastStateChange = time.Now()
+	p.lastStateChange = now
 	return nil
 }
 
@@ -70,17 +156,503 @@ func (p *PCB) GetCreationTime() time.Time {
 }
 
 func (p *PCB) GetContext() types.ProcessContext {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
 	return p.context
 }
 
 func (p *PCB) ExecuteTask() (any, error) {
+	if _, ok := p.task.(types.SteppableTask); ok {
+		return p.ExecuteSlice(context.Background())
+	}
 	return p.task.Execute()
 }
 
+// ExecuteSlice runs the task until ctx is done. A SteppableTask is stepped
+// against the process context so it resumes where the previous slice stopped.
+// Tasks that implement neither types.SteppableTask nor types.PreemptibleTask
+// cannot be interrupted and run to completion
+func (p *PCB) ExecuteSlice(ctx context.Context) (any, error) {
+	switch task := p.task.(type) {
+	case types.SteppableTask:
+		pctx := p.GetContext()
+		for {
+			done, err := task.Step(pctx, stepBudget)
+			if err != nil {
+				return nil, err
+			}
+			if done {
+				return task.Result(), nil
+			}
+			if err := types.Checkpoint(ctx); err != nil {
+				return nil, err
+			}
+		}
+	case types.PreemptibleTask:
+		return task.ExecuteSlice(ctx)
+	default:
+		return task.Execute()
+	}
+}
+
+// GetProgress reports the completed fraction of the task. Only a SteppableTask
+// can report partial progress, other tasks are either not done or done
+func (p *PCB) GetProgress() float64 {
+	if task, ok := p.task.(types.SteppableTask); ok {
+		return task.Progress(p.GetContext())
+	}
+	if p.GetState() == types.TERMINATED {
+		return 1
+	}
+	return 0
+}
+
+// SetExpectedBurst declares how much CPU time the process is expected to need
+// per burst, for schedulers that order by burst length
+func (p *PCB) SetExpectedBurst(burst time.Duration) error {
+	if burst < 0 {
+		return fmt.Errorf("expected burst cannot be negative, got %v", burst)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.expectedBurst = burst
+	return nil
+}
+
+func (p *PCB) GetExpectedBurst() time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.expectedBurst
+}
+
+// GetCurrentBurst returns the CPU time used in the ongoing burst, including
+// the time spent in the current slice if the process is running
+func (p *PCB) GetCurrentBurst() time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.state == types.RUNNING {
+		return p.currentBurst + p.since(p.lastStateChange)
+	}
+	return p.currentBurst
+}
+
+// GetRemainingBurst returns how much of the expected burst is left, which
+// shrinks while the process runs and never drops below zero
+func (p *PCB) GetRemainingBurst() time.Duration {
+	remaining := p.GetExpectedBurst() - p.GetCurrentBurst()
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// GetCPUTime returns the total CPU time the process has used
+func (p *PCB) GetCPUTime() time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.state == types.RUNNING {
+		return p.cpuTime + p.since(p.lastStateChange)
+	}
+	return p.cpuTime
+}
+
+// GetBurstHistory returns the lengths of the completed CPU bursts, oldest first
+func (p *PCB) GetBurstHistory() []time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	history := make([]time.Duration, len(p.burstHistory))
+	copy(history, p.burstHistory)
+	return history
+}
+
+// SetPriority sets the static base priority and resets the effective
+// priority to it
+func (p *PCB) SetPriority(priority int) error {
+	if err := validatePriority(priority); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.priority = priority
+	p.effectivePriority = priority
+	return nil
+}
+
+func (p *PCB) GetPriority() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.priority
+}
+
+// GetEffectivePriority returns the priority schedulers order the process by,
+// which may be temporarily raised above the base priority, e.g. by aging
+func (p *PCB) GetEffectivePriority() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.effectivePriority
+}
+
+func (p *PCB) SetEffectivePriority(priority int) error {
+	if err := validatePriority(priority); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.effectivePriority = priority
+	return nil
+}
+
+// SetClass assigns the process to a scheduling class
+func (p *PCB) SetClass(class types.ProcessClass) error {
+	if class < types.SYSTEM || class > types.BACKGROUND {
+		return fmt.Errorf("invalid process class: %d", class)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.class = class
+	return nil
+}
+
+func (p *PCB) GetClass() types.ProcessClass {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.class
+}
+
+// SetOwner sets the user the process runs as and the group it belongs to. The
+// group is a slash separated path starting at the root group
+func (p *PCB) SetOwner(user, group string) error {
+	if user == "" {
+		return fmt.Errorf("owner needs a user name")
+	}
+	if !strings.HasPrefix(group, types.RootGroup) {
+		return fmt.Errorf("group must be an absolute path, got %q", group)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.owner = types.Owner{User: user, Group: group}
+	return nil
+}
+
+func (p *PCB) GetOwner() types.Owner {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.owner
+}
+
+// SetAffinity restricts the process to the CPUs in the mask, which must not
+// be empty
+func (p *PCB) SetAffinity(mask types.CPUMask) error {
+	if mask == 0 {
+		return fmt.Errorf("affinity mask cannot be empty")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.affinity = mask
+	return nil
+}
+
+func (p *PCB) GetAffinity() types.CPUMask {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.affinity
+}
+
+// SetHomeNode places the memory of the process on a NUMA node, or clears the
+// placement with types.NoHomeNode
+func (p *PCB) SetHomeNode(node int) error {
+	if node < types.NoHomeNode {
+		return fmt.Errorf("home node must be non-negative or NoHomeNode, got %d", node)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.homeNode = node
+	return nil
+}
+
+func (p *PCB) GetHomeNode() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.homeNode
+}
+
+// SetNice sets the nice value fair share schedulers weight the process by
+func (p *PCB) SetNice(nice int) error {
+	if nice < types.MinNice || nice > types.MaxNice {
+		return fmt.Errorf("nice value must be between %d and %d, got %d", types.MinNice, types.MaxNice, nice)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.nice = nice
+	return nil
+}
+
+func (p *PCB) GetNice() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.nice
+}
+
+// SetTickets sets how many lottery tickets the process holds in its currency
+func (p *PCB) SetTickets(tickets int) error {
+	if tickets <= 0 {
+		return fmt.Errorf("tickets must be positive, got %d", tickets)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.tickets = tickets
+	return nil
+}
+
+func (p *PCB) GetTickets() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.tickets
+}
+
+// SetCurrency sets the currency the tickets of the process are issued in. The
+// empty name is the base currency
+func (p *PCB) SetCurrency(currency string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.currency = currency
+	return nil
+}
+
+func (p *PCB) GetCurrency() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.currency
+}
+
+// SetRequestedSlice sets the CPU time the process asks for per turn
+func (p *PCB) SetRequestedSlice(slice time.Duration) error {
+	if slice < 0 {
+		return fmt.Errorf("requested slice cannot be negative, got %v", slice)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.slice = slice
+	return nil
+}
+
+func (p *PCB) GetRequestedSlice() time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.slice
+}
+
+// SetPeriod sets the interval at which a periodic process releases jobs
+func (p *PCB) SetPeriod(period time.Duration) error {
+	if period < 0 {
+		return fmt.Errorf("period cannot be negative, got %v", period)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.period = period
+	return nil
+}
+
+func (p *PCB) GetPeriod() time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.period
+}
+
+// SetRelativeDeadline sets how long after its release a job has to finish
+func (p *PCB) SetRelativeDeadline(deadline time.Duration) error {
+	if deadline < 0 {
+		return fmt.Errorf("deadline cannot be negative, got %v", deadline)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.deadline = deadline
+	return nil
+}
+
+// GetRelativeDeadline returns the relative deadline of the process. Periodic
+// processes without an explicit one have to finish before their next release
+func (p *PCB) GetRelativeDeadline() time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.deadline == 0 {
+		return p.period
+	}
+	return p.deadline
+}
+
+// GetAbsoluteDeadline returns the point in time the current job has to finish
+// by, or the zero time if the process has no deadline
+func (p *PCB) GetAbsoluteDeadline() time.Time {
+	deadline := p.GetRelativeDeadline()
+	if deadline == 0 {
+		return time.Time{}
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.release.Add(deadline)
+}
+
+// GetReleaseTime returns when the current job of the process was released
+func (p *PCB) GetReleaseTime() time.Time {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.release
+}
+
+// SetWCET declares the worst case execution time of a single job
+func (p *PCB) SetWCET(wcet time.Duration) error {
+	if wcet < 0 {
+		return fmt.Errorf("WCET cannot be negative, got %v", wcet)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.wcet = wcet
+	return nil
+}
+
+func (p *PCB) GetWCET() time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.wcet
+}
+
+// SetJobLimit bounds how many jobs a periodic process releases, zero means no
+// limit
+func (p *PCB) SetJobLimit(jobs int) error {
+	if jobs < 0 {
+		return fmt.Errorf("job limit cannot be negative, got %d", jobs)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.jobLimit = jobs
+	return nil
+}
+
+func (p *PCB) GetJobLimit() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.jobLimit
+}
+
+// GetJob returns the number of the current job, starting at 1
+func (p *PCB) GetJob() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.job
+}
+
+// ReleaseJob starts the next job of a periodic process at the given time. The
+// process context is reset so the task runs from the beginning again
+func (p *PCB) ReleaseJob(release time.Time) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.period == 0 {
+		return fmt.Errorf("process %d is not periodic", p.pid)
+	}
+	if p.jobLimit > 0 && p.job >= p.jobLimit {
+		return fmt.Errorf("process %d has already released all %d jobs", p.pid, p.jobLimit)
+	}
+
+	p.job++
+	p.release = release
+	p.context = NewProcessContext()
+	return nil
+}
+
+func validatePriority(priority int) error {
+	if priority < types.HighestPriority || priority > types.LowestPriority {
+		return fmt.Errorf("priority must be between %d and %d, got %d",
+			types.HighestPriority, types.LowestPriority, priority)
+	}
+	return nil
+}
+
 func (p *PCB) GetTimeInState() time.Duration {
-	return time.Since(p.lastStateChange)
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.since(p.lastStateChange)
+}
+
+// GetWaitTime returns the total time the process has spent in READY waiting
+// for the CPU, including the ongoing wait if it is ready now
+func (p *PCB) GetWaitTime() time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.state == types.READY {
+		return p.waitTime + p.since(p.lastStateChange)
+	}
+	return p.waitTime
 }
 
 func (p *PCB) GetTotalTime() time.Duration {
-	return time.Since(p.createdAt)
+	return p.since(p.createdAt)
+}
+
+// now reads the clock of the process, falling back to the wall clock for a
+// PCB that was not created by a constructor
+func (p *PCB) now() time.Time {
+	if p.clock == nil {
+		return time.Now()
+	}
+	return p.clock.Now()
+}
+
+func (p *PCB) since(t time.Time) time.Duration {
+	return p.now().Sub(t)
 }
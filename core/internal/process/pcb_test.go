@@ -1,7 +1,9 @@
 package process
 
 import (
+	"context"
 	"cpu-scheduling/core/internal/types"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -43,6 +45,35 @@ func TestNewPCB(t *testing.T) {
 	})
 }
 
+// manualClock is a clock that only moves when the test advances it
+type manualClock struct {
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time                  { return c.now }
+func (c *manualClock) Since(t time.Time) time.Duration { return c.now.Sub(t) }
+
+func TestNewPCBWithClock(t *testing.T) {
+	t.Run("should account time by the given clock", func(t *testing.T) {
+		clock := &manualClock{now: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)}
+		pcb := NewPCBWithClock(1, NewTask(func() (any, error) { return nil, nil }), clock)
+
+		if !pcb.GetCreationTime().Equal(clock.now) {
+			t.Errorf("expected creation at %v, got %v", clock.now, pcb.GetCreationTime())
+		}
+
+		pcb.SetState(types.READY)
+		clock.now = clock.now.Add(3 * time.Second)
+		pcb.SetState(types.RUNNING)
+		clock.now = clock.now.Add(5 * time.Second)
+
+		if pcb.GetWaitTime() != 3*time.Second || pcb.GetCPUTime() != 5*time.Second || pcb.GetTotalTime() != 8*time.Second {
+			t.Errorf("expected 3s waiting, 5s running and 8s in total, got %v, %v and %v",
+				pcb.GetWaitTime(), pcb.GetCPUTime(), pcb.GetTotalTime())
+		}
+	})
+}
+
 func TestPCB_GetPID(t *testing.T) {
 	t.Run("should return the pid", func(t *testing.T) {
 		pcb := &PCB{pid: 1}
@@ -329,6 +360,331 @@ func TestPCB_ExecuteTask(t *testing.T) {
 	})
 }
 
+type countingTask struct {
+	units int
+	done  int
+}
+
+func (t *countingTask) Execute() (any, error) {
+	return t.ExecuteSlice(context.Background())
+}
+
+func (t *countingTask) ExecuteSlice(ctx context.Context) (any, error) {
+	for t.done < t.units {
+		if err := types.Checkpoint(ctx); err != nil {
+			return nil, err
+		}
+		t.done++
+	}
+	return t.done, nil
+}
+
+func TestPCB_ExecuteSlice(t *testing.T) {
+	t.Run("should run a non-preemptible task to completion", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return 1, nil }))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		result, err := pcb.ExecuteSlice(ctx)
+
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+		}
+		if result != 1 {
+			t.Errorf("expected result to be 1, got %v", result)
+		}
+	})
+
+	t.Run("should return ErrPreempted when the slice is over", func(t *testing.T) {
+		task := &countingTask{units: 10}
+		pcb := NewPCB(1, task)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := pcb.ExecuteSlice(ctx)
+
+		if !errors.Is(err, types.ErrPreempted) {
+			t.Errorf("expected ErrPreempted, got %v", err)
+		}
+		if task.done != 0 {
+			t.Errorf("expected no progress, got %d", task.done)
+		}
+	})
+
+	t.Run("should complete a preemptible task within its slice", func(t *testing.T) {
+		pcb := NewPCB(1, &countingTask{units: 10})
+
+		result, err := pcb.ExecuteSlice(context.Background())
+
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+		}
+		if result != 10 {
+			t.Errorf("expected result to be 10, got %v", result)
+		}
+	})
+}
+
+func TestPCB_ExecuteSlice_Steppable(t *testing.T) {
+	t.Run("should keep the resume point in the process context", func(t *testing.T) {
+		pcb := NewPCB(1, newSumTask(200))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := pcb.ExecuteSlice(ctx)
+		if !errors.Is(err, types.ErrPreempted) {
+			t.Fatalf("expected ErrPreempted, got %v", err)
+		}
+
+		next, _ := pcb.GetContext().GetRegisterValue(types.RCX)
+		if next != stepBudget {
+			t.Errorf("expected one step budget of progress, got %d", next)
+		}
+
+		result, err := pcb.ExecuteSlice(context.Background())
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+		}
+		if result != uint64(199*200/2) {
+			t.Errorf("expected result %d, got %v", 199*200/2, result)
+		}
+	})
+
+	t.Run("should run to completion through ExecuteTask", func(t *testing.T) {
+		pcb := NewPCB(1, newSumTask(100))
+
+		result, err := pcb.ExecuteTask()
+
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+		}
+		if result != uint64(4950) {
+			t.Errorf("expected result 4950, got %v", result)
+		}
+		if pcb.GetProgress() != 1 {
+			t.Errorf("expected progress 1, got %v", pcb.GetProgress())
+		}
+	})
+}
+
+func TestPCB_GetProgress(t *testing.T) {
+	t.Run("should report partial progress for steppable tasks", func(t *testing.T) {
+		pcb := NewPCB(1, newSumTask(stepBudget*4))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		pcb.ExecuteSlice(ctx)
+
+		if pcb.GetProgress() != 0.25 {
+			t.Errorf("expected progress 0.25, got %v", pcb.GetProgress())
+		}
+	})
+
+	t.Run("should read progress while jobs are released", func(t *testing.T) {
+		pcb := NewPCB(1, newSumTask(stepBudget*4))
+		pcb.SetPeriod(time.Millisecond)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 100; i++ {
+				pcb.GetProgress()
+			}
+		}()
+		for i := 0; i < 100; i++ {
+			pcb.ReleaseJob(time.Now())
+		}
+		<-done
+	})
+
+	t.Run("should report progress by state for other tasks", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if pcb.GetProgress() != 0 {
+			t.Errorf("expected progress 0, got %v", pcb.GetProgress())
+		}
+
+		pcb.SetState(types.READY)
+		pcb.SetState(types.RUNNING)
+		pcb.SetState(types.TERMINATED)
+
+		if pcb.GetProgress() != 1 {
+			t.Errorf("expected progress 1, got %v", pcb.GetProgress())
+		}
+	})
+}
+
+func TestPCB_BurstAccounting(t *testing.T) {
+	t.Run("should accumulate a burst across preemptions", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		pcb.SetState(types.READY)
+		pcb.SetState(types.RUNNING)
+		time.Sleep(5 * time.Millisecond)
+		pcb.SetState(types.READY)
+
+		if len(pcb.GetBurstHistory()) != 0 {
+			t.Error("expected preemption not to end the burst")
+		}
+
+		pcb.SetState(types.RUNNING)
+		time.Sleep(5 * time.Millisecond)
+		pcb.SetState(types.WAITING)
+
+		history := pcb.GetBurstHistory()
+		if len(history) != 1 || history[0] < 10*time.Millisecond {
+			t.Errorf("expected one burst of at least 10ms, got %v", history)
+		}
+		if pcb.GetCurrentBurst() != 0 {
+			t.Errorf("expected current burst to reset, got %v", pcb.GetCurrentBurst())
+		}
+		if pcb.GetCPUTime() != history[0] {
+			t.Errorf("expected CPU time %v, got %v", history[0], pcb.GetCPUTime())
+		}
+	})
+
+	t.Run("should include the running slice in the current burst", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		pcb.SetState(types.READY)
+		pcb.SetState(types.RUNNING)
+		time.Sleep(5 * time.Millisecond)
+
+		if pcb.GetCurrentBurst() < 5*time.Millisecond {
+			t.Errorf("expected current burst >= 5ms, got %v", pcb.GetCurrentBurst())
+		}
+		if pcb.GetCPUTime() < 5*time.Millisecond {
+			t.Errorf("expected CPU time >= 5ms, got %v", pcb.GetCPUTime())
+		}
+	})
+
+	t.Run("should record the final burst on termination", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		pcb.SetState(types.READY)
+		pcb.SetState(types.RUNNING)
+		pcb.SetState(types.TERMINATED)
+
+		if len(pcb.GetBurstHistory()) != 1 {
+			t.Errorf("expected one burst, got %d", len(pcb.GetBurstHistory()))
+		}
+	})
+}
+
+func TestPCB_GetRemainingBurst(t *testing.T) {
+	t.Run("should shrink while the process runs", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+		pcb.SetExpectedBurst(50 * time.Millisecond)
+
+		pcb.SetState(types.READY)
+		pcb.SetState(types.RUNNING)
+		time.Sleep(10 * time.Millisecond)
+
+		remaining := pcb.GetRemainingBurst()
+		if remaining > 40*time.Millisecond {
+			t.Errorf("expected remaining burst <= 40ms, got %v", remaining)
+		}
+	})
+
+	t.Run("should not drop below zero", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+		pcb.SetExpectedBurst(time.Millisecond)
+
+		pcb.SetState(types.READY)
+		pcb.SetState(types.RUNNING)
+		time.Sleep(2 * time.Millisecond)
+
+		if pcb.GetRemainingBurst() != 0 {
+			t.Errorf("expected remaining burst 0, got %v", pcb.GetRemainingBurst())
+		}
+	})
+}
+
+func TestPCB_SetExpectedBurst(t *testing.T) {
+	t.Run("should return error for negative burst", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if err := pcb.SetExpectedBurst(-time.Millisecond); err == nil {
+			t.Error("expected error for negative burst")
+		}
+	})
+}
+
+func TestPCB_Priority(t *testing.T) {
+	t.Run("should start at the default priority", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if pcb.GetPriority() != types.DefaultPriority || pcb.GetEffectivePriority() != types.DefaultPriority {
+			t.Errorf("expected default priority %d, got base %d effective %d",
+				types.DefaultPriority, pcb.GetPriority(), pcb.GetEffectivePriority())
+		}
+	})
+
+	t.Run("should change effective priority without touching the base", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if err := pcb.SetEffectivePriority(3); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if pcb.GetPriority() != types.DefaultPriority || pcb.GetEffectivePriority() != 3 {
+			t.Errorf("expected base %d effective 3, got base %d effective %d",
+				types.DefaultPriority, pcb.GetPriority(), pcb.GetEffectivePriority())
+		}
+	})
+
+	t.Run("should return error for invalid effective priority", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if err := pcb.SetEffectivePriority(-1); err == nil {
+			t.Error("expected error for negative priority")
+		}
+	})
+}
+
+func TestPCB_ReleaseJob(t *testing.T) {
+	t.Run("should start the next job from the beginning", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+		pcb.SetPeriod(10 * time.Millisecond)
+		pcb.GetContext().SetProgramCounter(8)
+
+		release := pcb.GetReleaseTime().Add(10 * time.Millisecond)
+		if err := pcb.ReleaseJob(release); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if pcb.GetJob() != 2 {
+			t.Errorf("expected job 2, got %d", pcb.GetJob())
+		}
+		if !pcb.GetReleaseTime().Equal(release) {
+			t.Errorf("expected release at %v, got %v", release, pcb.GetReleaseTime())
+		}
+		if pc := pcb.GetContext().GetProgramCounter(); pc != 0 {
+			t.Errorf("expected context to be reset, got PC %d", pc)
+		}
+	})
+
+	t.Run("should return error for aperiodic processes", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		if err := pcb.ReleaseJob(time.Now()); err == nil {
+			t.Error("expected error for aperiodic process")
+		}
+	})
+
+	t.Run("should stop at the job limit", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+		pcb.SetPeriod(10 * time.Millisecond)
+		pcb.SetJobLimit(2)
+
+		if err := pcb.ReleaseJob(time.Now()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := pcb.ReleaseJob(time.Now()); err == nil {
+			t.Error("expected error beyond the job limit")
+		}
+	})
+}
+
 func TestPCB_GetTimeInState(t *testing.T) {
 	t.Run("should return correct duration in current state", func(t *testing.T) {
 		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
@@ -356,6 +712,37 @@ func TestPCB_GetTimeInState(t *testing.T) {
 	})
 }
 
+func TestPCB_GetWaitTime(t *testing.T) {
+	t.Run("should add up every stint in READY", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		pcb.SetState(types.READY)
+		time.Sleep(10 * time.Millisecond)
+		pcb.SetState(types.RUNNING)
+		time.Sleep(20 * time.Millisecond)
+		pcb.SetState(types.READY)
+		time.Sleep(10 * time.Millisecond)
+
+		wait := pcb.GetWaitTime()
+		if wait < 20*time.Millisecond || wait >= 40*time.Millisecond {
+			t.Errorf("expected 20ms of waiting without the time running, got %v", wait)
+		}
+	})
+
+	t.Run("should stop counting outside READY", func(t *testing.T) {
+		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
+
+		pcb.SetState(types.READY)
+		pcb.SetState(types.RUNNING)
+		before := pcb.GetWaitTime()
+		time.Sleep(5 * time.Millisecond)
+
+		if pcb.GetWaitTime() != before {
+			t.Errorf("expected wait time to stay at %v while running, got %v", before, pcb.GetWaitTime())
+		}
+	})
+}
+
 func TestPCB_GetTotalTime(t *testing.T) {
 	t.Run("should return total time since creation", func(t *testing.T) {
 		pcb := NewPCB(1, NewTask(func() (any, error) { return nil, nil }))
@@ -0,0 +1,104 @@
+package process
+
+import (
+	"cpu-scheduling/core/internal/types"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// StepTask is a SteppableTask that works through a fixed number of units. The
+// index of the next unit lives in the RCX register and the program counter
+// advances one instruction per unit, so all resume state belongs to the
+// process context. The remaining registers are free for the step function to
+// use as accumulators
+type StepTask struct {
+	units    uint64
+	stepFn   func(ctx types.ProcessContext, unit uint64) error
+	resultFn func(ctx types.ProcessContext) any
+	result   any
+}
+
+// instructionSize is the program counter increment per unit of work
+const instructionSize = 4
+
+func NewStepTask(units uint64, stepFn func(ctx types.ProcessContext, unit uint64) error, resultFn func(ctx types.ProcessContext) any) *StepTask {
+	return &StepTask{
+		units:    units,
+		stepFn:   stepFn,
+		resultFn: resultFn,
+	}
+}
+
+func (t *StepTask) Step(ctx types.ProcessContext, budget int) (bool, error) {
+	if budget <= 0 {
+		return false, fmt.Errorf("step budget must be positive, got %d", budget)
+	}
+
+	next, err := ctx.GetRegisterValue(types.RCX)
+	if err != nil {
+		return false, err
+	}
+
+	for n := 0; n < budget && next < t.units; n++ {
+		stepErr := t.stepFn(ctx, next)
+
+		// A unit that blocks for I/O has done its work and is not repeated
+		var ioWait *types.IOWaitError
+		if stepErr != nil && !errors.As(stepErr, &ioWait) {
+			return false, stepErr
+		}
+		next++
+
+		if err := ctx.SetRegisterValue(types.RCX, next); err != nil {
+			return false, err
+		}
+		if err := ctx.SetProgramCounter(next * instructionSize); err != nil {
+			return false, err
+		}
+
+		if stepErr != nil {
+			return false, stepErr
+		}
+	}
+
+	if next < t.units {
+		return false, nil
+	}
+
+	if t.resultFn != nil {
+		t.result = t.resultFn(ctx)
+	}
+	return true, nil
+}
+
+func (t *StepTask) Progress(ctx types.ProcessContext) float64 {
+	if t.units == 0 {
+		return 1
+	}
+
+	next, err := ctx.GetRegisterValue(types.RCX)
+	if err != nil {
+		return 0
+	}
+	return float64(next) / float64(t.units)
+}
+
+func (t *StepTask) Result() any {
+	return t.result
+}
+
+// Execute runs every unit at once on a fresh context, for callers that use
+// the task without a process
+func (t *StepTask) Execute() (any, error) {
+	ctx := NewProcessContext()
+	for {
+		done, err := t.Step(ctx, math.MaxInt)
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			return t.result, nil
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package process
+
+import (
+	"cpu-scheduling/core/internal/types"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func newSumTask(units uint64) *StepTask {
+	return NewStepTask(units,
+		func(ctx types.ProcessContext, unit uint64) error {
+			sum, _ := ctx.GetRegisterValue(types.RAX)
+			return ctx.SetRegisterValue(types.RAX, sum+unit)
+		},
+		func(ctx types.ProcessContext) any {
+			sum, _ := ctx.GetRegisterValue(types.RAX)
+			return sum
+		},
+	)
+}
+
+func TestStepTask_Step(t *testing.T) {
+	t.Run("should stop after the budget and resume from the context", func(t *testing.T) {
+		task := newSumTask(10)
+		ctx := NewProcessContext()
+
+		done, err := task.Step(ctx, 4)
+		if err != nil || done {
+			t.Fatalf("expected unfinished step without error, got done=%v err=%v", done, err)
+		}
+
+		next, _ := ctx.GetRegisterValue(types.RCX)
+		if next != 4 {
+			t.Errorf("expected resume point 4, got %d", next)
+		}
+		if ctx.GetProgramCounter() != 16 {
+			t.Errorf("expected program counter 16, got %d", ctx.GetProgramCounter())
+		}
+
+		done, _ = task.Step(ctx, 4)
+		if done {
+			t.Error("expected task to be unfinished after 8 units")
+		}
+
+		done, _ = task.Step(ctx, 4)
+		if !done {
+			t.Error("expected task to be finished after 10 units")
+		}
+
+		if task.Result() != uint64(45) {
+			t.Errorf("expected result 45, got %v", task.Result())
+		}
+	})
+
+	t.Run("should return error for non-positive budget", func(t *testing.T) {
+		task := newSumTask(10)
+
+		if _, err := task.Step(NewProcessContext(), 0); err == nil {
+			t.Error("expected error for zero budget")
+		}
+	})
+
+	t.Run("should return error if a step fails", func(t *testing.T) {
+		task := NewStepTask(3, func(ctx types.ProcessContext, unit uint64) error {
+			if unit == 1 {
+				return fmt.Errorf("mock error")
+			}
+			return nil
+		}, nil)
+		ctx := NewProcessContext()
+
+		if _, err := task.Step(ctx, 10); err == nil {
+			t.Error("expected error from failing step")
+		}
+
+		next, _ := ctx.GetRegisterValue(types.RCX)
+		if next != 1 {
+			t.Errorf("expected resume point to stay at failing unit 1, got %d", next)
+		}
+	})
+}
+
+func TestStepTask_BlockForIO(t *testing.T) {
+	t.Run("should resume after a unit that blocked for I/O", func(t *testing.T) {
+		task := NewStepTask(4, func(ctx types.ProcessContext, unit uint64) error {
+			if unit == 1 {
+				return types.BlockForIO(time.Millisecond)
+			}
+			return nil
+		}, nil)
+		ctx := NewProcessContext()
+
+		_, err := task.Step(ctx, 10)
+		var ioWait *types.IOWaitError
+		if !errors.As(err, &ioWait) {
+			t.Fatalf("expected IOWaitError, got %v", err)
+		}
+
+		next, _ := ctx.GetRegisterValue(types.RCX)
+		if next != 2 {
+			t.Errorf("expected resume point 2 after the blocking unit, got %d", next)
+		}
+
+		done, err := task.Step(ctx, 10)
+		if err != nil || !done {
+			t.Errorf("expected task to finish, got done=%v err=%v", done, err)
+		}
+	})
+}
+
+func TestStepTask_Progress(t *testing.T) {
+	t.Run("should report the completed fraction", func(t *testing.T) {
+		task := newSumTask(8)
+		ctx := NewProcessContext()
+
+		if task.Progress(ctx) != 0 {
+			t.Errorf("expected progress 0, got %v", task.Progress(ctx))
+		}
+
+		task.Step(ctx, 2)
+
+		if task.Progress(ctx) != 0.25 {
+			t.Errorf("expected progress 0.25, got %v", task.Progress(ctx))
+		}
+	})
+}
+
+func TestStepTask_Execute(t *testing.T) {
+	t.Run("should run all units at once", func(t *testing.T) {
+		result, err := newSumTask(5).Execute()
+
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+		}
+		if result != uint64(10) {
+			t.Errorf("expected result 10, got %v", result)
+		}
+	})
+}
@@ -0,0 +1,435 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/rbtree"
+	"cpu-scheduling/core/internal/types"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSchedLatency      = 6 * time.Millisecond
+	defaultMinGranularity    = 750 * time.Microsecond
+	defaultWakeupGranularity = time.Millisecond
+)
+
+// CFSConfig holds the tunables of the Completely Fair Scheduler. SchedLatency
+// is the period in which every runnable process should run once, as long as
+// there are few enough that none gets less than MinGranularity. A waking
+// process preempts the running one when its vruntime is more than
+// WakeupGranularity behind
+type CFSConfig struct {
+	SchedLatency      time.Duration
+	MinGranularity    time.Duration
+	WakeupGranularity time.Duration
+}
+
+// DefaultCFSConfig returns the Linux defaults for a single CPU
+func DefaultCFSConfig() CFSConfig {
+	return CFSConfig{
+		SchedLatency:      defaultSchedLatency,
+		MinGranularity:    defaultMinGranularity,
+		WakeupGranularity: defaultWakeupGranularity,
+	}
+}
+
+// CFSProcessMetrics reports the fairness state of a single process
+type CFSProcessMetrics struct {
+	PID      int
+	Vruntime time.Duration
+	Weight   int
+}
+
+// CFSMetrics extends the scheduling metrics with the virtual runtimes
+type CFSMetrics struct {
+	types.SchedulingMetrics
+	MinVruntime time.Duration
+	Processes   []CFSProcessMetrics
+}
+
+type entityState int
+
+const (
+	entityQueued entityState = iota
+	entityRunning
+	entitySleeping
+)
+
+// cfsEntity is the scheduling entity CFS keeps for every known process
+type cfsEntity struct {
+	process  types.Process
+	vruntime time.Duration
+	weight   int
+	state    entityState
+	seq      uint64
+	node     *rbtree.Node[*cfsEntity]
+}
+
+// CFSQueue is a Linux inspired Completely Fair Scheduler. Each process
+// accumulates virtual runtime, its CPU time scaled by the inverse of its nice
+// weight, and the process with the smallest vruntime runs next. Runnable
+// processes are kept in a red-black tree ordered by vruntime
+type CFSQueue struct {
+	timeline *rbtree.Tree[*cfsEntity]
+	entities map[int]*cfsEntity
+	config   CFSConfig
+	nextSeq  uint64
+	mu       sync.RWMutex
+
+	// minVruntime only moves forward and is where new and waking processes
+	// are placed, so they cannot claim CPU time from before they arrived
+	minVruntime time.Duration
+	// load is the total weight and nrRunning the number of queued and
+	// running entities
+	load      int
+	nrRunning int
+
+	metrics metricsTracker
+}
+
+// NewCFSQueue creates a CFS queue, using the defaults for unset tunables
+func NewCFSQueue(config CFSConfig) *CFSQueue {
+	if config.SchedLatency <= 0 {
+		config.SchedLatency = defaultSchedLatency
+	}
+	if config.MinGranularity <= 0 {
+		config.MinGranularity = defaultMinGranularity
+	}
+	if config.WakeupGranularity <= 0 {
+		config.WakeupGranularity = defaultWakeupGranularity
+	}
+
+	return &CFSQueue{
+		timeline: rbtree.New(func(a, b *cfsEntity) bool {
+			if a.vruntime != b.vruntime {
+				return a.vruntime < b.vruntime
+			}
+			return a.seq < b.seq
+		}),
+		entities: make(map[int]*cfsEntity),
+		config:   config,
+		metrics:  newMetricsTracker(),
+	}
+}
+
+func (q *CFSQueue) Enqueue(p types.Process) error {
+	if p == nil {
+		return fmt.Errorf("cannot enqueue nil process")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	e, known := q.entities[p.GetPID()]
+	switch {
+	case !known:
+		e = &cfsEntity{process: p, weight: NiceWeight(p.GetNice()), state: entitySleeping}
+		q.entities[p.GetPID()] = e
+		q.activate(e)
+		// New processes start one virtual slice behind the pack
+		e.vruntime = q.minVruntime + q.vslice(e)
+	case e.state == entityQueued:
+		return fmt.Errorf("process %d is already queued", p.GetPID())
+	case e.state == entitySleeping:
+		q.activate(e)
+		// Sleepers get at most half a latency period of credit
+		if floor := q.minVruntime - q.config.SchedLatency/2; e.vruntime < floor {
+			e.vruntime = floor
+		}
+	}
+
+	q.setWeight(e, NiceWeight(p.GetNice()))
+	e.state = entityQueued
+	e.seq = q.nextSeq
+	q.nextSeq++
+	e.node = q.timeline.Insert(e)
+
+	q.updateMinVruntime()
+	return nil
+}
+
+func (q *CFSQueue) Dequeue() (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	leftmost := q.timeline.Min()
+	if leftmost == nil {
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	e := q.take(leftmost)
+	q.metrics.record(e.process)
+	return e.process, nil
+}
+
+func (q *CFSQueue) Peek() (types.Process, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	leftmost := q.timeline.Min()
+	if leftmost == nil {
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	return leftmost.Value.process, nil
+}
+
+func (q *CFSQueue) IsEmpty() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.timeline.Len() == 0
+}
+
+func (q *CFSQueue) Size() int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.timeline.Len()
+}
+
+func (q *CFSQueue) GetMetrics() types.SchedulingMetrics {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.metrics.snapshot()
+}
+
+func (q *CFSQueue) SetClock(clock types.Clock) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.metrics.setClock(clock)
+}
+
+// DequeueMatching dequeues the process with the smallest vruntime that match
+// accepts
+func (q *CFSQueue) DequeueMatching(match func(types.Process) bool) (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for n := q.timeline.Min(); n != nil; n = q.timeline.Next(n) {
+		if match(n.Value.process) {
+			e := q.take(n)
+			q.metrics.record(e.process)
+			return e.process, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no queued process matches")
+}
+
+// Remove takes the process off the timeline without counting it as
+// dispatched. Like a dequeued process it stays runnable until RecordSlice
+func (q *CFSQueue) Remove(p types.Process) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	e, ok := q.entities[p.GetPID()]
+	if !ok || e.state != entityQueued {
+		return fmt.Errorf("process %d is not queued", p.GetPID())
+	}
+
+	q.take(e.node)
+	return nil
+}
+
+// CFS specific methods
+
+// GetCFSMetrics returns the scheduling metrics along with min_vruntime and the
+// vruntime of every known process, ordered by PID
+func (q *CFSQueue) GetCFSMetrics() CFSMetrics {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	processes := make([]CFSProcessMetrics, 0, len(q.entities))
+	for pid, e := range q.entities {
+		processes = append(processes, CFSProcessMetrics{
+			PID:      pid,
+			Vruntime: e.vruntime,
+			Weight:   e.weight,
+		})
+	}
+	sort.Slice(processes, func(i, j int) bool { return processes[i].PID < processes[j].PID })
+
+	return CFSMetrics{
+		SchedulingMetrics: q.metrics.snapshot(),
+		MinVruntime:       q.minVruntime,
+		Processes:         processes,
+	}
+}
+
+// GetVruntime returns the virtual runtime of the process
+func (q *CFSQueue) GetVruntime(p types.Process) time.Duration {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if e, ok := q.entities[p.GetPID()]; ok {
+		return e.vruntime
+	}
+	return 0
+}
+
+// GetMinVruntime returns the monotonically increasing floor of the vruntimes
+func (q *CFSQueue) GetMinVruntime() time.Duration {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.minVruntime
+}
+
+// GetTimeSlice returns the share of the scheduling period the process is
+// entitled to by its weight. The period is SchedLatency, stretched so that
+// no slice is shorter than MinGranularity
+func (q *CFSQueue) GetTimeSlice(p types.Process) time.Duration {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	e, ok := q.entities[p.GetPID()]
+	if !ok {
+		e = &cfsEntity{weight: NiceWeight(p.GetNice())}
+	}
+	return q.slice(e)
+}
+
+// RequeueProcess returns a preempted process to the timeline
+func (q *CFSQueue) RequeueProcess(p types.Process) error {
+	return q.Enqueue(p)
+}
+
+// ShouldPreempt reports whether the arrived process is far enough behind the
+// running one in virtual time to take over the CPU
+func (q *CFSQueue) ShouldPreempt(running, arrived types.Process) bool {
+	if running == nil || arrived == nil {
+		return false
+	}
+
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	curr, ok := q.entities[running.GetPID()]
+	if !ok {
+		return false
+	}
+	next, ok := q.entities[arrived.GetPID()]
+	if !ok {
+		return false
+	}
+
+	// Include the part of the current slice that has not been charged yet
+	currVruntime := curr.vruntime
+	if running.GetState() == types.RUNNING {
+		currVruntime += toVirtual(running.GetTimeInState(), curr.weight)
+	}
+	return currVruntime-next.vruntime > q.config.WakeupGranularity
+}
+
+// RecordSlice charges the CPU time of the slice to the vruntime of the process
+func (q *CFSQueue) RecordSlice(p types.Process, ran time.Duration, outcome types.SliceOutcome) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	e, ok := q.entities[p.GetPID()]
+	if !ok {
+		return
+	}
+
+	e.vruntime += toVirtual(ran, e.weight)
+
+	switch outcome {
+	case types.SliceCompleted, types.SliceFailed, types.SliceMigrated:
+		q.deactivate(e)
+		delete(q.entities, p.GetPID())
+	case types.SliceBlocked, types.SliceJobCompleted:
+		q.deactivate(e)
+	}
+
+	q.updateMinVruntime()
+}
+
+// take removes the node from the timeline and marks its entity running
+func (q *CFSQueue) take(n *rbtree.Node[*cfsEntity]) *cfsEntity {
+	e := n.Value
+	q.timeline.Delete(n)
+	e.node = nil
+	e.state = entityRunning
+	return e
+}
+
+func (q *CFSQueue) slice(e *cfsEntity) time.Duration {
+	nr := q.nrRunning
+	load := q.load
+	if e.state == entitySleeping {
+		nr++
+		load += e.weight
+	}
+
+	period := q.config.SchedLatency
+	if nrLatency := int(q.config.SchedLatency / q.config.MinGranularity); nr > nrLatency {
+		period = time.Duration(nr) * q.config.MinGranularity
+	}
+
+	slice := time.Duration(int64(period) * int64(e.weight) / int64(load))
+	if slice < q.config.MinGranularity {
+		return q.config.MinGranularity
+	}
+	return slice
+}
+
+// vslice is the slice of the entity expressed in virtual time
+func (q *CFSQueue) vslice(e *cfsEntity) time.Duration {
+	return toVirtual(q.slice(e), e.weight)
+}
+
+func (q *CFSQueue) activate(e *cfsEntity) {
+	if e.state != entitySleeping {
+		return
+	}
+	q.nrRunning++
+	q.load += e.weight
+	e.state = entityRunning
+}
+
+func (q *CFSQueue) deactivate(e *cfsEntity) {
+	if e.state == entitySleeping {
+		return
+	}
+	if e.node != nil {
+		q.timeline.Delete(e.node)
+		e.node = nil
+	}
+	q.nrRunning--
+	q.load -= e.weight
+	e.state = entitySleeping
+}
+
+func (q *CFSQueue) setWeight(e *cfsEntity, weight int) {
+	if e.state != entitySleeping {
+		q.load += weight - e.weight
+	}
+	e.weight = weight
+}
+
+// updateMinVruntime advances min_vruntime to the smallest vruntime of any
+// queued or running entity, never moving it backwards
+func (q *CFSQueue) updateMinVruntime() {
+	found := false
+	var min time.Duration
+
+	if leftmost := q.timeline.Min(); leftmost != nil {
+		min = leftmost.Value.vruntime
+		found = true
+	}
+	for _, e := range q.entities {
+		if e.state == entityRunning && (!found || e.vruntime < min) {
+			min = e.vruntime
+			found = true
+		}
+	}
+
+	if found && min > q.minVruntime {
+		q.minVruntime = min
+	}
+}
@@ -0,0 +1,276 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/process"
+	"cpu-scheduling/core/internal/types"
+	"testing"
+	"time"
+)
+
+func newNicePCB(pid, nice int) *process.PCB {
+	p := process.NewPCB(pid, process.NewTask(func() (any, error) { return nil, nil }))
+	p.SetNice(nice)
+	return p
+}
+
+// runSlice dequeues the next process and charges it a full slice
+func runSlice(t *testing.T, q *CFSQueue) types.Process {
+	t.Helper()
+
+	p, err := q.Dequeue()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	q.RecordSlice(p, q.GetTimeSlice(p), types.SliceExpired)
+	q.RequeueProcess(p)
+	return p
+}
+
+func TestNiceWeight(t *testing.T) {
+	t.Run("should follow the Linux weight table", func(t *testing.T) {
+		if NiceWeight(0) != 1024 || NiceWeight(-20) != 88761 || NiceWeight(19) != 15 {
+			t.Errorf("unexpected weights: %d %d %d", NiceWeight(0), NiceWeight(-20), NiceWeight(19))
+		}
+	})
+
+	t.Run("should clamp out of range nice values", func(t *testing.T) {
+		if NiceWeight(-100) != NiceWeight(-20) || NiceWeight(100) != NiceWeight(19) {
+			t.Error("expected out of range values to be clamped")
+		}
+	})
+}
+
+func TestNewCFSQueue(t *testing.T) {
+	t.Run("should use defaults for unset tunables", func(t *testing.T) {
+		queue := NewCFSQueue(CFSConfig{})
+
+		if queue.config != DefaultCFSConfig() {
+			t.Errorf("expected default config, got %+v", queue.config)
+		}
+		if !queue.IsEmpty() {
+			t.Error("new queue should be empty")
+		}
+	})
+}
+
+func TestCFSQueue_Enqueue(t *testing.T) {
+	t.Run("should return error for nil process", func(t *testing.T) {
+		if err := NewCFSQueue(CFSConfig{}).Enqueue(nil); err == nil {
+			t.Error("expected error for nil process")
+		}
+	})
+
+	t.Run("should return error for a process that is already queued", func(t *testing.T) {
+		queue := NewCFSQueue(CFSConfig{})
+		p := newNicePCB(1, 0)
+		queue.Enqueue(p)
+
+		if err := queue.Enqueue(p); err == nil {
+			t.Error("expected error for double enqueue")
+		}
+	})
+
+	t.Run("should place new processes at min_vruntime", func(t *testing.T) {
+		queue := NewCFSQueue(CFSConfig{})
+		veteran := newNicePCB(1, 0)
+		queue.Enqueue(veteran)
+		for i := 0; i < 10; i++ {
+			runSlice(t, queue)
+		}
+
+		newcomer := newNicePCB(2, 0)
+		queue.Enqueue(newcomer)
+
+		if queue.GetVruntime(newcomer) < queue.GetMinVruntime() {
+			t.Errorf("expected newcomer vruntime %v to be at least min_vruntime %v",
+				queue.GetVruntime(newcomer), queue.GetMinVruntime())
+		}
+	})
+}
+
+func TestCFSQueue_Dequeue(t *testing.T) {
+	t.Run("should run the smallest vruntime first", func(t *testing.T) {
+		queue := NewCFSQueue(CFSConfig{})
+		a := newNicePCB(1, 0)
+		b := newNicePCB(2, 0)
+		queue.Enqueue(a)
+		queue.Enqueue(b)
+
+		first := runSlice(t, queue)
+		second, _ := queue.Peek()
+
+		if first.GetPID() == second.GetPID() {
+			t.Error("expected the other process to run after a full slice")
+		}
+	})
+
+	t.Run("should share CPU time by weight", func(t *testing.T) {
+		queue := NewCFSQueue(CFSConfig{})
+		heavy := newNicePCB(1, -5)
+		light := newNicePCB(2, 0)
+		queue.Enqueue(heavy)
+		queue.Enqueue(light)
+
+		cpu := map[int]time.Duration{}
+		for i := 0; i < 200; i++ {
+			p, _ := queue.Dequeue()
+			queue.RecordSlice(p, time.Millisecond, types.SliceExpired)
+			cpu[p.GetPID()] += time.Millisecond
+			queue.RequeueProcess(p)
+		}
+
+		ratio := float64(cpu[1]) / float64(cpu[2])
+		expected := float64(NiceWeight(-5)) / float64(NiceWeight(0))
+		if ratio < expected*0.9 || ratio > expected*1.1 {
+			t.Errorf("expected CPU ratio near %.2f, got %.2f", expected, ratio)
+		}
+	})
+
+	t.Run("should return error when empty", func(t *testing.T) {
+		if _, err := NewCFSQueue(CFSConfig{}).Dequeue(); err == nil {
+			t.Error("expected error when dequeuing from empty queue")
+		}
+	})
+}
+
+func TestCFSQueue_GetTimeSlice(t *testing.T) {
+	t.Run("should divide the latency period by weight", func(t *testing.T) {
+		queue := NewCFSQueue(CFSConfig{})
+		a := newNicePCB(1, 0)
+		b := newNicePCB(2, 0)
+		queue.Enqueue(a)
+		queue.Enqueue(b)
+
+		if got := queue.GetTimeSlice(a); got != 3*time.Millisecond {
+			t.Errorf("expected 3ms slice, got %v", got)
+		}
+	})
+
+	t.Run("should stretch the period to respect min granularity", func(t *testing.T) {
+		queue := NewCFSQueue(CFSConfig{})
+		for i := 0; i < 20; i++ {
+			queue.Enqueue(newNicePCB(i, 0))
+		}
+
+		if got := queue.GetTimeSlice(newNicePCB(0, 0)); got != defaultMinGranularity {
+			t.Errorf("expected min granularity slice, got %v", got)
+		}
+	})
+}
+
+func TestCFSQueue_RecordSlice(t *testing.T) {
+	t.Run("should scale vruntime by the inverse weight", func(t *testing.T) {
+		queue := NewCFSQueue(CFSConfig{})
+		p := newNicePCB(1, 5)
+		queue.Enqueue(p)
+		queue.Dequeue()
+
+		before := queue.GetVruntime(p)
+		queue.RecordSlice(p, time.Millisecond, types.SliceExpired)
+
+		expected := time.Duration(int64(time.Millisecond) * nice0Weight / int64(NiceWeight(5)))
+		if got := queue.GetVruntime(p) - before; got != expected {
+			t.Errorf("expected vruntime to grow by %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("should forget completed processes", func(t *testing.T) {
+		queue := NewCFSQueue(CFSConfig{})
+		p := newNicePCB(1, 0)
+		queue.Enqueue(p)
+		queue.Dequeue()
+		queue.RecordSlice(p, time.Millisecond, types.SliceCompleted)
+
+		if len(queue.GetCFSMetrics().Processes) != 0 {
+			t.Error("expected completed process to be removed")
+		}
+	})
+
+	t.Run("should forget migrated processes", func(t *testing.T) {
+		queue := NewCFSQueue(CFSConfig{})
+		stays := newNicePCB(1, 0)
+		leaves := newNicePCB(2, 0)
+		queue.Enqueue(stays)
+		queue.Enqueue(leaves)
+		queue.Dequeue()
+		p, _ := queue.Dequeue()
+		queue.RecordSlice(p, 0, types.SliceMigrated)
+
+		if len(queue.GetCFSMetrics().Processes) != 1 || queue.load != NiceWeight(0) {
+			t.Errorf("expected only the remaining process to count, got load %d", queue.load)
+		}
+	})
+
+	t.Run("should limit the credit of a waking sleeper", func(t *testing.T) {
+		queue := NewCFSQueue(CFSConfig{})
+		sleeper := newNicePCB(1, 0)
+		worker := newNicePCB(2, 0)
+		queue.Enqueue(sleeper)
+		queue.Enqueue(worker)
+
+		// The sleeper blocks right away while the worker keeps running
+		p, _ := queue.Dequeue()
+		if p.GetPID() != 1 {
+			queue.RecordSlice(p, 0, types.SliceExpired)
+			queue.RequeueProcess(p)
+			p, _ = queue.Dequeue()
+		}
+		queue.RecordSlice(p, 0, types.SliceBlocked)
+		for i := 0; i < 50; i++ {
+			runSlice(t, queue)
+		}
+
+		queue.Enqueue(sleeper)
+
+		floor := queue.GetMinVruntime() - defaultSchedLatency/2
+		if queue.GetVruntime(sleeper) < floor {
+			t.Errorf("expected waking vruntime >= %v, got %v", floor, queue.GetVruntime(sleeper))
+		}
+	})
+}
+
+func TestCFSQueue_ShouldPreempt(t *testing.T) {
+	t.Run("should preempt for a waking process far behind in virtual time", func(t *testing.T) {
+		queue := NewCFSQueue(CFSConfig{})
+		sleeper := newNicePCB(1, 0)
+		worker := newNicePCB(2, 0)
+		queue.Enqueue(sleeper)
+		queue.Enqueue(worker)
+
+		p, _ := queue.Dequeue()
+		if p.GetPID() != 1 {
+			queue.RecordSlice(p, 0, types.SliceExpired)
+			queue.RequeueProcess(p)
+			p, _ = queue.Dequeue()
+		}
+		queue.RecordSlice(p, 0, types.SliceBlocked)
+		for i := 0; i < 10; i++ {
+			runSlice(t, queue)
+		}
+
+		running, _ := queue.Dequeue()
+		queue.Enqueue(sleeper)
+
+		if !queue.ShouldPreempt(running, sleeper) {
+			t.Error("expected waking sleeper to preempt")
+		}
+		if queue.ShouldPreempt(sleeper, running) {
+			t.Error("expected no preemption the other way round")
+		}
+	})
+
+	t.Run("should not preempt for a newly arrived process", func(t *testing.T) {
+		queue := NewCFSQueue(CFSConfig{})
+		running := newNicePCB(1, 0)
+		queue.Enqueue(running)
+		queue.Dequeue()
+		queue.RecordSlice(running, 20*time.Millisecond, types.SliceExpired)
+
+		arrived := newNicePCB(2, 0)
+		queue.Enqueue(arrived)
+
+		if queue.ShouldPreempt(running, arrived) {
+			t.Error("expected new process to wait for the running one")
+		}
+	})
+}
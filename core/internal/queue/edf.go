@@ -0,0 +1,280 @@
+package queue
+
+import (
+	"container/heap"
+	"cpu-scheduling/core/internal/types"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// utilizationBound is the highest total utilization EDF can schedule on one
+// CPU, with some slack for floating point error
+const utilizationBound = 1 + 1e-9
+
+// EDFMetrics extends the scheduling metrics with deadline statistics and the
+// utilization of the admitted processes
+type EDFMetrics struct {
+	types.SchedulingMetrics
+	DeadlineMetrics
+	Utilization float64
+}
+
+// deadlineEntry is a queued process together with the absolute deadline it is
+// ordered by
+type deadlineEntry struct {
+	process  types.Process
+	deadline time.Time
+	seq      uint64
+}
+
+// deadlineHeap is a min-heap of processes by absolute deadline. Processes
+// without a deadline come after all others, and ties are broken in arrival
+// order
+type deadlineHeap []*deadlineEntry
+
+func (h deadlineHeap) Len() int { return len(h) }
+
+func (h deadlineHeap) Less(i, j int) bool {
+	if !h[i].deadline.Equal(h[j].deadline) {
+		return earlierDeadline(h[i].deadline, h[j].deadline)
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h deadlineHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *deadlineHeap) Push(x any) { *h = append(*h, x.(*deadlineEntry)) }
+
+func (h *deadlineHeap) Pop() any {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return entry
+}
+
+// earlierDeadline reports whether deadline a comes before b, treating the zero
+// time as no deadline at all
+func earlierDeadline(a, b time.Time) bool {
+	switch {
+	case a.IsZero():
+		return false
+	case b.IsZero():
+		return true
+	default:
+		return a.Before(b)
+	}
+}
+
+// EDFQueue is an Earliest Deadline First queue for soft real-time processes.
+// The ready job with the nearest absolute deadline runs next, and an arriving
+// job with an earlier deadline preempts the running one. Processes without a
+// deadline only run when no real-time job is ready. With admission control a
+// process is refused if it would push the utilization of the admitted
+// processes above 1, beyond which EDF cannot meet every deadline
+type EDFQueue struct {
+	processes deadlineHeap
+	admission bool
+	nextSeq   uint64
+	mu        sync.RWMutex
+
+	// admitted holds the utilization of every process that has not
+	// completed yet
+	admitted    map[int]float64
+	utilization float64
+
+	metrics   metricsTracker
+	clock     types.Clock
+	deadlines deadlineTracker
+}
+
+func NewEDFQueue() *EDFQueue {
+	return &EDFQueue{
+		processes: make(deadlineHeap, 0),
+		admitted:  make(map[int]float64),
+		metrics:   newMetricsTracker(),
+		clock:     types.RealClock{},
+	}
+}
+
+// NewEDFQueueWithAdmission creates an EDFQueue that refuses processes the
+// task set could not be scheduled with
+func NewEDFQueueWithAdmission() *EDFQueue {
+	q := NewEDFQueue()
+	q.admission = true
+	return q
+}
+
+func (q *EDFQueue) Enqueue(p types.Process) error {
+	if p == nil {
+		return fmt.Errorf("cannot enqueue nil process")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.admitted[p.GetPID()]; !ok {
+		u := utilization(p)
+		if q.admission && q.utilization+u > utilizationBound {
+			return fmt.Errorf("admitting process %d would raise utilization to %.3f",
+				p.GetPID(), q.utilization+u)
+		}
+		q.admitted[p.GetPID()] = u
+		q.utilization += u
+	}
+
+	heap.Push(&q.processes, &deadlineEntry{
+		process:  p,
+		deadline: p.GetAbsoluteDeadline(),
+		seq:      q.nextSeq,
+	})
+	q.nextSeq++
+	return nil
+}
+
+func (q *EDFQueue) Dequeue() (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.processes) == 0 {
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	entry := heap.Pop(&q.processes).(*deadlineEntry)
+	q.metrics.record(entry.process)
+	return entry.process, nil
+}
+
+func (q *EDFQueue) Peek() (types.Process, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if len(q.processes) == 0 {
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	return q.processes[0].process, nil
+}
+
+func (q *EDFQueue) IsEmpty() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return len(q.processes) == 0
+}
+
+func (q *EDFQueue) Size() int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return len(q.processes)
+}
+
+func (q *EDFQueue) GetMetrics() types.SchedulingMetrics {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.metrics.snapshot()
+}
+
+func (q *EDFQueue) SetClock(clock types.Clock) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.metrics.setClock(clock)
+	q.clock = clock
+}
+
+// DequeueMatching dequeues the process with the earliest deadline that match
+// accepts
+func (q *EDFQueue) DequeueMatching(match func(types.Process) bool) (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := firstMatching(len(q.processes), q.processes.Less, func(i int) bool {
+		return match(q.processes[i].process)
+	})
+	if i < 0 {
+		return nil, fmt.Errorf("no queued process matches")
+	}
+
+	entry := heap.Remove(&q.processes, i).(*deadlineEntry)
+	q.metrics.record(entry.process)
+	return entry.process, nil
+}
+
+// Remove takes the process off the queue without counting it as dispatched
+func (q *EDFQueue) Remove(p types.Process) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := indexOf(q.processes, p, func(e *deadlineEntry) types.Process { return e.process })
+	if i < 0 {
+		return fmt.Errorf("process %d is not queued", p.GetPID())
+	}
+
+	heap.Remove(&q.processes, i)
+	return nil
+}
+
+// EDF specific methods
+
+// GetEDFMetrics returns the scheduling metrics along with deadline misses,
+// lateness and tardiness of the completed jobs
+func (q *EDFQueue) GetEDFMetrics() EDFMetrics {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return EDFMetrics{
+		SchedulingMetrics: q.metrics.snapshot(),
+		DeadlineMetrics:   q.deadlines.snapshot(),
+		Utilization:       q.utilization,
+	}
+}
+
+// GetUtilization returns the total utilization of the admitted processes
+func (q *EDFQueue) GetUtilization() float64 {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.utilization
+}
+
+// ShouldPreempt reports whether the arrived process has an earlier deadline
+// than the running one
+func (q *EDFQueue) ShouldPreempt(running, arrived types.Process) bool {
+	if running == nil || arrived == nil {
+		return false
+	}
+	return earlierDeadline(arrived.GetAbsoluteDeadline(), running.GetAbsoluteDeadline())
+}
+
+// RecordSlice accounts for the deadline of finished jobs, counting failed ones
+// apart, and releases the share of the utilization of processes that exited
+// or migrated
+func (q *EDFQueue) RecordSlice(p types.Process, ran time.Duration, outcome types.SliceOutcome) {
+	switch outcome {
+	case types.SliceCompleted, types.SliceJobCompleted, types.SliceFailed, types.SliceMigrated:
+	default:
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	switch outcome {
+	case types.SliceCompleted, types.SliceJobCompleted:
+		q.deadlines.record(p, q.clock.Now())
+	case types.SliceFailed:
+		q.deadlines.fail(p)
+	}
+	if outcome == types.SliceJobCompleted {
+		return
+	}
+	if u, ok := q.admitted[p.GetPID()]; ok {
+		delete(q.admitted, p.GetPID())
+		q.utilization -= u
+	}
+}
@@ -0,0 +1,195 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/process"
+	"cpu-scheduling/core/internal/types"
+	"testing"
+	"time"
+)
+
+func newRealtimePCB(pid int, period, wcet time.Duration) *process.PCB {
+	p := process.NewPCB(pid, process.NewTask(func() (any, error) { return nil, nil }))
+	p.SetPeriod(period)
+	p.SetWCET(wcet)
+	return p
+}
+
+func newDeadlinePCB(pid int, deadline time.Duration) *process.PCB {
+	p := process.NewPCB(pid, process.NewTask(func() (any, error) { return nil, nil }))
+	p.SetRelativeDeadline(deadline)
+	return p
+}
+
+func TestUtilization(t *testing.T) {
+	t.Run("should sum WCET over period", func(t *testing.T) {
+		u := Utilization(
+			newRealtimePCB(1, 10*time.Millisecond, 2*time.Millisecond),
+			newRealtimePCB(2, 20*time.Millisecond, 5*time.Millisecond),
+		)
+		if u < 0.449 || u > 0.451 {
+			t.Errorf("expected utilization 0.45, got %.3f", u)
+		}
+	})
+
+	t.Run("should ignore processes without timing constraints", func(t *testing.T) {
+		p := process.NewPCB(1, process.NewTask(func() (any, error) { return nil, nil }))
+		if u := Utilization(p); u != 0 {
+			t.Errorf("expected zero utilization, got %.3f", u)
+		}
+	})
+}
+
+func TestEDFQueue_Enqueue(t *testing.T) {
+	t.Run("should return error for nil process", func(t *testing.T) {
+		if err := NewEDFQueue().Enqueue(nil); err == nil {
+			t.Error("expected error for nil process")
+		}
+	})
+
+	t.Run("should refuse processes beyond full utilization with admission control", func(t *testing.T) {
+		queue := NewEDFQueueWithAdmission()
+		if err := queue.Enqueue(newRealtimePCB(1, 10*time.Millisecond, 6*time.Millisecond)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := queue.Enqueue(newRealtimePCB(2, 10*time.Millisecond, 4*time.Millisecond)); err != nil {
+			t.Fatalf("expected a utilization of exactly 1 to be admitted, got %v", err)
+		}
+
+		if err := queue.Enqueue(newRealtimePCB(3, 10*time.Millisecond, time.Millisecond)); err == nil {
+			t.Error("expected error for overloaded task set")
+		}
+		if queue.Size() != 2 {
+			t.Errorf("expected refused process not to be queued, got size %d", queue.Size())
+		}
+	})
+
+	t.Run("should accept overload without admission control", func(t *testing.T) {
+		queue := NewEDFQueue()
+		queue.Enqueue(newRealtimePCB(1, 10*time.Millisecond, 8*time.Millisecond))
+
+		if err := queue.Enqueue(newRealtimePCB(2, 10*time.Millisecond, 8*time.Millisecond)); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("should not count a requeued process twice", func(t *testing.T) {
+		queue := NewEDFQueueWithAdmission()
+		p := newRealtimePCB(1, 10*time.Millisecond, 6*time.Millisecond)
+		queue.Enqueue(p)
+		queue.Dequeue()
+
+		if err := queue.Enqueue(p); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestEDFQueue_Dequeue(t *testing.T) {
+	t.Run("should run the nearest deadline first", func(t *testing.T) {
+		queue := NewEDFQueue()
+		queue.Enqueue(process.NewPCB(1, process.NewTask(func() (any, error) { return nil, nil })))
+		queue.Enqueue(newDeadlinePCB(2, 50*time.Millisecond))
+		queue.Enqueue(newDeadlinePCB(3, 10*time.Millisecond))
+
+		for _, expected := range []int{3, 2, 1} {
+			p, err := queue.Dequeue()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.GetPID() != expected {
+				t.Errorf("expected PID %d, got %d", expected, p.GetPID())
+			}
+		}
+	})
+
+	t.Run("should return error when empty", func(t *testing.T) {
+		if _, err := NewEDFQueue().Dequeue(); err == nil {
+			t.Error("expected error when dequeuing from empty queue")
+		}
+	})
+}
+
+func TestEDFQueue_ShouldPreempt(t *testing.T) {
+	queue := NewEDFQueue()
+	near := newDeadlinePCB(1, 10*time.Millisecond)
+	far := newDeadlinePCB(2, 50*time.Millisecond)
+	none := process.NewPCB(3, process.NewTask(func() (any, error) { return nil, nil }))
+
+	if !queue.ShouldPreempt(far, near) {
+		t.Error("expected nearer deadline to preempt")
+	}
+	if queue.ShouldPreempt(near, far) {
+		t.Error("expected later deadline not to preempt")
+	}
+	if !queue.ShouldPreempt(none, far) || queue.ShouldPreempt(far, none) {
+		t.Error("expected real-time processes to preempt best effort ones only")
+	}
+}
+
+func TestEDFQueue_GetEDFMetrics(t *testing.T) {
+	t.Run("should track misses, lateness and tardiness", func(t *testing.T) {
+		queue := NewEDFQueue()
+		onTime := newDeadlinePCB(1, time.Hour)
+		late := newDeadlinePCB(2, time.Nanosecond)
+		queue.Enqueue(onTime)
+		queue.Enqueue(late)
+
+		time.Sleep(time.Millisecond)
+		for !queue.IsEmpty() {
+			p, _ := queue.Dequeue()
+			queue.RecordSlice(p, 0, types.SliceCompleted)
+		}
+
+		metrics := queue.GetEDFMetrics()
+		if metrics.Jobs != 2 || metrics.Misses != 1 || metrics.MissRatio != 0.5 {
+			t.Errorf("expected 1 miss in 2 jobs, got %+v", metrics.DeadlineMetrics)
+		}
+		if metrics.MaxLateness <= 0 || metrics.TotalTardiness != metrics.MaxLateness {
+			t.Errorf("expected the late job to account for all tardiness, got %+v", metrics.DeadlineMetrics)
+		}
+		if metrics.AverageLateness >= 0 {
+			t.Errorf("expected negative average lateness, got %v", metrics.AverageLateness)
+		}
+	})
+
+	t.Run("should release utilization of completed processes", func(t *testing.T) {
+		queue := NewEDFQueueWithAdmission()
+		p := newRealtimePCB(1, 10*time.Millisecond, 5*time.Millisecond)
+		queue.Enqueue(p)
+		queue.Dequeue()
+		queue.RecordSlice(p, 5*time.Millisecond, types.SliceCompleted)
+
+		if u := queue.GetEDFMetrics().Utilization; u != 0 {
+			t.Errorf("expected utilization to drop to 0, got %.3f", u)
+		}
+	})
+
+	t.Run("should count failed jobs apart from met and missed ones", func(t *testing.T) {
+		queue := NewEDFQueueWithAdmission()
+		p := newRealtimePCB(1, 10*time.Millisecond, 5*time.Millisecond)
+		queue.Enqueue(p)
+		queue.Dequeue()
+		queue.RecordSlice(p, time.Millisecond, types.SliceFailed)
+
+		metrics := queue.GetEDFMetrics()
+		if metrics.Jobs != 0 || metrics.Misses != 0 || metrics.Failed != 1 {
+			t.Errorf("expected one failed job and no met or missed ones, got %+v", metrics.DeadlineMetrics)
+		}
+		if metrics.Utilization != 0 {
+			t.Errorf("expected utilization to drop to 0, got %.3f", metrics.Utilization)
+		}
+	})
+
+	t.Run("should release utilization of migrated processes without a deadline", func(t *testing.T) {
+		queue := NewEDFQueueWithAdmission()
+		p := newRealtimePCB(1, 10*time.Millisecond, 5*time.Millisecond)
+		queue.Enqueue(p)
+		queue.Dequeue()
+		queue.RecordSlice(p, 0, types.SliceMigrated)
+
+		metrics := queue.GetEDFMetrics()
+		if metrics.Utilization != 0 || metrics.Jobs != 0 {
+			t.Errorf("expected no utilization and no jobs, got %+v", metrics)
+		}
+	})
+}
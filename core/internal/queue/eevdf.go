@@ -0,0 +1,456 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/rbtree"
+	"cpu-scheduling/core/internal/types"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const defaultBaseSlice = 3 * time.Millisecond
+
+// EEVDFConfig holds the tunables of the EEVDF scheduler. BaseSlice is the
+// slice of processes that do not request one of their own
+type EEVDFConfig struct {
+	BaseSlice time.Duration
+}
+
+// DefaultEEVDFConfig returns the Linux base slice for a machine with a few
+// CPUs
+func DefaultEEVDFConfig() EEVDFConfig {
+	return EEVDFConfig{BaseSlice: defaultBaseSlice}
+}
+
+// EEVDFProcessMetrics reports the fairness state of a single process. Lag is
+// the virtual time the process is owed, positive when it received less than
+// its share. EligibleTime is the virtual time from which it may run, and it
+// is Eligible once the average vruntime has reached it
+type EEVDFProcessMetrics struct {
+	PID          int
+	Weight       int
+	Slice        time.Duration
+	Lag          time.Duration
+	EligibleTime time.Duration
+	Deadline     time.Duration
+	Eligible     bool
+}
+
+// EEVDFMetrics extends the scheduling metrics with the lag and deadlines of
+// every known process
+type EEVDFMetrics struct {
+	types.SchedulingMetrics
+	AvgVruntime time.Duration
+	Processes   []EEVDFProcessMetrics
+}
+
+// eevdfEntity is the scheduling entity EEVDF keeps for every known process
+type eevdfEntity struct {
+	process  types.Process
+	vruntime time.Duration
+	deadline time.Duration
+	// vlag is the lag saved when the entity went to sleep
+	vlag   time.Duration
+	slice  time.Duration
+	weight int
+	state  entityState
+	seq    uint64
+	node   *rbtree.Node[*eevdfEntity]
+}
+
+// EEVDFQueue is a Linux inspired Earliest Eligible Virtual Deadline First
+// scheduler. Like CFS it tracks virtual runtime, but a process is only
+// eligible while it has not received more than its share, that is while its
+// vruntime is not ahead of the weighted average. Among the eligible processes
+// the one with the earliest virtual deadline, its vruntime plus its requested
+// slice in virtual time, runs next. Processes with shorter slices therefore
+// run sooner without getting more CPU time overall
+type EEVDFQueue struct {
+	timeline *rbtree.Tree[*eevdfEntity]
+	entities map[int]*eevdfEntity
+	config   EEVDFConfig
+	nextSeq  uint64
+	mu       sync.RWMutex
+
+	// avgVruntime is the weighted average vruntime of the queued and running
+	// entities. It keeps its last value while nothing is runnable, so
+	// sleepers wake relative to where they left
+	avgVruntime time.Duration
+	load        int64
+
+	metrics metricsTracker
+}
+
+// NewEEVDFQueue creates an EEVDF queue, using the default base slice if none
+// is set
+func NewEEVDFQueue(config EEVDFConfig) *EEVDFQueue {
+	if config.BaseSlice <= 0 {
+		config.BaseSlice = defaultBaseSlice
+	}
+
+	return &EEVDFQueue{
+		timeline: rbtree.New(func(a, b *eevdfEntity) bool {
+			if a.deadline != b.deadline {
+				return a.deadline < b.deadline
+			}
+			return a.seq < b.seq
+		}),
+		entities: make(map[int]*eevdfEntity),
+		config:   config,
+		metrics:  newMetricsTracker(),
+	}
+}
+
+func (q *EEVDFQueue) Enqueue(p types.Process) error {
+	if p == nil {
+		return fmt.Errorf("cannot enqueue nil process")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	e, known := q.entities[p.GetPID()]
+	if !known {
+		e = &eevdfEntity{process: p, state: entitySleeping}
+		q.entities[p.GetPID()] = e
+	}
+	if e.state == entityQueued {
+		return fmt.Errorf("process %d is already queued", p.GetPID())
+	}
+
+	e.weight = NiceWeight(p.GetNice())
+	e.slice = q.requestedSlice(p)
+	if e.state == entitySleeping {
+		q.place(e)
+	}
+
+	e.state = entityQueued
+	e.seq = q.nextSeq
+	q.nextSeq++
+	e.node = q.timeline.Insert(e)
+
+	q.updateAvgVruntime()
+	return nil
+}
+
+func (q *EEVDFQueue) Dequeue() (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	next := q.pick()
+	if next == nil {
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	e := q.take(next)
+	q.metrics.record(e.process)
+	return e.process, nil
+}
+
+func (q *EEVDFQueue) Peek() (types.Process, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	next := q.pick()
+	if next == nil {
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	return next.Value.process, nil
+}
+
+func (q *EEVDFQueue) IsEmpty() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.timeline.Len() == 0
+}
+
+func (q *EEVDFQueue) Size() int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.timeline.Len()
+}
+
+func (q *EEVDFQueue) GetMetrics() types.SchedulingMetrics {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.metrics.snapshot()
+}
+
+func (q *EEVDFQueue) SetClock(clock types.Clock) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.metrics.setClock(clock)
+}
+
+// DequeueMatching dequeues the process pick would choose among those match
+// accepts
+func (q *EEVDFQueue) DequeueMatching(match func(types.Process) bool) (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	next := q.pickMatching(match)
+	if next == nil {
+		return nil, fmt.Errorf("no queued process matches")
+	}
+
+	e := q.take(next)
+	q.metrics.record(e.process)
+	return e.process, nil
+}
+
+// Remove takes the process off the timeline without counting it as
+// dispatched. Like a dequeued process it stays runnable until RecordSlice
+func (q *EEVDFQueue) Remove(p types.Process) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	e, ok := q.entities[p.GetPID()]
+	if !ok || e.state != entityQueued {
+		return fmt.Errorf("process %d is not queued", p.GetPID())
+	}
+
+	q.take(e.node)
+	return nil
+}
+
+// EEVDF specific methods
+
+// GetEEVDFMetrics returns the scheduling metrics along with the lag, eligible
+// time and deadline of every known process, ordered by PID
+func (q *EEVDFQueue) GetEEVDFMetrics() EEVDFMetrics {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	processes := make([]EEVDFProcessMetrics, 0, len(q.entities))
+	for pid, e := range q.entities {
+		processes = append(processes, EEVDFProcessMetrics{
+			PID:          pid,
+			Weight:       e.weight,
+			Slice:        e.slice,
+			Lag:          q.lag(e),
+			EligibleTime: e.vruntime,
+			Deadline:     e.deadline,
+			Eligible:     q.eligible(e),
+		})
+	}
+	sort.Slice(processes, func(i, j int) bool { return processes[i].PID < processes[j].PID })
+
+	return EEVDFMetrics{
+		SchedulingMetrics: q.metrics.snapshot(),
+		AvgVruntime:       q.avgVruntime,
+		Processes:         processes,
+	}
+}
+
+// GetLag returns the virtual time the process is owed. Sleeping processes
+// report the lag they will resume with
+func (q *EEVDFQueue) GetLag(p types.Process) time.Duration {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if e, ok := q.entities[p.GetPID()]; ok {
+		return q.lag(e)
+	}
+	return 0
+}
+
+// GetAvgVruntime returns the weighted average vruntime that decides eligibility
+func (q *EEVDFQueue) GetAvgVruntime() time.Duration {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.avgVruntime
+}
+
+// GetTimeSlice returns the CPU time left until the virtual deadline of the
+// process, when it has to make way for the next one
+func (q *EEVDFQueue) GetTimeSlice(p types.Process) time.Duration {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	e, ok := q.entities[p.GetPID()]
+	if !ok || e.state == entitySleeping {
+		return q.requestedSlice(p)
+	}
+
+	if remaining := fromVirtual(e.deadline-e.vruntime, e.weight); remaining > 0 {
+		return remaining
+	}
+	return e.slice
+}
+
+// RequeueProcess returns a preempted process to the timeline, keeping its
+// vruntime and deadline
+func (q *EEVDFQueue) RequeueProcess(p types.Process) error {
+	return q.Enqueue(p)
+}
+
+// ShouldPreempt reports whether the arrived process is eligible and has an
+// earlier virtual deadline than the running one
+func (q *EEVDFQueue) ShouldPreempt(running, arrived types.Process) bool {
+	if running == nil || arrived == nil {
+		return false
+	}
+
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	curr, ok := q.entities[running.GetPID()]
+	if !ok {
+		return false
+	}
+	next, ok := q.entities[arrived.GetPID()]
+	if !ok || !q.eligible(next) {
+		return false
+	}
+
+	// Include the part of the current slice that has not been charged yet
+	currDeadline := curr.deadline
+	if running.GetState() == types.RUNNING {
+		vruntime := curr.vruntime + toVirtual(running.GetTimeInState(), curr.weight)
+		if vruntime >= currDeadline {
+			currDeadline = vruntime + toVirtual(curr.slice, curr.weight)
+		}
+	}
+	return next.deadline < currDeadline
+}
+
+// RecordSlice charges the CPU time of the slice to the vruntime of the process
+// and sets a new deadline once the old one has passed. A process that blocks
+// keeps its lag until it wakes up again
+func (q *EEVDFQueue) RecordSlice(p types.Process, ran time.Duration, outcome types.SliceOutcome) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	e, ok := q.entities[p.GetPID()]
+	if !ok {
+		return
+	}
+
+	e.vruntime += toVirtual(ran, e.weight)
+	if e.vruntime >= e.deadline {
+		e.deadline = e.vruntime + toVirtual(e.slice, e.weight)
+	}
+
+	switch outcome {
+	case types.SliceCompleted, types.SliceFailed, types.SliceMigrated:
+		q.deactivate(e)
+		delete(q.entities, p.GetPID())
+	case types.SliceBlocked, types.SliceJobCompleted:
+		q.updateAvgVruntime()
+		e.vlag = q.clampLag(e, q.avgVruntime-e.vruntime)
+		q.deactivate(e)
+	}
+
+	q.updateAvgVruntime()
+}
+
+func (q *EEVDFQueue) requestedSlice(p types.Process) time.Duration {
+	if slice := p.GetRequestedSlice(); slice > 0 {
+		return slice
+	}
+	return q.config.BaseSlice
+}
+
+// place positions a new or waking entity so that it resumes with the lag it
+// had when it went to sleep. Adding the entity pulls the average towards it,
+// so the lag is inflated by the weight it adds to keep it intact afterwards
+func (q *EEVDFQueue) place(e *eevdfEntity) {
+	lag := e.vlag
+	if q.load > 0 {
+		lag = time.Duration(int64(lag) * (q.load + int64(e.weight)) / q.load)
+	}
+
+	e.vruntime = q.avgVruntime - lag
+	e.deadline = e.vruntime + toVirtual(e.slice, e.weight)
+	e.vlag = 0
+}
+
+// pick returns the eligible node with the earliest deadline. When no queued
+// entity is eligible, because a running one holds the average back, it falls
+// back to the earliest deadline overall
+func (q *EEVDFQueue) pick() *rbtree.Node[*eevdfEntity] {
+	return q.pickMatching(func(types.Process) bool { return true })
+}
+
+// pickMatching is pick among the entities whose process match accepts
+func (q *EEVDFQueue) pickMatching(match func(types.Process) bool) *rbtree.Node[*eevdfEntity] {
+	var earliest *rbtree.Node[*eevdfEntity]
+	for n := q.timeline.Min(); n != nil; n = q.timeline.Next(n) {
+		if !match(n.Value.process) {
+			continue
+		}
+		if q.eligible(n.Value) {
+			return n
+		}
+		if earliest == nil {
+			earliest = n
+		}
+	}
+	return earliest
+}
+
+// take removes the node from the timeline and marks its entity running
+func (q *EEVDFQueue) take(n *rbtree.Node[*eevdfEntity]) *eevdfEntity {
+	e := n.Value
+	q.timeline.Delete(n)
+	e.node = nil
+	e.state = entityRunning
+	return e
+}
+
+func (q *EEVDFQueue) eligible(e *eevdfEntity) bool {
+	return e.state != entitySleeping && e.vruntime <= q.avgVruntime
+}
+
+func (q *EEVDFQueue) lag(e *eevdfEntity) time.Duration {
+	if e.state == entitySleeping {
+		return e.vlag
+	}
+	return q.avgVruntime - e.vruntime
+}
+
+// clampLag bounds the lag to two slices, so a process cannot bank unlimited
+// credit or debt across a sleep
+func (q *EEVDFQueue) clampLag(e *eevdfEntity, lag time.Duration) time.Duration {
+	limit := toVirtual(2*e.slice, e.weight)
+	if lag > limit {
+		return limit
+	}
+	if lag < -limit {
+		return -limit
+	}
+	return lag
+}
+
+func (q *EEVDFQueue) deactivate(e *eevdfEntity) {
+	if e.node != nil {
+		q.timeline.Delete(e.node)
+		e.node = nil
+	}
+	e.state = entitySleeping
+}
+
+// updateAvgVruntime recomputes the weighted average vruntime of the queued and
+// running entities, relative to the previous average to keep the sums small
+func (q *EEVDFQueue) updateAvgVruntime() {
+	var load, sum int64
+	for _, e := range q.entities {
+		if e.state == entitySleeping {
+			continue
+		}
+		load += int64(e.weight)
+		sum += int64(e.vruntime-q.avgVruntime) * int64(e.weight)
+	}
+
+	if load > 0 {
+		q.avgVruntime += time.Duration(sum / load)
+	}
+	q.load = load
+}
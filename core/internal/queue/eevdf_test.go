@@ -0,0 +1,275 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/process"
+	"cpu-scheduling/core/internal/types"
+	"testing"
+	"time"
+)
+
+func newSlicePCB(pid int, slice time.Duration) *process.PCB {
+	p := newNicePCB(pid, 0)
+	p.SetRequestedSlice(slice)
+	return p
+}
+
+// runEEVDFSlice dequeues the next process and runs it up to its deadline
+func runEEVDFSlice(t *testing.T, q *EEVDFQueue) types.Process {
+	t.Helper()
+
+	p, err := q.Dequeue()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	q.RecordSlice(p, q.GetTimeSlice(p), types.SliceExpired)
+	q.RequeueProcess(p)
+	return p
+}
+
+func TestNewEEVDFQueue(t *testing.T) {
+	t.Run("should use the default base slice", func(t *testing.T) {
+		queue := NewEEVDFQueue(EEVDFConfig{})
+
+		if queue.config != DefaultEEVDFConfig() {
+			t.Errorf("expected default config, got %+v", queue.config)
+		}
+		if !queue.IsEmpty() {
+			t.Error("new queue should be empty")
+		}
+	})
+}
+
+func TestEEVDFQueue_Enqueue(t *testing.T) {
+	t.Run("should return error for nil process", func(t *testing.T) {
+		if err := NewEEVDFQueue(EEVDFConfig{}).Enqueue(nil); err == nil {
+			t.Error("expected error for nil process")
+		}
+	})
+
+	t.Run("should return error for a process that is already queued", func(t *testing.T) {
+		queue := NewEEVDFQueue(EEVDFConfig{})
+		p := newNicePCB(1, 0)
+		queue.Enqueue(p)
+
+		if err := queue.Enqueue(p); err == nil {
+			t.Error("expected error for double enqueue")
+		}
+	})
+
+	t.Run("should start new processes with zero lag", func(t *testing.T) {
+		queue := NewEEVDFQueue(EEVDFConfig{})
+		veteran := newNicePCB(1, 0)
+		queue.Enqueue(veteran)
+		for i := 0; i < 10; i++ {
+			runEEVDFSlice(t, queue)
+		}
+
+		newcomer := newNicePCB(2, 0)
+		queue.Enqueue(newcomer)
+
+		if lag := queue.GetLag(newcomer); lag != 0 {
+			t.Errorf("expected zero lag, got %v", lag)
+		}
+	})
+}
+
+func TestEEVDFQueue_Dequeue(t *testing.T) {
+	t.Run("should run the earliest virtual deadline first", func(t *testing.T) {
+		queue := NewEEVDFQueue(EEVDFConfig{})
+		queue.Enqueue(newSlicePCB(1, 10*time.Millisecond))
+		queue.Enqueue(newSlicePCB(2, time.Millisecond))
+
+		p, _ := queue.Dequeue()
+		if p.GetPID() != 2 {
+			t.Errorf("expected the short slice process first, got %d", p.GetPID())
+		}
+	})
+
+	t.Run("should skip processes that are not eligible", func(t *testing.T) {
+		queue := NewEEVDFQueue(EEVDFConfig{})
+		short := newSlicePCB(1, time.Millisecond)
+		long := newSlicePCB(2, 10*time.Millisecond)
+		queue.Enqueue(short)
+		queue.Enqueue(long)
+
+		// The short process overruns and is ahead of the average afterwards,
+		// even though its new deadline is still the earliest
+		p, _ := queue.Dequeue()
+		queue.RecordSlice(p, 5*time.Millisecond, types.SliceExpired)
+		queue.RequeueProcess(p)
+
+		next, _ := queue.Dequeue()
+		if next.GetPID() != 2 {
+			t.Errorf("expected the eligible process to run, got %d", next.GetPID())
+		}
+	})
+
+	t.Run("should share CPU time by weight", func(t *testing.T) {
+		queue := NewEEVDFQueue(EEVDFConfig{})
+		heavy := newNicePCB(1, -5)
+		light := newNicePCB(2, 0)
+		queue.Enqueue(heavy)
+		queue.Enqueue(light)
+
+		cpu := map[int]time.Duration{}
+		for i := 0; i < 200; i++ {
+			p, _ := queue.Dequeue()
+			queue.RecordSlice(p, time.Millisecond, types.SliceExpired)
+			cpu[p.GetPID()] += time.Millisecond
+			queue.RequeueProcess(p)
+		}
+
+		ratio := float64(cpu[1]) / float64(cpu[2])
+		expected := float64(NiceWeight(-5)) / float64(NiceWeight(0))
+		if ratio < expected*0.9 || ratio > expected*1.1 {
+			t.Errorf("expected CPU ratio near %.2f, got %.2f", expected, ratio)
+		}
+	})
+
+	t.Run("should return error when empty", func(t *testing.T) {
+		if _, err := NewEEVDFQueue(EEVDFConfig{}).Dequeue(); err == nil {
+			t.Error("expected error when dequeuing from empty queue")
+		}
+	})
+}
+
+func TestEEVDFQueue_GetTimeSlice(t *testing.T) {
+	t.Run("should use the requested slice", func(t *testing.T) {
+		queue := NewEEVDFQueue(EEVDFConfig{})
+		p := newSlicePCB(1, 2*time.Millisecond)
+		queue.Enqueue(p)
+
+		if got := queue.GetTimeSlice(p); got != 2*time.Millisecond {
+			t.Errorf("expected 2ms slice, got %v", got)
+		}
+	})
+
+	t.Run("should return the time left until the deadline", func(t *testing.T) {
+		queue := NewEEVDFQueue(EEVDFConfig{})
+		p := newNicePCB(1, 0)
+		queue.Enqueue(p)
+		queue.Dequeue()
+		queue.RecordSlice(p, time.Millisecond, types.SliceExpired)
+
+		if got := queue.GetTimeSlice(p); got != defaultBaseSlice-time.Millisecond {
+			t.Errorf("expected %v, got %v", defaultBaseSlice-time.Millisecond, got)
+		}
+	})
+}
+
+func TestEEVDFQueue_RecordSlice(t *testing.T) {
+	// sleepAfterWork runs a for a full slice and lets b block after 1ms, so b
+	// leaves with a lag of 1ms
+	sleepAfterWork := func(t *testing.T, queue *EEVDFQueue, a, b types.Process) {
+		t.Helper()
+
+		queue.Enqueue(a)
+		queue.Enqueue(b)
+		if p := runEEVDFSlice(t, queue); p.GetPID() != a.GetPID() {
+			t.Fatalf("expected %d to run first, got %d", a.GetPID(), p.GetPID())
+		}
+
+		p, _ := queue.Dequeue()
+		queue.RecordSlice(p, time.Millisecond, types.SliceBlocked)
+	}
+
+	t.Run("should keep the lag of a sleeping process", func(t *testing.T) {
+		queue := NewEEVDFQueue(EEVDFConfig{})
+		a := newNicePCB(1, 0)
+		b := newNicePCB(2, 0)
+		sleepAfterWork(t, queue, a, b)
+
+		if lag := queue.GetLag(b); lag != time.Millisecond {
+			t.Errorf("expected lag of 1ms while sleeping, got %v", lag)
+		}
+
+		for i := 0; i < 10; i++ {
+			runEEVDFSlice(t, queue)
+		}
+		queue.Enqueue(b)
+
+		if lag := queue.GetLag(b); lag != time.Millisecond {
+			t.Errorf("expected lag of 1ms after waking, got %v", lag)
+		}
+	})
+
+	t.Run("should clamp the lag to two slices", func(t *testing.T) {
+		queue := NewEEVDFQueue(EEVDFConfig{})
+		a := newSlicePCB(1, time.Millisecond)
+		b := newSlicePCB(2, time.Millisecond)
+		queue.Enqueue(a)
+		queue.Enqueue(b)
+
+		p, _ := queue.Dequeue()
+		queue.RecordSlice(p, 100*time.Millisecond, types.SliceBlocked)
+
+		if lag := queue.GetLag(p); lag != -2*time.Millisecond {
+			t.Errorf("expected lag to be clamped to -2ms, got %v", lag)
+		}
+	})
+
+	t.Run("should forget completed processes", func(t *testing.T) {
+		queue := NewEEVDFQueue(EEVDFConfig{})
+		p := newNicePCB(1, 0)
+		queue.Enqueue(p)
+		queue.Dequeue()
+		queue.RecordSlice(p, time.Millisecond, types.SliceCompleted)
+
+		if len(queue.GetEEVDFMetrics().Processes) != 0 {
+			t.Error("expected completed process to be removed")
+		}
+	})
+}
+
+func TestEEVDFQueue_ShouldPreempt(t *testing.T) {
+	t.Run("should preempt for an eligible process with an earlier deadline", func(t *testing.T) {
+		queue := NewEEVDFQueue(EEVDFConfig{})
+		worker := newNicePCB(1, 0)
+		sleeper := newNicePCB(2, 0)
+		queue.Enqueue(worker)
+		queue.Enqueue(sleeper)
+		runEEVDFSlice(t, queue)
+		p, _ := queue.Dequeue()
+		queue.RecordSlice(p, time.Millisecond, types.SliceBlocked)
+		for i := 0; i < 10; i++ {
+			runEEVDFSlice(t, queue)
+		}
+
+		running, _ := queue.Dequeue()
+		queue.Enqueue(sleeper)
+
+		if !queue.ShouldPreempt(running, sleeper) {
+			t.Error("expected waking process with positive lag to preempt")
+		}
+		if queue.ShouldPreempt(sleeper, running) {
+			t.Error("expected no preemption the other way round")
+		}
+	})
+}
+
+func TestEEVDFQueue_GetEEVDFMetrics(t *testing.T) {
+	t.Run("should report lag and eligibility per process", func(t *testing.T) {
+		queue := NewEEVDFQueue(EEVDFConfig{})
+		a := newNicePCB(1, 0)
+		b := newNicePCB(2, 0)
+		queue.Enqueue(a)
+		queue.Enqueue(b)
+		runEEVDFSlice(t, queue)
+
+		metrics := queue.GetEEVDFMetrics()
+		if len(metrics.Processes) != 2 || metrics.Processes[0].PID != 1 {
+			t.Fatalf("expected two processes ordered by PID, got %+v", metrics.Processes)
+		}
+
+		ran, waited := metrics.Processes[0], metrics.Processes[1]
+		if ran.Lag >= 0 || ran.Eligible {
+			t.Errorf("expected the process that ran to be behind and ineligible, got %+v", ran)
+		}
+		if waited.Lag <= 0 || !waited.Eligible {
+			t.Errorf("expected the waiting process to be owed time and eligible, got %+v", waited)
+		}
+		if ran.Lag+waited.Lag != 0 {
+			t.Errorf("expected lags of equal weights to cancel out, got %v and %v", ran.Lag, waited.Lag)
+		}
+	})
+}
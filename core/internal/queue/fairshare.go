@@ -0,0 +1,561 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/types"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultShareWeight is the weight of groups and users that have not been
+	// given one, matching the cgroup cpu.weight default
+	DefaultShareWeight = 100
+	// MaxShareWeight is the largest weight a group or user can have
+	MaxShareWeight = 10000
+)
+
+// FairShareConfig configures a fair-share queue. Every process runs for at
+// most Quantum per turn
+type FairShareConfig struct {
+	Quantum time.Duration
+}
+
+// FairShareUserMetrics reports the consumption of a user within a group
+type FairShareUserMetrics struct {
+	User      string
+	Group     string
+	Weight    int
+	Processes int
+	CPUTime   time.Duration
+	CPUShare  float64
+}
+
+// FairShareGroupMetrics reports the consumption of a group and everything
+// below it
+type FairShareGroupMetrics struct {
+	Path     string
+	Weight   int
+	CPUTime  time.Duration
+	CPUShare float64
+}
+
+// FairShareMetrics extends the scheduling metrics with the consumption of
+// every group and user, ordered by path and name
+type FairShareMetrics struct {
+	types.SchedulingMetrics
+	Groups []FairShareGroupMetrics
+	Users  []FairShareUserMetrics
+}
+
+type fairNodeKind int
+
+const (
+	fairGroup fairNodeKind = iota
+	fairUser
+	fairProcess
+)
+
+// fairNode is a group, a user or a process in the share hierarchy
+type fairNode struct {
+	kind     fairNodeKind
+	name     string
+	key      string
+	parent   *fairNode
+	children map[string]*fairNode
+	weight   int
+
+	// vtime is the CPU time of the node scaled by the inverse of its weight.
+	// Siblings with the lowest vtime are picked first
+	vtime   float64
+	cpuTime time.Duration
+	seq     uint64
+
+	// queued counts the queued processes below the node and active those
+	// that are queued or running
+	queued int
+	active int
+
+	process types.Process
+}
+
+// FairShareQueue divides the CPU among groups, then among the users of each
+// group, and only then among the processes of each user. Groups form a
+// hierarchy like cgroups, and at every level the CPU is split between the
+// siblings that have work in proportion to their weight. A user who starts
+// many processes therefore gets no more CPU than one who starts a single one
+type FairShareQueue struct {
+	root         *fairNode
+	processes    map[int]*fairNode
+	groupWeights map[string]int
+	userWeights  map[string]int
+	quantum      time.Duration
+	nextSeq      uint64
+	mu           sync.RWMutex
+
+	metrics metricsTracker
+}
+
+func NewFairShareQueue(config FairShareConfig) *FairShareQueue {
+	quantum := config.Quantum
+	if quantum <= 0 {
+		quantum = defaultShareQuantum
+	}
+
+	return &FairShareQueue{
+		root: &fairNode{
+			kind:     fairGroup,
+			name:     types.RootGroup,
+			children: make(map[string]*fairNode),
+			weight:   DefaultShareWeight,
+		},
+		processes:    make(map[int]*fairNode),
+		groupWeights: make(map[string]int),
+		userWeights:  make(map[string]int),
+		quantum:      quantum,
+		metrics:      newMetricsTracker(),
+	}
+}
+
+func (q *FairShareQueue) Enqueue(p types.Process) error {
+	if p == nil {
+		return fmt.Errorf("cannot enqueue nil process")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	leaf, known := q.processes[p.GetPID()]
+	if !known {
+		leaf = q.leaf(p)
+	}
+	if leaf.queued > 0 {
+		return fmt.Errorf("process %d is already queued", p.GetPID())
+	}
+	leaf.weight = NiceWeight(p.GetNice())
+
+	// Nodes that become active resume at the pace of their busy siblings
+	// rather than claiming the CPU time they did not use
+	if leaf.active == 0 {
+		for n := leaf; n != q.root; n = n.parent {
+			if n.active > 0 {
+				break
+			}
+			if floor := minActiveVTime(n.parent, n); floor != math.MaxFloat64 && n.vtime < floor {
+				n.vtime = floor
+			}
+			n.seq = q.nextSeq
+			q.nextSeq++
+		}
+		q.adjust(leaf, 0, 1)
+	}
+
+	q.adjust(leaf, 1, 0)
+	return nil
+}
+
+func (q *FairShareQueue) Dequeue() (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	leaf := q.pick()
+	if leaf == nil {
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	q.adjust(leaf, -1, 0)
+	q.metrics.record(leaf.process)
+	return leaf.process, nil
+}
+
+func (q *FairShareQueue) Peek() (types.Process, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	leaf := q.pick()
+	if leaf == nil {
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	return leaf.process, nil
+}
+
+func (q *FairShareQueue) IsEmpty() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.root.queued == 0
+}
+
+func (q *FairShareQueue) Size() int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.root.queued
+}
+
+func (q *FairShareQueue) GetMetrics() types.SchedulingMetrics {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.metrics.snapshot()
+}
+
+func (q *FairShareQueue) SetClock(clock types.Clock) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.metrics.setClock(clock)
+}
+
+// DequeueMatching dequeues the process match accepts that comes first when
+// descending the hierarchy in pick order, passing over siblings with nothing
+// match accepts below them
+func (q *FairShareQueue) DequeueMatching(match func(types.Process) bool) (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	leaf := q.pickMatching(q.root, match)
+	if leaf == nil {
+		return nil, fmt.Errorf("no queued process matches")
+	}
+
+	q.adjust(leaf, -1, 0)
+	q.metrics.record(leaf.process)
+	return leaf.process, nil
+}
+
+// Remove takes the process off the queue without counting it as dispatched.
+// It stays active until RecordSlice, like a dequeued process
+func (q *FairShareQueue) Remove(p types.Process) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	leaf, ok := q.processes[p.GetPID()]
+	if !ok || leaf.queued == 0 {
+		return fmt.Errorf("process %d is not queued", p.GetPID())
+	}
+
+	q.adjust(leaf, -1, 0)
+	return nil
+}
+
+// Fair-share specific methods
+
+// SetGroupWeight sets the weight of the group at path relative to its sibling
+// groups and users
+func (q *FairShareQueue) SetGroupWeight(path string, weight int) error {
+	if err := validateShareWeight(weight); err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	path = cleanGroupPath(path)
+	if path == types.RootGroup {
+		return fmt.Errorf("cannot weight the root group")
+	}
+
+	q.groupWeights[path] = weight
+	if group := q.findGroup(path); group != nil {
+		group.weight = weight
+	}
+	return nil
+}
+
+// SetUserWeight sets the weight of the user relative to the other users and
+// groups in every group the user has processes in
+func (q *FairShareQueue) SetUserWeight(user string, weight int) error {
+	if err := validateShareWeight(weight); err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.userWeights[user] = weight
+	q.walk(q.root, func(n *fairNode) {
+		if n.kind == fairUser && n.name == user {
+			n.weight = weight
+		}
+	})
+	return nil
+}
+
+// GetFairShareMetrics returns the scheduling metrics along with the CPU time
+// and share of every group and user
+func (q *FairShareQueue) GetFairShareMetrics() FairShareMetrics {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	total := q.root.cpuTime
+	share := func(d time.Duration) float64 {
+		if total == 0 {
+			return 0
+		}
+		return float64(d) / float64(total)
+	}
+
+	metrics := FairShareMetrics{SchedulingMetrics: q.metrics.snapshot()}
+	q.walk(q.root, func(n *fairNode) {
+		switch n.kind {
+		case fairGroup:
+			metrics.Groups = append(metrics.Groups, FairShareGroupMetrics{
+				Path:     groupPath(n),
+				Weight:   n.weight,
+				CPUTime:  n.cpuTime,
+				CPUShare: share(n.cpuTime),
+			})
+		case fairUser:
+			metrics.Users = append(metrics.Users, FairShareUserMetrics{
+				User:      n.name,
+				Group:     groupPath(n.parent),
+				Weight:    n.weight,
+				Processes: n.active,
+				CPUTime:   n.cpuTime,
+				CPUShare:  share(n.cpuTime),
+			})
+		}
+	})
+
+	sort.Slice(metrics.Groups, func(i, j int) bool { return metrics.Groups[i].Path < metrics.Groups[j].Path })
+	sort.Slice(metrics.Users, func(i, j int) bool {
+		if metrics.Users[i].Group != metrics.Users[j].Group {
+			return metrics.Users[i].Group < metrics.Users[j].Group
+		}
+		return metrics.Users[i].User < metrics.Users[j].User
+	})
+	return metrics
+}
+
+// GetTimeSlice returns the quantum a process may run for per turn
+func (q *FairShareQueue) GetTimeSlice(p types.Process) time.Duration {
+	return q.quantum
+}
+
+// RequeueProcess returns a preempted process to the queue
+func (q *FairShareQueue) RequeueProcess(p types.Process) error {
+	return q.Enqueue(p)
+}
+
+// RecordSlice charges the CPU time to the process, its user and every group
+// above it
+func (q *FairShareQueue) RecordSlice(p types.Process, ran time.Duration, outcome types.SliceOutcome) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	leaf, ok := q.processes[p.GetPID()]
+	if !ok {
+		return
+	}
+
+	for n := leaf; n != nil; n = n.parent {
+		n.cpuTime += ran
+		if n != q.root {
+			n.vtime += float64(ran) * scaleWeight(n) / float64(n.weight)
+		}
+	}
+
+	switch outcome {
+	case types.SliceCompleted, types.SliceFailed, types.SliceMigrated:
+		q.adjust(leaf, 0, -1)
+		delete(leaf.parent.children, leaf.key)
+		delete(q.processes, p.GetPID())
+	case types.SliceBlocked, types.SliceJobCompleted:
+		q.adjust(leaf, 0, -1)
+	}
+}
+
+// leaf creates the process node below its user and group, creating those as
+// needed
+func (q *FairShareQueue) leaf(p types.Process) *fairNode {
+	owner := p.GetOwner()
+	user := owner.User
+	if user == "" {
+		user = types.DefaultUser
+	}
+
+	group := q.root
+	path := ""
+	for _, name := range strings.Split(strings.Trim(cleanGroupPath(owner.Group), "/"), "/") {
+		if name == "" {
+			continue
+		}
+		path += "/" + name
+		group = q.child(group, fairGroup, name, q.weightOf(q.groupWeights, path))
+	}
+
+	userNode := q.child(group, fairUser, user, q.weightOf(q.userWeights, user))
+
+	leaf := q.child(userNode, fairProcess, strconv.Itoa(p.GetPID()), NiceWeight(p.GetNice()))
+	leaf.process = p
+	q.processes[p.GetPID()] = leaf
+	return leaf
+}
+
+// child returns the child of the given kind and name, creating it if needed.
+// A group and a user may share a name, so children are keyed by kind too
+func (q *FairShareQueue) child(parent *fairNode, kind fairNodeKind, name string, weight int) *fairNode {
+	key := childKey(kind, name)
+	if n, ok := parent.children[key]; ok {
+		return n
+	}
+
+	n := &fairNode{
+		kind:     kind,
+		name:     name,
+		key:      key,
+		parent:   parent,
+		children: make(map[string]*fairNode),
+		weight:   weight,
+	}
+	parent.children[key] = n
+	return n
+}
+
+func (q *FairShareQueue) weightOf(weights map[string]int, key string) int {
+	if weight, ok := weights[key]; ok {
+		return weight
+	}
+	return DefaultShareWeight
+}
+
+func (q *FairShareQueue) findGroup(path string) *fairNode {
+	group := q.root
+	for _, name := range strings.Split(strings.Trim(path, "/"), "/") {
+		next, ok := group.children[childKey(fairGroup, name)]
+		if !ok {
+			return nil
+		}
+		group = next
+	}
+	return group
+}
+
+// adjust changes the queued and active counts of the node and everything
+// above it
+func (q *FairShareQueue) adjust(leaf *fairNode, queued, active int) {
+	for n := leaf; n != nil; n = n.parent {
+		n.queued += queued
+		n.active += active
+	}
+}
+
+// pick descends from the root to the sibling with queued processes and the
+// lowest vtime at every level, breaking ties by activation order
+func (q *FairShareQueue) pick() *fairNode {
+	if q.root.queued == 0 {
+		return nil
+	}
+
+	n := q.root
+	for n.kind != fairProcess {
+		var best *fairNode
+		for _, child := range n.children {
+			if child.queued == 0 {
+				continue
+			}
+			if best == nil || child.vtime < best.vtime || (child.vtime == best.vtime && child.seq < best.seq) {
+				best = child
+			}
+		}
+		n = best
+	}
+	return n
+}
+
+// pickMatching is pick restricted to the processes match accepts, trying the
+// children of every node in pick order until one has such a process below it
+func (q *FairShareQueue) pickMatching(n *fairNode, match func(types.Process) bool) *fairNode {
+	if n.queued == 0 {
+		return nil
+	}
+	if n.kind == fairProcess {
+		if match(n.process) {
+			return n
+		}
+		return nil
+	}
+
+	children := make([]*fairNode, 0, len(n.children))
+	for _, child := range n.children {
+		children = append(children, child)
+	}
+	sort.Slice(children, func(i, j int) bool {
+		if children[i].vtime != children[j].vtime {
+			return children[i].vtime < children[j].vtime
+		}
+		return children[i].seq < children[j].seq
+	})
+
+	for _, child := range children {
+		if leaf := q.pickMatching(child, match); leaf != nil {
+			return leaf
+		}
+	}
+	return nil
+}
+
+func (q *FairShareQueue) walk(n *fairNode, fn func(n *fairNode)) {
+	fn(n)
+	for _, child := range n.children {
+		q.walk(child, fn)
+	}
+}
+
+// minActiveVTime returns the lowest vtime among the active children of parent
+// other than skip
+func minActiveVTime(parent, skip *fairNode) float64 {
+	min := math.MaxFloat64
+	for _, child := range parent.children {
+		if child != skip && child.active > 0 && child.vtime < min {
+			min = child.vtime
+		}
+	}
+	return min
+}
+
+// scaleWeight is the weight a node is charged relative to, so processes use
+// the nice scale and groups and users the cpu.weight scale
+func scaleWeight(n *fairNode) float64 {
+	if n.kind == fairProcess {
+		return nice0Weight
+	}
+	return DefaultShareWeight
+}
+
+func childKey(kind fairNodeKind, name string) string {
+	return fmt.Sprintf("%d:%s", kind, name)
+}
+
+func groupPath(n *fairNode) string {
+	if n.parent == nil {
+		return types.RootGroup
+	}
+
+	parent := groupPath(n.parent)
+	if parent == types.RootGroup {
+		return types.RootGroup + n.name
+	}
+	return parent + "/" + n.name
+}
+
+func cleanGroupPath(path string) string {
+	trimmed := strings.Trim(path, "/")
+	if trimmed == "" {
+		return types.RootGroup
+	}
+	return "/" + trimmed
+}
+
+func validateShareWeight(weight int) error {
+	if weight < 1 || weight > MaxShareWeight {
+		return fmt.Errorf("weight must be between 1 and %d, got %d", MaxShareWeight, weight)
+	}
+	return nil
+}
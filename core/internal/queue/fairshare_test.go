@@ -0,0 +1,186 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/process"
+	"cpu-scheduling/core/internal/types"
+	"testing"
+	"time"
+)
+
+func newOwnedPCB(pid int, user, group string) *process.PCB {
+	p := process.NewPCB(pid, process.NewTask(func() (any, error) { return nil, nil }))
+	p.SetOwner(user, group)
+	return p
+}
+
+// userShares returns the CPU share of every user in the metrics
+func userShares(metrics FairShareMetrics) map[string]float64 {
+	shares := map[string]float64{}
+	for _, u := range metrics.Users {
+		shares[u.User] += u.CPUShare
+	}
+	return shares
+}
+
+func TestFairShareQueue_Enqueue(t *testing.T) {
+	t.Run("should return error for nil process", func(t *testing.T) {
+		if err := NewFairShareQueue(FairShareConfig{}).Enqueue(nil); err == nil {
+			t.Error("expected error for nil process")
+		}
+	})
+
+	t.Run("should return error for a process that is already queued", func(t *testing.T) {
+		queue := NewFairShareQueue(FairShareConfig{})
+		p := newOwnedPCB(1, "alice", "/")
+		queue.Enqueue(p)
+
+		if err := queue.Enqueue(p); err == nil {
+			t.Error("expected error for double enqueue")
+		}
+		if queue.Size() != 1 {
+			t.Errorf("expected size 1, got %d", queue.Size())
+		}
+	})
+}
+
+func TestFairShareQueue_Dequeue(t *testing.T) {
+	t.Run("should not let a user with many processes starve another", func(t *testing.T) {
+		queue := NewFairShareQueue(FairShareConfig{})
+		for pid := 1; pid <= 100; pid++ {
+			queue.Enqueue(newOwnedPCB(pid, "alice", "/"))
+		}
+		queue.Enqueue(newOwnedPCB(101, "bob", "/"))
+
+		wins := runShares(t, queue, 200)
+		if wins[101] != 100 {
+			t.Errorf("expected bob to get half of the quanta, got %d of 200", wins[101])
+		}
+
+		shares := userShares(queue.GetFairShareMetrics())
+		if shares["alice"] != 0.5 || shares["bob"] != 0.5 {
+			t.Errorf("expected an even split between users, got %v", shares)
+		}
+	})
+
+	t.Run("should split the CPU by user weight", func(t *testing.T) {
+		queue := NewFairShareQueue(FairShareConfig{})
+		if err := queue.SetUserWeight("alice", 300); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		queue.Enqueue(newOwnedPCB(1, "alice", "/"))
+		queue.Enqueue(newOwnedPCB(2, "bob", "/"))
+		queue.Enqueue(newOwnedPCB(3, "bob", "/"))
+
+		wins := runShares(t, queue, 400)
+		if wins[1] != 300 || wins[2]+wins[3] != 100 {
+			t.Errorf("expected a 3:1 split between users, got %v", wins)
+		}
+	})
+
+	t.Run("should split the CPU between groups before users", func(t *testing.T) {
+		queue := NewFairShareQueue(FairShareConfig{})
+		queue.Enqueue(newOwnedPCB(1, "alice", "/batch"))
+		queue.Enqueue(newOwnedPCB(2, "bob", "/batch"))
+		queue.Enqueue(newOwnedPCB(3, "carol", "/interactive/web"))
+
+		runShares(t, queue, 400)
+
+		metrics := queue.GetFairShareMetrics()
+		shares := userShares(metrics)
+		if shares["alice"] != 0.25 || shares["bob"] != 0.25 || shares["carol"] != 0.5 {
+			t.Errorf("expected the groups to split evenly first, got %v", shares)
+		}
+
+		groups := map[string]float64{}
+		for _, g := range metrics.Groups {
+			groups[g.Path] = g.CPUShare
+		}
+		if groups["/"] != 1 || groups["/batch"] != 0.5 || groups["/interactive"] != 0.5 || groups["/interactive/web"] != 0.5 {
+			t.Errorf("unexpected group shares %v", groups)
+		}
+	})
+
+	t.Run("should split the CPU by group weight", func(t *testing.T) {
+		queue := NewFairShareQueue(FairShareConfig{})
+		queue.Enqueue(newOwnedPCB(1, "alice", "/batch"))
+		queue.Enqueue(newOwnedPCB(2, "bob", "/interactive"))
+		if err := queue.SetGroupWeight("/interactive", 400); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		wins := runShares(t, queue, 500)
+		if wins[1] != 100 || wins[2] != 400 {
+			t.Errorf("expected a 1:4 split between groups, got %v", wins)
+		}
+	})
+
+	t.Run("should split a user's share by nice weight", func(t *testing.T) {
+		queue := NewFairShareQueue(FairShareConfig{})
+		heavy := newOwnedPCB(1, "alice", "/")
+		heavy.SetNice(-5)
+		queue.Enqueue(heavy)
+		queue.Enqueue(newOwnedPCB(2, "alice", "/"))
+
+		wins := runShares(t, queue, 400)
+		ratio := float64(wins[1]) / float64(wins[2])
+		expected := float64(NiceWeight(-5)) / float64(NiceWeight(0))
+		if ratio < expected*0.9 || ratio > expected*1.1 {
+			t.Errorf("expected CPU ratio near %.2f, got %.2f", expected, ratio)
+		}
+	})
+
+	t.Run("should not let a returning user claim CPU time it did not use", func(t *testing.T) {
+		queue := NewFairShareQueue(FairShareConfig{})
+		queue.Enqueue(newOwnedPCB(1, "alice", "/"))
+		runShares(t, queue, 50)
+
+		queue.Enqueue(newOwnedPCB(2, "bob", "/"))
+		wins := runShares(t, queue, 20)
+		if wins[1] < 9 {
+			t.Errorf("expected alice to keep running alongside bob, got %v", wins)
+		}
+	})
+
+	t.Run("should return error when empty", func(t *testing.T) {
+		if _, err := NewFairShareQueue(FairShareConfig{}).Dequeue(); err == nil {
+			t.Error("expected error when dequeuing from empty queue")
+		}
+	})
+}
+
+func TestFairShareQueue_RecordSlice(t *testing.T) {
+	t.Run("should drop completed processes from the user count", func(t *testing.T) {
+		queue := NewFairShareQueue(FairShareConfig{})
+		p := newOwnedPCB(1, "alice", "/")
+		queue.Enqueue(p)
+		queue.Dequeue()
+		queue.RecordSlice(p, time.Millisecond, types.SliceCompleted)
+
+		metrics := queue.GetFairShareMetrics()
+		if len(metrics.Users) != 1 {
+			t.Fatalf("expected alice to still be reported, got %+v", metrics.Users)
+		}
+		if u := metrics.Users[0]; u.Processes != 0 || u.CPUTime != time.Millisecond {
+			t.Errorf("expected no processes and 1ms of CPU time, got %+v", u)
+		}
+		if !queue.IsEmpty() {
+			t.Error("expected queue to be empty")
+		}
+	})
+}
+
+func TestFairShareQueue_SetWeight(t *testing.T) {
+	t.Run("should reject weights out of range", func(t *testing.T) {
+		queue := NewFairShareQueue(FairShareConfig{})
+
+		if err := queue.SetUserWeight("alice", 0); err == nil {
+			t.Error("expected error for zero weight")
+		}
+		if err := queue.SetGroupWeight("/batch", MaxShareWeight+1); err == nil {
+			t.Error("expected error for weight above the maximum")
+		}
+		if err := queue.SetGroupWeight("/", 200); err == nil {
+			t.Error("expected error for weighting the root group")
+		}
+	})
+}
@@ -16,12 +16,14 @@ type FCFSQueue struct {
 	totalTurnaround time.Duration
 	processedCount  int
 	startTime       time.Time
+	clock           types.Clock
 }
 
 func NewFCFSQueue() *FCFSQueue {
 	return &FCFSQueue{
 		processes: make([]types.Process, 0),
 		startTime: time.Now(),
+		clock:     types.RealClock{},
 	}
 }
 
@@ -95,7 +97,7 @@ func (q *FCFSQueue) GetMetrics() types.SchedulingMetrics {
 	avgTurnaround := q.totalTurnaround / time.Duration(q.processedCount)
 
 	// Calculate throughput (processes per minute)
-	elapsedMinutes := time.Since(q.startTime).Minutes()
+	elapsedMinutes := q.clock.Since(q.startTime).Minutes()
 	var throughput float64
 	if elapsedMinutes > 0 {
 		throughput = float64(q.processedCount) / elapsedMinutes
@@ -107,3 +109,48 @@ func (q *FCFSQueue) GetMetrics() types.SchedulingMetrics {
 		ThroughputPerMin:  throughput,
 	}
 }
+
+func (q *FCFSQueue) SetClock(clock types.Clock) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.clock = clock
+	q.startTime = clock.Now()
+	q.totalWaitTime = 0
+	q.totalTurnaround = 0
+	q.processedCount = 0
+}
+
+// DequeueMatching dequeues the longest waiting process that match accepts
+func (q *FCFSQueue) DequeueMatching(match func(types.Process) bool) (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, p := range q.processes {
+		if !match(p) {
+			continue
+		}
+		q.processes = append(q.processes[:i], q.processes[i+1:]...)
+
+		q.processedCount++
+		q.totalWaitTime += p.GetTimeInState()
+		q.totalTurnaround += p.GetTotalTime()
+		return p, nil
+	}
+
+	return nil, fmt.Errorf("no queued process matches")
+}
+
+// Remove takes the process off the queue without counting it as dispatched
+func (q *FCFSQueue) Remove(p types.Process) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := indexOf(q.processes, p, func(p types.Process) types.Process { return p })
+	if i < 0 {
+		return fmt.Errorf("process %d is not queued", p.GetPID())
+	}
+
+	q.processes = append(q.processes[:i], q.processes[i+1:]...)
+	return nil
+}
@@ -2,6 +2,7 @@ package queue
 
 import (
 	"cpu-scheduling/core/internal/process"
+	"cpu-scheduling/core/internal/simulation"
 	"testing"
 	"time"
 )
@@ -158,6 +159,25 @@ func TestFCFSQueue_GetMetrics(t *testing.T) {
 	})
 }
 
+func TestFCFSQueue_SetClock(t *testing.T) {
+	t.Run("should compute metrics in the time of the clock", func(t *testing.T) {
+		clock := simulation.NewClock(simulation.Epoch)
+		queue := NewFCFSQueue()
+		queue.SetClock(clock)
+
+		p := process.NewPCBWithClock(1, process.NewTask(func() (any, error) { return nil, nil }), clock)
+		queue.Enqueue(p)
+		clock.Schedule(simulation.Epoch.Add(time.Minute), simulation.Arrival, 0)
+		clock.Next()
+		queue.Dequeue()
+
+		metrics := queue.GetMetrics()
+		if metrics.AverageWaitTime != time.Minute || metrics.ThroughputPerMin != 1 {
+			t.Errorf("expected a minute of wait and one process per minute, got %+v", metrics)
+		}
+	})
+}
+
 func TestFCFSQueue_Concurrency(t *testing.T) {
 	t.Run("should handle concurrent operations safely", func(t *testing.T) {
 		queue := NewFCFSQueue()
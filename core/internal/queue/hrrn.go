@@ -0,0 +1,172 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/types"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// HRRNQueue is a non-preemptive Highest Response Ratio Next queue. At every
+// Dequeue it picks the process with the highest response ratio
+// (wait + burst) / burst, where burst is the predicted next CPU burst and wait
+// the total time the process has spent ready. Short processes are favoured
+// like in SJF, but the ratio of a long process grows while it waits, so it
+// cannot starve
+type HRRNQueue struct {
+	processes []*burstEntry
+	predictor BurstPredictor
+	nextSeq   uint64
+	mu        sync.RWMutex
+
+	metrics metricsTracker
+}
+
+func NewHRRNQueue(predictor BurstPredictor) *HRRNQueue {
+	return &HRRNQueue{
+		processes: make([]*burstEntry, 0),
+		predictor: NewBurstPredictor(predictor.Alpha, predictor.InitialEstimate),
+		metrics:   newMetricsTracker(),
+	}
+}
+
+func (q *HRRNQueue) Enqueue(p types.Process) error {
+	if p == nil {
+		return fmt.Errorf("cannot enqueue nil process")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.processes = append(q.processes, &burstEntry{
+		process: p,
+		key:     q.predictor.Estimate(p),
+		seq:     q.nextSeq,
+	})
+	q.nextSeq++
+	return nil
+}
+
+func (q *HRRNQueue) Dequeue() (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.processes) == 0 {
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	i := q.highest()
+	entry := q.processes[i]
+	q.processes = append(q.processes[:i], q.processes[i+1:]...)
+
+	q.metrics.record(entry.process)
+	return entry.process, nil
+}
+
+func (q *HRRNQueue) Peek() (types.Process, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if len(q.processes) == 0 {
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	return q.processes[q.highest()].process, nil
+}
+
+func (q *HRRNQueue) IsEmpty() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return len(q.processes) == 0
+}
+
+func (q *HRRNQueue) Size() int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return len(q.processes)
+}
+
+func (q *HRRNQueue) GetMetrics() types.SchedulingMetrics {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.metrics.snapshot()
+}
+
+func (q *HRRNQueue) SetClock(clock types.Clock) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.metrics.setClock(clock)
+}
+
+// DequeueMatching dequeues the process with the highest response ratio that
+// match accepts
+func (q *HRRNQueue) DequeueMatching(match func(types.Process) bool) (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	best, bestRatio := -1, 0.0
+	for i, entry := range q.processes {
+		if !match(entry.process) {
+			continue
+		}
+		if ratio := responseRatio(entry.process.GetWaitTime(), entry.key); best < 0 || ratio > bestRatio {
+			best, bestRatio = i, ratio
+		}
+	}
+	if best < 0 {
+		return nil, fmt.Errorf("no queued process matches")
+	}
+
+	entry := q.processes[best]
+	q.processes = append(q.processes[:best], q.processes[best+1:]...)
+
+	q.metrics.record(entry.process)
+	return entry.process, nil
+}
+
+// Remove takes the process off the queue without counting it as dispatched
+func (q *HRRNQueue) Remove(p types.Process) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := indexOf(q.processes, p, func(e *burstEntry) types.Process { return e.process })
+	if i < 0 {
+		return fmt.Errorf("process %d is not queued", p.GetPID())
+	}
+
+	q.processes = append(q.processes[:i], q.processes[i+1:]...)
+	return nil
+}
+
+// HRRN specific methods
+
+// GetResponseRatio returns the current response ratio of the process
+func (q *HRRNQueue) GetResponseRatio(p types.Process) float64 {
+	return responseRatio(p.GetWaitTime(), q.predictor.Estimate(p))
+}
+
+// highest returns the index of the process with the highest response ratio,
+// breaking ties in arrival order. The queue must not be empty
+func (q *HRRNQueue) highest() int {
+	best := 0
+	bestRatio := responseRatio(q.processes[0].process.GetWaitTime(), q.processes[0].key)
+
+	for i, entry := range q.processes[1:] {
+		ratio := responseRatio(entry.process.GetWaitTime(), entry.key)
+		if ratio > bestRatio {
+			best, bestRatio = i+1, ratio
+		}
+	}
+	return best
+}
+
+func responseRatio(wait, burst time.Duration) float64 {
+	if burst <= 0 {
+		burst = 1
+	}
+	return float64(wait+burst) / float64(burst)
+}
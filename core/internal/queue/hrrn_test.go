@@ -0,0 +1,89 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/types"
+	"testing"
+	"time"
+)
+
+func TestHRRNQueue_Enqueue(t *testing.T) {
+	t.Run("should return error for nil process", func(t *testing.T) {
+		if err := NewHRRNQueue(NewBurstPredictor(0, 0)).Enqueue(nil); err == nil {
+			t.Error("expected error for nil process")
+		}
+	})
+}
+
+func TestHRRNQueue_Dequeue(t *testing.T) {
+	t.Run("should favour short processes when waits are equal", func(t *testing.T) {
+		queue := NewHRRNQueue(NewBurstPredictor(0, 0))
+		long := newBurstPCB(1, 100*time.Millisecond)
+		short := newBurstPCB(2, 10*time.Millisecond)
+		for _, p := range []types.Process{long, short} {
+			p.SetState(types.READY)
+			queue.Enqueue(p)
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		p, _ := queue.Dequeue()
+		if p.GetPID() != short.GetPID() {
+			t.Errorf("expected short process first, got %d", p.GetPID())
+		}
+	})
+
+	t.Run("should favour a long process that has waited long enough", func(t *testing.T) {
+		queue := NewHRRNQueue(NewBurstPredictor(0, 0))
+		long := newBurstPCB(1, 20*time.Millisecond)
+		long.SetState(types.READY)
+		queue.Enqueue(long)
+		time.Sleep(60 * time.Millisecond)
+
+		short := newBurstPCB(2, 10*time.Millisecond)
+		short.SetState(types.READY)
+		queue.Enqueue(short)
+
+		p, _ := queue.Dequeue()
+		if p.GetPID() != long.GetPID() {
+			t.Errorf("expected long waiting process first, ratios %.2f and %.2f",
+				queue.GetResponseRatio(long), queue.GetResponseRatio(short))
+		}
+	})
+
+	t.Run("should count waits from earlier bursts", func(t *testing.T) {
+		queue := NewHRRNQueue(NewBurstPredictor(0, 0))
+		veteran := newBurstPCB(1, 10*time.Millisecond)
+		veteran.SetState(types.READY)
+		time.Sleep(40 * time.Millisecond)
+		runBurst(veteran, time.Millisecond)
+		veteran.SetState(types.READY)
+
+		if ratio := queue.GetResponseRatio(veteran); ratio < 5 {
+			t.Errorf("expected earlier wait to count towards the ratio, got %.2f", ratio)
+		}
+	})
+
+	t.Run("should return error when empty", func(t *testing.T) {
+		if _, err := NewHRRNQueue(NewBurstPredictor(0, 0)).Dequeue(); err == nil {
+			t.Error("expected error when dequeuing from empty queue")
+		}
+	})
+}
+
+func TestHRRNQueue_Peek(t *testing.T) {
+	t.Run("should return the next process without removing it", func(t *testing.T) {
+		queue := NewHRRNQueue(NewBurstPredictor(0, 0))
+		for _, p := range []types.Process{newBurstPCB(1, 50*time.Millisecond), newBurstPCB(2, 5*time.Millisecond)} {
+			p.SetState(types.READY)
+			queue.Enqueue(p)
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		p, err := queue.Peek()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if p.GetPID() != 2 || queue.Size() != 2 {
+			t.Errorf("expected PID 2 with the queue untouched, got %d and size %d", p.GetPID(), queue.Size())
+		}
+	})
+}
@@ -0,0 +1,271 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/types"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// LotteryConfig configures a lottery queue. Every process runs for at most
+// Quantum per win, and the draws are taken from a random source seeded with
+// Seed so runs can be reproduced
+type LotteryConfig struct {
+	Quantum time.Duration
+	Seed    int64
+}
+
+// LotteryQueue is a proportional share queue after Waldspurger and Weihl.
+// Every process holds tickets, and each time the CPU is free a lottery is
+// drawn among the ready processes, so a process wins in proportion to the
+// value of its tickets. Tickets can be issued in currencies funded with base
+// tickets, transferred between processes, and a process that blocks before
+// its quantum ends gets compensation tickets until it runs again
+type LotteryQueue struct {
+	ready  []*ticketHolder
+	ledger ticketLedger
+	rng    *rand.Rand
+	mu     sync.RWMutex
+
+	// drawn is the winner of a lottery drawn by Peek, which the next Dequeue
+	// hands out
+	drawn *ticketHolder
+
+	metrics metricsTracker
+}
+
+func NewLotteryQueue(config LotteryConfig) *LotteryQueue {
+	return &LotteryQueue{
+		ready:   make([]*ticketHolder, 0),
+		ledger:  newTicketLedger(config.Quantum),
+		rng:     rand.New(rand.NewSource(config.Seed)),
+		metrics: newMetricsTracker(),
+	}
+}
+
+func (q *LotteryQueue) Enqueue(p types.Process) error {
+	if p == nil {
+		return fmt.Errorf("cannot enqueue nil process")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	h, err := q.ledger.holder(p)
+	if err != nil {
+		return err
+	}
+	if h.queued {
+		return fmt.Errorf("process %d is already queued", p.GetPID())
+	}
+
+	h.active = true
+	h.queued = true
+	q.ready = append(q.ready, h)
+	q.drawn = nil
+	return nil
+}
+
+func (q *LotteryQueue) Dequeue() (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	winner := q.draw()
+	if winner == nil {
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	q.take(winner)
+	q.metrics.record(winner.process)
+	return winner.process, nil
+}
+
+// Peek draws the next lottery without removing the winner, which the next
+// Dequeue returns unless the set of ready processes changes in between
+func (q *LotteryQueue) Peek() (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	winner := q.draw()
+	if winner == nil {
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	return winner.process, nil
+}
+
+func (q *LotteryQueue) IsEmpty() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return len(q.ready) == 0
+}
+
+func (q *LotteryQueue) Size() int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return len(q.ready)
+}
+
+func (q *LotteryQueue) GetMetrics() types.SchedulingMetrics {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.metrics.snapshot()
+}
+
+func (q *LotteryQueue) SetClock(clock types.Clock) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.metrics.setClock(clock)
+}
+
+// DequeueMatching draws a lottery among the processes match accepts. The
+// winner of a pending draw by Peek wins again if match accepts it
+func (q *LotteryQueue) DequeueMatching(match func(types.Process) bool) (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	winner := q.drawn
+	if winner == nil || !match(winner.process) {
+		var candidates []*ticketHolder
+		for _, h := range q.ready {
+			if match(h.process) {
+				candidates = append(candidates, h)
+			}
+		}
+		if len(candidates) == 0 {
+			return nil, fmt.Errorf("no queued process matches")
+		}
+		winner = q.lottery(candidates)
+	}
+
+	q.take(winner)
+	q.metrics.record(winner.process)
+	return winner.process, nil
+}
+
+// Remove takes the process off the queue without counting it as dispatched.
+// It stays active until RecordSlice, like a dequeued process
+func (q *LotteryQueue) Remove(p types.Process) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	h, ok := q.ledger.holders[p.GetPID()]
+	if !ok || !h.queued {
+		return fmt.Errorf("process %d is not queued", p.GetPID())
+	}
+
+	q.take(h)
+	return nil
+}
+
+// Lottery specific methods
+
+// AddCurrency creates a currency funded with the given number of base tickets
+func (q *LotteryQueue) AddCurrency(name string, funding int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.drawn = nil
+	return q.ledger.addCurrency(name, funding)
+}
+
+// TransferTickets moves tickets of the currency of from to to. Transferring
+// them back undoes the transfer
+func (q *LotteryQueue) TransferTickets(from, to types.Process, tickets int) error {
+	if from == nil || to == nil {
+		return fmt.Errorf("cannot transfer tickets to or from nil process")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.drawn = nil
+	return q.ledger.transfer(from, to, tickets)
+}
+
+// GetShareMetrics returns the scheduling metrics along with the entitled and
+// actual CPU share of every known process
+func (q *LotteryQueue) GetShareMetrics() ShareMetrics {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.ledger.metrics(q.metrics.snapshot())
+}
+
+// GetTimeSlice returns the quantum a winner may run for
+func (q *LotteryQueue) GetTimeSlice(p types.Process) time.Duration {
+	return q.ledger.quantum
+}
+
+// RequeueProcess returns a preempted process to the ready set
+func (q *LotteryQueue) RequeueProcess(p types.Process) error {
+	return q.Enqueue(p)
+}
+
+// RecordSlice charges the CPU time to the process and grants compensation
+// tickets when it blocked early
+func (q *LotteryQueue) RecordSlice(p types.Process, ran time.Duration, outcome types.SliceOutcome) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if h, ok := q.ledger.holders[p.GetPID()]; ok {
+		q.ledger.recordSlice(h, ran, outcome)
+	}
+}
+
+// draw holds a lottery among the ready processes, or returns the winner of the
+// pending draw
+func (q *LotteryQueue) draw() *ticketHolder {
+	if len(q.ready) == 0 {
+		return nil
+	}
+	if q.drawn != nil {
+		return q.drawn
+	}
+
+	q.drawn = q.lottery(q.ready)
+	return q.drawn
+}
+
+// lottery draws a winner among the candidates, which must not be empty
+func (q *LotteryQueue) lottery(candidates []*ticketHolder) *ticketHolder {
+	rates := q.ledger.rates()
+	total := 0.0
+	for _, h := range candidates {
+		total += q.ledger.weight(h, rates)
+	}
+
+	// Without any tickets in play the oldest process wins
+	winner := candidates[0]
+	if total == 0 {
+		return winner
+	}
+
+	winning := q.rng.Float64() * total
+	for _, h := range candidates {
+		winning -= q.ledger.weight(h, rates)
+		if winning < 0 {
+			winner = h
+			break
+		}
+	}
+	return winner
+}
+
+// take removes the holder from the ready processes, which invalidates any
+// pending draw
+func (q *LotteryQueue) take(h *ticketHolder) {
+	for i, ready := range q.ready {
+		if ready == h {
+			q.ready = append(q.ready[:i], q.ready[i+1:]...)
+			break
+		}
+	}
+	h.queued = false
+	q.drawn = nil
+}
@@ -0,0 +1,197 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/process"
+	"cpu-scheduling/core/internal/types"
+	"math"
+	"testing"
+	"time"
+)
+
+func newTicketPCB(pid, tickets int, currency string) *process.PCB {
+	p := process.NewPCB(pid, process.NewTask(func() (any, error) { return nil, nil }))
+	p.SetTickets(tickets)
+	p.SetCurrency(currency)
+	return p
+}
+
+// runShares runs full quanta on a proportional share queue and returns the
+// number of quanta every process received
+func runShares(t *testing.T, q types.TimeSlicedQueue, rounds int) map[int]int {
+	t.Helper()
+
+	wins := map[int]int{}
+	for i := 0; i < rounds; i++ {
+		p, err := q.Dequeue()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		wins[p.GetPID()]++
+		q.(types.FeedbackQueue).RecordSlice(p, q.GetTimeSlice(p), types.SliceExpired)
+		q.RequeueProcess(p)
+	}
+	return wins
+}
+
+func TestLotteryQueue_Enqueue(t *testing.T) {
+	t.Run("should return error for nil process", func(t *testing.T) {
+		if err := NewLotteryQueue(LotteryConfig{}).Enqueue(nil); err == nil {
+			t.Error("expected error for nil process")
+		}
+	})
+
+	t.Run("should return error for an unknown currency", func(t *testing.T) {
+		queue := NewLotteryQueue(LotteryConfig{})
+
+		if err := queue.Enqueue(newTicketPCB(1, 10, "alice")); err == nil {
+			t.Error("expected error for unknown currency")
+		}
+	})
+}
+
+func TestLotteryQueue_Dequeue(t *testing.T) {
+	t.Run("should draw the same winners for the same seed", func(t *testing.T) {
+		draws := func() []int {
+			queue := NewLotteryQueue(LotteryConfig{Seed: 42})
+			for pid := 1; pid <= 3; pid++ {
+				queue.Enqueue(newTicketPCB(pid, 100, BaseCurrency))
+			}
+
+			var winners []int
+			for i := 0; i < 20; i++ {
+				p, _ := queue.Dequeue()
+				winners = append(winners, p.GetPID())
+				queue.RequeueProcess(p)
+			}
+			return winners
+		}
+
+		first, second := draws(), draws()
+		for i := range first {
+			if first[i] != second[i] {
+				t.Fatalf("expected identical draws, got %v and %v", first, second)
+			}
+		}
+	})
+
+	t.Run("should return the process drawn by Peek", func(t *testing.T) {
+		queue := NewLotteryQueue(LotteryConfig{Seed: 7})
+		for pid := 1; pid <= 5; pid++ {
+			queue.Enqueue(newTicketPCB(pid, 100, BaseCurrency))
+		}
+
+		peeked, _ := queue.Peek()
+		dequeued, _ := queue.Dequeue()
+		if peeked.GetPID() != dequeued.GetPID() {
+			t.Errorf("expected Dequeue to return peeked PID %d, got %d", peeked.GetPID(), dequeued.GetPID())
+		}
+	})
+
+	t.Run("should win in proportion to tickets", func(t *testing.T) {
+		queue := NewLotteryQueue(LotteryConfig{Seed: 1})
+		queue.Enqueue(newTicketPCB(1, 300, BaseCurrency))
+		queue.Enqueue(newTicketPCB(2, 100, BaseCurrency))
+
+		wins := runShares(t, queue, 4000)
+
+		if share := float64(wins[1]) / 4000; math.Abs(share-0.75) > 0.03 {
+			t.Errorf("expected a share near 0.75, got %.3f", share)
+		}
+	})
+
+	t.Run("should return error when empty", func(t *testing.T) {
+		if _, err := NewLotteryQueue(LotteryConfig{}).Dequeue(); err == nil {
+			t.Error("expected error when dequeuing from empty queue")
+		}
+	})
+}
+
+func TestLotteryQueue_Currencies(t *testing.T) {
+	t.Run("should split the funding of a currency among its active processes", func(t *testing.T) {
+		queue := NewLotteryQueue(LotteryConfig{})
+		queue.AddCurrency("alice", 100)
+		queue.AddCurrency("bob", 100)
+		queue.Enqueue(newTicketPCB(1, 1, "alice"))
+		queue.Enqueue(newTicketPCB(2, 3, "alice"))
+		queue.Enqueue(newTicketPCB(3, 1, "bob"))
+
+		expected := map[int]float64{1: 0.125, 2: 0.375, 3: 0.5}
+		for _, m := range queue.GetShareMetrics().Processes {
+			if math.Abs(m.EntitledShare-expected[m.PID]) > 1e-9 {
+				t.Errorf("expected PID %d to be entitled to %.3f, got %.3f", m.PID, expected[m.PID], m.EntitledShare)
+			}
+		}
+	})
+
+	t.Run("should reject duplicate and unfunded currencies", func(t *testing.T) {
+		queue := NewLotteryQueue(LotteryConfig{})
+		queue.AddCurrency("alice", 100)
+
+		if err := queue.AddCurrency("alice", 100); err == nil {
+			t.Error("expected error for duplicate currency")
+		}
+		if err := queue.AddCurrency("bob", 0); err == nil {
+			t.Error("expected error for unfunded currency")
+		}
+		if err := queue.AddCurrency(BaseCurrency, 100); err == nil {
+			t.Error("expected error for redefining the base currency")
+		}
+	})
+}
+
+func TestLotteryQueue_TransferTickets(t *testing.T) {
+	t.Run("should move tickets between processes", func(t *testing.T) {
+		queue := NewLotteryQueue(LotteryConfig{})
+		client := newTicketPCB(1, 100, BaseCurrency)
+		server := newTicketPCB(2, 100, BaseCurrency)
+		queue.Enqueue(client)
+		queue.Enqueue(server)
+
+		if err := queue.TransferTickets(client, server, 100); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		metrics := queue.GetShareMetrics()
+		if metrics.Processes[0].Tickets != 0 || metrics.Processes[1].Tickets != 200 {
+			t.Errorf("expected all tickets with the server, got %+v", metrics.Processes)
+		}
+	})
+
+	t.Run("should return error when transferring more than held", func(t *testing.T) {
+		queue := NewLotteryQueue(LotteryConfig{})
+		a := newTicketPCB(1, 10, BaseCurrency)
+		b := newTicketPCB(2, 10, BaseCurrency)
+
+		if err := queue.TransferTickets(a, b, 11); err == nil {
+			t.Error("expected error for overdrawn transfer")
+		}
+	})
+}
+
+func TestLotteryQueue_RecordSlice(t *testing.T) {
+	t.Run("should compensate processes that block early", func(t *testing.T) {
+		queue := NewLotteryQueue(LotteryConfig{Quantum: 10 * time.Millisecond, Seed: 3})
+		io := newTicketPCB(1, 100, BaseCurrency)
+		cpu := newTicketPCB(2, 100, BaseCurrency)
+		queue.Enqueue(io)
+		queue.Enqueue(cpu)
+
+		// The I/O bound process uses a fifth of its quantum, but should still
+		// get about as many quanta as its ticket share over time by winning
+		// five times as often
+		for i := 0; i < 4000; i++ {
+			p, _ := queue.Dequeue()
+			if p.GetPID() == io.GetPID() {
+				queue.RecordSlice(p, 2*time.Millisecond, types.SliceBlocked)
+			} else {
+				queue.RecordSlice(p, 10*time.Millisecond, types.SliceExpired)
+			}
+			queue.RequeueProcess(p)
+		}
+
+		metrics := queue.GetShareMetrics()
+		if share := metrics.Processes[0].ActualShare; math.Abs(share-0.5) > 0.05 {
+			t.Errorf("expected compensated CPU share near 0.5, got %.3f", share)
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/types"
+	"time"
+)
+
+// metricsTracker accumulates the wait and turnaround times of dequeued
+// processes. It is not safe for concurrent use and relies on the lock of the
+// queue that embeds it
+type metricsTracker struct {
+	totalWaitTime   time.Duration
+	totalTurnaround time.Duration
+	processedCount  int
+	startTime       time.Time
+	clock           types.Clock
+}
+
+func newMetricsTracker() metricsTracker {
+	clock := types.RealClock{}
+	return metricsTracker{startTime: clock.Now(), clock: clock}
+}
+
+// setClock restarts the tracker on the given clock
+func (m *metricsTracker) setClock(clock types.Clock) {
+	*m = metricsTracker{startTime: clock.Now(), clock: clock}
+}
+
+// record accounts for a process leaving the queue to run
+func (m *metricsTracker) record(p types.Process) {
+	m.processedCount++
+	m.totalWaitTime += p.GetTimeInState()
+	m.totalTurnaround += p.GetTotalTime()
+}
+
+func (m *metricsTracker) snapshot() types.SchedulingMetrics {
+	if m.processedCount == 0 {
+		return types.SchedulingMetrics{}
+	}
+
+	// Calculate averages
+	avgWait := m.totalWaitTime / time.Duration(m.processedCount)
+	avgTurnaround := m.totalTurnaround / time.Duration(m.processedCount)
+
+	// Calculate throughput (processes per minute)
+	elapsedMinutes := m.clock.Since(m.startTime).Minutes()
+	var throughput float64
+	if elapsedMinutes > 0 {
+		throughput = float64(m.processedCount) / elapsedMinutes
+	}
+
+	return types.SchedulingMetrics{
+		AverageWaitTime:   avgWait,
+		AverageTurnaround: avgTurnaround,
+		ThroughputPerMin:  throughput,
+	}
+}
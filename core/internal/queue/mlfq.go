@@ -0,0 +1,423 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/types"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MLFQPolicy selects how processes within a single MLFQ level are scheduled
+type MLFQPolicy int
+
+const (
+	// MLFQRoundRobin time slices the processes of a level with its quantum
+	MLFQRoundRobin MLFQPolicy = iota
+	// MLFQFirstCome runs the processes of a level to completion in arrival order
+	MLFQFirstCome
+)
+
+// UnmarshalText parses a policy from "rr" or "fcfs"
+func (p *MLFQPolicy) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "rr":
+		*p = MLFQRoundRobin
+	case "fcfs":
+		*p = MLFQFirstCome
+	default:
+		return fmt.Errorf("unknown MLFQ policy %q", text)
+	}
+	return nil
+}
+
+// MLFQLevel configures one level of a multi-level feedback queue. Allotment is
+// the CPU time a process may use at this level, across any number of slices,
+// before it is demoted. It defaults to the quantum, and every level but the
+// bottom one needs one of the two
+type MLFQLevel struct {
+	Quantum   time.Duration
+	Allotment time.Duration
+	Policy    MLFQPolicy
+}
+
+// MLFQConfig configures a multi-level feedback queue. Levels are listed from
+// the highest priority to the lowest. Every BoostInterval all processes are
+// moved back to the top level; a zero interval disables the boost. With
+// PromoteOnIO a process that blocks before its quantum ends moves up a level
+// instead of staying where it is
+type MLFQConfig struct {
+	Levels        []MLFQLevel
+	BoostInterval time.Duration
+	PromoteOnIO   bool
+}
+
+// DefaultMLFQConfig returns a three level configuration with Round Robin at
+// the top two levels and FCFS at the bottom
+func DefaultMLFQConfig() MLFQConfig {
+	return MLFQConfig{
+		Levels: []MLFQLevel{
+			{Quantum: 10 * time.Millisecond, Policy: MLFQRoundRobin},
+			{Quantum: 20 * time.Millisecond, Policy: MLFQRoundRobin},
+			{Policy: MLFQFirstCome},
+		},
+		BoostInterval: time.Second,
+	}
+}
+
+// MLFQLevelMetrics reports the state of a single MLFQ level
+type MLFQLevelMetrics struct {
+	Quantum    time.Duration
+	Depth      int
+	Demotions  int
+	Promotions int
+}
+
+// MLFQMetrics extends the scheduling metrics with per-level statistics
+type MLFQMetrics struct {
+	types.SchedulingMetrics
+	Levels []MLFQLevelMetrics
+	Boosts int
+}
+
+// mlfqProcess tracks where a process sits in the feedback queue
+type mlfqProcess struct {
+	level int
+	used  time.Duration
+}
+
+type mlfqLevel struct {
+	config     MLFQLevel
+	queue      types.SchedulingQueue
+	demotions  int
+	promotions int
+}
+
+// MLFQQueue is a Multi-Level Feedback Queue. New processes start at the top
+// level. A process that uses up the allotment of its level is demoted, one
+// that blocks for I/O before then keeps its level or is promoted, and a
+// periodic boost moves everything back to the top to prevent starvation
+type MLFQQueue struct {
+	levels      []*mlfqLevel
+	processes   map[int]*mlfqProcess
+	promoteOnIO bool
+	mu          sync.RWMutex
+
+	boostInterval time.Duration
+	lastBoost     time.Time
+	boosts        int
+
+	metrics metricsTracker
+	clock   types.Clock
+}
+
+func NewMLFQQueue(config MLFQConfig) (*MLFQQueue, error) {
+	if len(config.Levels) == 0 {
+		return nil, fmt.Errorf("MLFQ needs at least one level")
+	}
+	if config.BoostInterval < 0 {
+		return nil, fmt.Errorf("boost interval cannot be negative, got %v", config.BoostInterval)
+	}
+
+	levels := make([]*mlfqLevel, len(config.Levels))
+	for i, level := range config.Levels {
+		if level.Quantum < 0 || level.Allotment < 0 {
+			return nil, fmt.Errorf("level %d: quantum and allotment cannot be negative", i)
+		}
+
+		var child types.SchedulingQueue
+		switch level.Policy {
+		case MLFQRoundRobin:
+			if level.Quantum == 0 {
+				return nil, fmt.Errorf("level %d: round robin needs a quantum", i)
+			}
+			child = NewRoundRobinQueue(level.Quantum)
+		case MLFQFirstCome:
+			child = NewFCFSQueue()
+		default:
+			return nil, fmt.Errorf("level %d: unknown policy %d", i, level.Policy)
+		}
+
+		if level.Allotment == 0 {
+			level.Allotment = level.Quantum
+		}
+		// Every slice would use up a zero allotment and demote the process
+		if level.Allotment == 0 && i < len(config.Levels)-1 {
+			return nil, fmt.Errorf("level %d: needs an allotment or quantum above the bottom level", i)
+		}
+
+		levels[i] = &mlfqLevel{config: level, queue: child}
+	}
+
+	return &MLFQQueue{
+		levels:        levels,
+		processes:     make(map[int]*mlfqProcess),
+		promoteOnIO:   config.PromoteOnIO,
+		boostInterval: config.BoostInterval,
+		lastBoost:     time.Now(),
+		metrics:       newMetricsTracker(),
+		clock:         types.RealClock{},
+	}, nil
+}
+
+func (q *MLFQQueue) Enqueue(p types.Process) error {
+	if p == nil {
+		return fmt.Errorf("cannot enqueue nil process")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.boostIfDue(); err != nil {
+		return err
+	}
+
+	return q.levels[q.state(p).level].queue.Enqueue(p)
+}
+
+func (q *MLFQQueue) Dequeue() (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.boostIfDue(); err != nil {
+		return nil, err
+	}
+
+	for _, level := range q.levels {
+		if level.queue.IsEmpty() {
+			continue
+		}
+
+		p, err := level.queue.Dequeue()
+		if err != nil {
+			return nil, err
+		}
+		q.metrics.record(p)
+		return p, nil
+	}
+
+	return nil, fmt.Errorf("queue is empty")
+}
+
+func (q *MLFQQueue) Peek() (types.Process, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	for _, level := range q.levels {
+		if !level.queue.IsEmpty() {
+			return level.queue.Peek()
+		}
+	}
+
+	return nil, fmt.Errorf("queue is empty")
+}
+
+func (q *MLFQQueue) IsEmpty() bool {
+	return q.Size() == 0
+}
+
+func (q *MLFQQueue) Size() int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	size := 0
+	for _, level := range q.levels {
+		size += level.queue.Size()
+	}
+	return size
+}
+
+func (q *MLFQQueue) GetMetrics() types.SchedulingMetrics {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.metrics.snapshot()
+}
+
+func (q *MLFQQueue) SetClock(clock types.Clock) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.metrics.setClock(clock)
+	q.clock = clock
+	q.lastBoost = clock.Now()
+}
+
+// DequeueMatching dequeues the first process match accepts from the highest
+// level that has one
+func (q *MLFQQueue) DequeueMatching(match func(types.Process) bool) (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.boostIfDue(); err != nil {
+		return nil, err
+	}
+
+	for _, level := range q.levels {
+		if level.queue.IsEmpty() {
+			continue
+		}
+
+		p, err := dequeueMatching(level.queue, match)
+		if err != nil {
+			continue
+		}
+		q.metrics.record(p)
+		return p, nil
+	}
+
+	return nil, fmt.Errorf("no queued process matches")
+}
+
+// Remove takes the process off its level without counting it as dispatched
+func (q *MLFQQueue) Remove(p types.Process) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return remove(q.levels[q.levelOf(p)].queue, p)
+}
+
+// MLFQ specific methods
+
+// GetMLFQMetrics returns the scheduling metrics along with the depth, demotion
+// and promotion counts of every level
+func (q *MLFQQueue) GetMLFQMetrics() MLFQMetrics {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	levels := make([]MLFQLevelMetrics, len(q.levels))
+	for i, level := range q.levels {
+		levels[i] = MLFQLevelMetrics{
+			Quantum:    level.config.Quantum,
+			Depth:      level.queue.Size(),
+			Demotions:  level.demotions,
+			Promotions: level.promotions,
+		}
+	}
+
+	return MLFQMetrics{
+		SchedulingMetrics: q.metrics.snapshot(),
+		Levels:            levels,
+		Boosts:            q.boosts,
+	}
+}
+
+// GetLevel returns the level the process is currently assigned to
+func (q *MLFQQueue) GetLevel(p types.Process) int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if state, ok := q.processes[p.GetPID()]; ok {
+		return state.level
+	}
+	return 0
+}
+
+// GetTimeSlice returns the quantum of the level the process is at. FCFS levels
+// have no quantum and are never time sliced
+func (q *MLFQQueue) GetTimeSlice(p types.Process) time.Duration {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	level := q.levels[q.levelOf(p)]
+	if level.config.Policy == MLFQFirstCome {
+		return 0
+	}
+	return level.config.Quantum
+}
+
+// RequeueProcess returns a preempted process to the tail of its current level
+func (q *MLFQQueue) RequeueProcess(p types.Process) error {
+	return q.Enqueue(p)
+}
+
+// ShouldPreempt reports whether the arrived process sits at a higher level
+// than the running one
+func (q *MLFQQueue) ShouldPreempt(running, arrived types.Process) bool {
+	if running == nil || arrived == nil {
+		return false
+	}
+
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.levelOf(arrived) < q.levelOf(running)
+}
+
+// RecordSlice charges the slice against the allotment of the process and moves
+// it between levels accordingly
+func (q *MLFQQueue) RecordSlice(p types.Process, ran time.Duration, outcome types.SliceOutcome) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if outcome == types.SliceCompleted || outcome == types.SliceFailed || outcome == types.SliceMigrated {
+		delete(q.processes, p.GetPID())
+		return
+	}
+
+	state := q.state(p)
+	level := q.levels[state.level]
+	state.used += ran
+
+	bottom := state.level == len(q.levels)-1
+	if !bottom && state.used >= level.config.Allotment {
+		level.demotions++
+		state.level++
+		state.used = 0
+		return
+	}
+
+	if outcome == types.SliceBlocked && q.promoteOnIO && state.level > 0 {
+		level.promotions++
+		state.level--
+		state.used = 0
+	}
+}
+
+// state returns the tracking entry of the process, starting new processes at
+// the top level
+func (q *MLFQQueue) state(p types.Process) *mlfqProcess {
+	state, ok := q.processes[p.GetPID()]
+	if !ok {
+		state = &mlfqProcess{}
+		q.processes[p.GetPID()] = state
+	}
+	return state
+}
+
+func (q *MLFQQueue) levelOf(p types.Process) int {
+	if state, ok := q.processes[p.GetPID()]; ok {
+		return state.level
+	}
+	return 0
+}
+
+// boostIfDue moves every process to the top level once the boost interval has
+// passed since the previous boost
+func (q *MLFQQueue) boostIfDue() error {
+	if q.boostInterval == 0 || q.clock.Since(q.lastBoost) < q.boostInterval {
+		return nil
+	}
+
+	top := q.levels[0].queue
+	for _, level := range q.levels[1:] {
+		for !level.queue.IsEmpty() {
+			p, err := level.queue.Dequeue()
+			if err != nil {
+				return err
+			}
+			if err := top.Enqueue(p); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, state := range q.processes {
+		state.level = 0
+		state.used = 0
+	}
+
+	q.lastBoost = q.clock.Now()
+	q.boosts++
+	return nil
+}
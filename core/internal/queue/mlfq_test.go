@@ -0,0 +1,208 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/process"
+	"cpu-scheduling/core/internal/types"
+	"testing"
+	"time"
+)
+
+func newMLFQPCB(pid int) *process.PCB {
+	return process.NewPCB(pid, process.NewTask(func() (any, error) { return nil, nil }))
+}
+
+func TestNewMLFQQueue(t *testing.T) {
+	t.Run("should create queue from default config", func(t *testing.T) {
+		queue, err := NewMLFQQueue(DefaultMLFQConfig())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !queue.IsEmpty() {
+			t.Error("new queue should be empty")
+		}
+		if len(queue.GetMLFQMetrics().Levels) != 3 {
+			t.Errorf("expected 3 levels, got %d", len(queue.GetMLFQMetrics().Levels))
+		}
+	})
+
+	t.Run("should return error without levels", func(t *testing.T) {
+		if _, err := NewMLFQQueue(MLFQConfig{}); err == nil {
+			t.Error("expected error for empty config")
+		}
+	})
+
+	t.Run("should return error for round robin level without quantum", func(t *testing.T) {
+		config := MLFQConfig{Levels: []MLFQLevel{{Policy: MLFQRoundRobin}}}
+
+		if _, err := NewMLFQQueue(config); err == nil {
+			t.Error("expected error for missing quantum")
+		}
+	})
+
+	t.Run("should return error for an FCFS level without allotment above the bottom", func(t *testing.T) {
+		config := MLFQConfig{Levels: []MLFQLevel{{Policy: MLFQFirstCome}, {Policy: MLFQFirstCome}}}
+		if _, err := NewMLFQQueue(config); err == nil {
+			t.Error("expected error for a zero allotment")
+		}
+
+		config.Levels[0].Allotment = 10 * time.Millisecond
+		if _, err := NewMLFQQueue(config); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestMLFQQueue_Dequeue(t *testing.T) {
+	t.Run("should serve higher levels first", func(t *testing.T) {
+		queue, _ := NewMLFQQueue(DefaultMLFQConfig())
+		demoted := newMLFQPCB(1)
+		fresh := newMLFQPCB(2)
+
+		queue.Enqueue(demoted)
+		p, _ := queue.Dequeue()
+		queue.RecordSlice(p, 10*time.Millisecond, types.SliceExpired)
+		queue.RequeueProcess(p)
+		queue.Enqueue(fresh)
+
+		first, _ := queue.Dequeue()
+		if first.GetPID() != 2 {
+			t.Errorf("expected top level process first, got PID %d", first.GetPID())
+		}
+	})
+
+	t.Run("should return error when empty", func(t *testing.T) {
+		queue, _ := NewMLFQQueue(DefaultMLFQConfig())
+
+		if _, err := queue.Dequeue(); err == nil {
+			t.Error("expected error when dequeuing from empty queue")
+		}
+	})
+}
+
+func TestMLFQQueue_RecordSlice(t *testing.T) {
+	t.Run("should demote a process that uses its full quantum", func(t *testing.T) {
+		queue, _ := NewMLFQQueue(DefaultMLFQConfig())
+		p := newMLFQPCB(1)
+
+		queue.Enqueue(p)
+		queue.Dequeue()
+		queue.RecordSlice(p, 10*time.Millisecond, types.SliceExpired)
+
+		if queue.GetLevel(p) != 1 {
+			t.Errorf("expected level 1, got %d", queue.GetLevel(p))
+		}
+		if queue.GetTimeSlice(p) != 20*time.Millisecond {
+			t.Errorf("expected 20ms slice at level 1, got %v", queue.GetTimeSlice(p))
+		}
+		if queue.GetMLFQMetrics().Levels[0].Demotions != 1 {
+			t.Error("expected one demotion from level 0")
+		}
+	})
+
+	t.Run("should demote once the allotment is used across slices", func(t *testing.T) {
+		config := MLFQConfig{Levels: []MLFQLevel{
+			{Quantum: 10 * time.Millisecond, Allotment: 15 * time.Millisecond},
+			{Policy: MLFQFirstCome},
+		}}
+		queue, _ := NewMLFQQueue(config)
+		p := newMLFQPCB(1)
+
+		queue.RecordSlice(p, 8*time.Millisecond, types.SliceBlocked)
+		if queue.GetLevel(p) != 0 {
+			t.Errorf("expected process to stay at level 0, got %d", queue.GetLevel(p))
+		}
+
+		queue.RecordSlice(p, 8*time.Millisecond, types.SliceBlocked)
+		if queue.GetLevel(p) != 1 {
+			t.Errorf("expected process to be demoted after using its allotment, got level %d", queue.GetLevel(p))
+		}
+	})
+
+	t.Run("should keep the level of a process that blocks early", func(t *testing.T) {
+		queue, _ := NewMLFQQueue(DefaultMLFQConfig())
+		p := newMLFQPCB(1)
+
+		queue.RecordSlice(p, time.Millisecond, types.SliceBlocked)
+
+		if queue.GetLevel(p) != 0 {
+			t.Errorf("expected level 0, got %d", queue.GetLevel(p))
+		}
+	})
+
+	t.Run("should promote a process that blocks early with PromoteOnIO", func(t *testing.T) {
+		config := DefaultMLFQConfig()
+		config.PromoteOnIO = true
+		queue, _ := NewMLFQQueue(config)
+		p := newMLFQPCB(1)
+
+		queue.RecordSlice(p, 10*time.Millisecond, types.SliceExpired)
+		queue.RecordSlice(p, time.Millisecond, types.SliceBlocked)
+
+		if queue.GetLevel(p) != 0 {
+			t.Errorf("expected promotion to level 0, got %d", queue.GetLevel(p))
+		}
+		if queue.GetMLFQMetrics().Levels[1].Promotions != 1 {
+			t.Error("expected one promotion from level 1")
+		}
+	})
+
+	t.Run("should not demote below the bottom level", func(t *testing.T) {
+		queue, _ := NewMLFQQueue(DefaultMLFQConfig())
+		p := newMLFQPCB(1)
+
+		for i := 0; i < 5; i++ {
+			queue.RecordSlice(p, time.Second, types.SliceExpired)
+		}
+
+		if queue.GetLevel(p) != 2 {
+			t.Errorf("expected bottom level 2, got %d", queue.GetLevel(p))
+		}
+		if queue.GetTimeSlice(p) != 0 {
+			t.Errorf("expected no time slice at FCFS level, got %v", queue.GetTimeSlice(p))
+		}
+	})
+}
+
+func TestMLFQQueue_Boost(t *testing.T) {
+	t.Run("should move every process back to the top level", func(t *testing.T) {
+		config := DefaultMLFQConfig()
+		config.BoostInterval = 5 * time.Millisecond
+		queue, _ := NewMLFQQueue(config)
+		p := newMLFQPCB(1)
+
+		queue.Enqueue(p)
+		queue.Dequeue()
+		queue.RecordSlice(p, time.Second, types.SliceExpired)
+		queue.RequeueProcess(p)
+
+		time.Sleep(6 * time.Millisecond)
+		queue.Enqueue(newMLFQPCB(2))
+
+		metrics := queue.GetMLFQMetrics()
+		if metrics.Boosts != 1 {
+			t.Errorf("expected one boost, got %d", metrics.Boosts)
+		}
+		if metrics.Levels[0].Depth != 2 || metrics.Levels[1].Depth != 0 {
+			t.Errorf("expected both processes at level 0, got depths %d and %d",
+				metrics.Levels[0].Depth, metrics.Levels[1].Depth)
+		}
+		if queue.GetLevel(p) != 0 {
+			t.Errorf("expected level 0 after boost, got %d", queue.GetLevel(p))
+		}
+	})
+}
+
+func TestMLFQQueue_ShouldPreempt(t *testing.T) {
+	t.Run("should preempt a lower level process for a higher level arrival", func(t *testing.T) {
+		queue, _ := NewMLFQQueue(DefaultMLFQConfig())
+		running := newMLFQPCB(1)
+		queue.RecordSlice(running, 10*time.Millisecond, types.SliceExpired)
+
+		if !queue.ShouldPreempt(running, newMLFQPCB(2)) {
+			t.Error("expected new process to preempt a demoted one")
+		}
+		if queue.ShouldPreempt(newMLFQPCB(3), newMLFQPCB(4)) {
+			t.Error("expected no preemption between processes at the same level")
+		}
+	})
+}
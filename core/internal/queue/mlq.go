@@ -0,0 +1,460 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/types"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MLQSelection decides which class of a multi-level queue runs next
+type MLQSelection int
+
+const (
+	// MLQStrictPriority always runs the most important non-empty class
+	MLQStrictPriority MLQSelection = iota
+	// MLQTimeSliced splits CPU time between classes according to their shares
+	MLQTimeSliced
+)
+
+// UnmarshalText parses a selection from "strict" or "sliced"
+func (s *MLQSelection) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "strict":
+		*s = MLQStrictPriority
+	case "sliced":
+		*s = MLQTimeSliced
+	default:
+		return fmt.Errorf("unknown MLQ selection %q", text)
+	}
+	return nil
+}
+
+// MLQClass binds a process class to the queue that schedules its processes.
+// Share is the relative amount of CPU time the class receives in time sliced
+// selection and is ignored under strict priority
+type MLQClass struct {
+	Class types.ProcessClass
+	Queue types.SchedulingQueue
+	Share float64
+}
+
+// MLQConfig configures a multi-level queue. Classes are listed from the most
+// to the least important. In time sliced selection, ClassSlice bounds how long
+// a process of a class whose queue is not time sliced itself may run, so the
+// split can be enforced
+type MLQConfig struct {
+	Classes    []MLQClass
+	Selection  MLQSelection
+	ClassSlice time.Duration
+}
+
+// DefaultMLQConfig returns a strict priority configuration with FCFS for
+// system processes, Round Robin for interactive ones and FCFS for batch and
+// background work
+func DefaultMLQConfig() MLQConfig {
+	return MLQConfig{
+		Classes: []MLQClass{
+			{Class: types.SYSTEM, Queue: NewFCFSQueue(), Share: 1},
+			{Class: types.INTERACTIVE, Queue: NewRoundRobinQueue(10 * time.Millisecond), Share: 1},
+			{Class: types.BATCH, Queue: NewFCFSQueue(), Share: 1},
+			{Class: types.BACKGROUND, Queue: NewFCFSQueue(), Share: 1},
+		},
+		Selection: MLQStrictPriority,
+	}
+}
+
+// MLQClassMetrics reports the state of a single class
+type MLQClassMetrics struct {
+	Class    types.ProcessClass
+	Depth    int
+	CPUTime  time.Duration
+	CPUShare float64
+}
+
+// MLQMetrics extends the scheduling metrics with per-class statistics
+type MLQMetrics struct {
+	types.SchedulingMetrics
+	Classes []MLQClassMetrics
+}
+
+type mlqClass struct {
+	config  MLQClass
+	cpuTime time.Duration
+	// vtime is the CPU time of the class divided by its share
+	vtime float64
+	// running counts the processes of the class that were dequeued and have
+	// not left the CPU for good, as those that are preempted come back
+	running int
+}
+
+// active reports whether the class has processes, queued or running
+func (c *mlqClass) active() bool {
+	return c.running > 0 || !c.config.Queue.IsEmpty()
+}
+
+// MLQQueue is a Multi-Level Queue where every process belongs permanently to
+// the class it was created with. Each class has its own child queue, and the
+// class to run next is picked by strict priority or by time sliced shares
+type MLQQueue struct {
+	classes    []*mlqClass
+	byClass    map[types.ProcessClass]*mlqClass
+	selection  MLQSelection
+	classSlice time.Duration
+	mu         sync.RWMutex
+
+	metrics metricsTracker
+}
+
+func NewMLQQueue(config MLQConfig) (*MLQQueue, error) {
+	if len(config.Classes) == 0 {
+		return nil, fmt.Errorf("MLQ needs at least one class")
+	}
+	if config.Selection != MLQStrictPriority && config.Selection != MLQTimeSliced {
+		return nil, fmt.Errorf("unknown class selection %d", config.Selection)
+	}
+	if config.ClassSlice < 0 {
+		return nil, fmt.Errorf("class slice cannot be negative, got %v", config.ClassSlice)
+	}
+
+	q := &MLQQueue{
+		byClass:    make(map[types.ProcessClass]*mlqClass),
+		selection:  config.Selection,
+		classSlice: config.ClassSlice,
+		metrics:    newMetricsTracker(),
+	}
+
+	for _, class := range config.Classes {
+		if class.Queue == nil {
+			return nil, fmt.Errorf("class %d has no queue", class.Class)
+		}
+		if _, exists := q.byClass[class.Class]; exists {
+			return nil, fmt.Errorf("class %d is configured twice", class.Class)
+		}
+		if config.Selection == MLQTimeSliced && class.Share <= 0 {
+			return nil, fmt.Errorf("class %d needs a positive share", class.Class)
+		}
+
+		entry := &mlqClass{config: class}
+		q.classes = append(q.classes, entry)
+		q.byClass[class.Class] = entry
+	}
+
+	return q, nil
+}
+
+func (q *MLQQueue) Enqueue(p types.Process) error {
+	if p == nil {
+		return fmt.Errorf("cannot enqueue nil process")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	class, err := q.classOf(p)
+	if err != nil {
+		return err
+	}
+
+	// A class that was idle resumes at the pace of the busy ones rather than
+	// claiming the CPU time it did not use
+	if q.selection == MLQTimeSliced && !class.active() {
+		if floor := q.minActiveVTime(); floor != math.MaxFloat64 && class.vtime < floor {
+			class.vtime = floor
+		}
+	}
+
+	return class.config.Queue.Enqueue(p)
+}
+
+func (q *MLQQueue) Dequeue() (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	class := q.next()
+	if class == nil {
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	p, err := class.config.Queue.Dequeue()
+	if err != nil {
+		return nil, err
+	}
+
+	class.running++
+	q.metrics.record(p)
+	return p, nil
+}
+
+func (q *MLQQueue) Peek() (types.Process, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	class := q.next()
+	if class == nil {
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	return class.config.Queue.Peek()
+}
+
+func (q *MLQQueue) IsEmpty() bool {
+	return q.Size() == 0
+}
+
+func (q *MLQQueue) Size() int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	size := 0
+	for _, class := range q.classes {
+		size += class.config.Queue.Size()
+	}
+	return size
+}
+
+func (q *MLQQueue) GetMetrics() types.SchedulingMetrics {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.metrics.snapshot()
+}
+
+// SetClock moves the queue and the queues of its classes to the clock
+func (q *MLQQueue) SetClock(clock types.Clock) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.metrics.setClock(clock)
+	for _, class := range q.classes {
+		if child, ok := class.config.Queue.(types.ClockedQueue); ok {
+			child.SetClock(clock)
+		}
+	}
+}
+
+// DequeueMatching dequeues the first process match accepts, trying the
+// classes in the order they are selected in
+func (q *MLQQueue) DequeueMatching(match func(types.Process) bool) (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	classes := q.classes
+	if q.selection == MLQTimeSliced {
+		classes = append([]*mlqClass(nil), q.classes...)
+		sort.SliceStable(classes, func(i, j int) bool {
+			return classes[i].vtime < classes[j].vtime
+		})
+	}
+
+	for _, class := range classes {
+		if class.config.Queue.IsEmpty() {
+			continue
+		}
+
+		p, err := dequeueMatching(class.config.Queue, match)
+		if err != nil {
+			continue
+		}
+		class.running++
+		q.metrics.record(p)
+		return p, nil
+	}
+
+	return nil, fmt.Errorf("no queued process matches")
+}
+
+// Remove takes the process off the queue of its class without counting it as
+// dispatched. The class keeps it until the SliceMigrated RecordSlice
+func (q *MLQQueue) Remove(p types.Process) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	class, err := q.classOf(p)
+	if err != nil {
+		return err
+	}
+	if err := remove(class.config.Queue, p); err != nil {
+		return err
+	}
+	class.running++
+	return nil
+}
+
+// MLQ specific methods
+
+// GetMLQMetrics returns the scheduling metrics along with the depth and CPU
+// time of every class
+func (q *MLQQueue) GetMLQMetrics() MLQMetrics {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	var total time.Duration
+	for _, class := range q.classes {
+		total += class.cpuTime
+	}
+
+	classes := make([]MLQClassMetrics, len(q.classes))
+	for i, class := range q.classes {
+		classes[i] = MLQClassMetrics{
+			Class:   class.config.Class,
+			Depth:   class.config.Queue.Size(),
+			CPUTime: class.cpuTime,
+		}
+		if total > 0 {
+			classes[i].CPUShare = float64(class.cpuTime) / float64(total)
+		}
+	}
+
+	return MLQMetrics{
+		SchedulingMetrics: q.metrics.snapshot(),
+		Classes:           classes,
+	}
+}
+
+// GetTimeSlice returns the time slice of the child queue of the process. In
+// time sliced selection, classes without one of their own get ClassSlice
+func (q *MLQQueue) GetTimeSlice(p types.Process) time.Duration {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	class, err := q.classOf(p)
+	if err != nil {
+		return 0
+	}
+
+	if child, ok := class.config.Queue.(types.TimeSlicedQueue); ok {
+		if slice := child.GetTimeSlice(p); slice > 0 {
+			return slice
+		}
+	}
+
+	if q.selection == MLQTimeSliced {
+		return q.classSlice
+	}
+	return 0
+}
+
+// RequeueProcess returns a preempted process to its class queue. The class
+// never stopped being active, so its vtime is left alone
+func (q *MLQQueue) RequeueProcess(p types.Process) error {
+	if p == nil {
+		return fmt.Errorf("cannot requeue nil process")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	class, err := q.classOf(p)
+	if err != nil {
+		return err
+	}
+
+	if child, ok := class.config.Queue.(types.TimeSlicedQueue); ok {
+		return child.RequeueProcess(p)
+	}
+	return class.config.Queue.Enqueue(p)
+}
+
+// ShouldPreempt preempts for arrivals of a more important class under strict
+// priority, and otherwise defers to the child queue when both processes share
+// a class
+func (q *MLQQueue) ShouldPreempt(running, arrived types.Process) bool {
+	if running == nil || arrived == nil {
+		return false
+	}
+
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	runningClass, err := q.classOf(running)
+	if err != nil {
+		return false
+	}
+	arrivedClass, err := q.classOf(arrived)
+	if err != nil {
+		return false
+	}
+
+	if runningClass != arrivedClass {
+		return q.selection == MLQStrictPriority && q.rank(arrivedClass) < q.rank(runningClass)
+	}
+
+	if child, ok := runningClass.config.Queue.(types.PreemptiveQueue); ok {
+		return child.ShouldPreempt(running, arrived)
+	}
+	return false
+}
+
+// RecordSlice charges the CPU time to the class of the process and passes the
+// slice on to the child queue. A process that is not preempted leaves its
+// class until it is enqueued again
+func (q *MLQQueue) RecordSlice(p types.Process, ran time.Duration, outcome types.SliceOutcome) {
+	q.mu.Lock()
+	class, err := q.classOf(p)
+	if err == nil {
+		class.cpuTime += ran
+		if class.config.Share > 0 {
+			class.vtime += float64(ran) / class.config.Share
+		}
+		if outcome != types.SliceExpired && outcome != types.SlicePreempted && class.running > 0 {
+			class.running--
+		}
+	}
+	q.mu.Unlock()
+
+	if err != nil {
+		return
+	}
+
+	if child, ok := class.config.Queue.(types.FeedbackQueue); ok {
+		child.RecordSlice(p, ran, outcome)
+	}
+}
+
+func (q *MLQQueue) classOf(p types.Process) (*mlqClass, error) {
+	class, ok := q.byClass[p.GetClass()]
+	if !ok {
+		return nil, fmt.Errorf("no queue configured for process class %d", p.GetClass())
+	}
+	return class, nil
+}
+
+func (q *MLQQueue) rank(class *mlqClass) int {
+	for i, c := range q.classes {
+		if c == class {
+			return i
+		}
+	}
+	return len(q.classes)
+}
+
+// next picks the class to run from, or nil when every class is empty
+func (q *MLQQueue) next() *mlqClass {
+	var best *mlqClass
+	for _, class := range q.classes {
+		if class.config.Queue.IsEmpty() {
+			continue
+		}
+		if q.selection == MLQStrictPriority {
+			return class
+		}
+		if best == nil || class.vtime < best.vtime {
+			best = class
+		}
+	}
+	return best
+}
+
+func (q *MLQQueue) minActiveVTime() float64 {
+	min := math.MaxFloat64
+	for _, class := range q.classes {
+		if class.active() && class.vtime < min {
+			min = class.vtime
+		}
+	}
+	return min
+}
@@ -0,0 +1,224 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/process"
+	"cpu-scheduling/core/internal/types"
+	"testing"
+	"time"
+)
+
+func newClassPCB(pid int, class types.ProcessClass) *process.PCB {
+	p := process.NewPCB(pid, process.NewTask(func() (any, error) { return nil, nil }))
+	p.SetClass(class)
+	return p
+}
+
+func newSplitMLQ(t *testing.T) *MLQQueue {
+	queue, err := NewMLQQueue(MLQConfig{
+		Classes: []MLQClass{
+			{Class: types.INTERACTIVE, Queue: NewRoundRobinQueue(10 * time.Millisecond), Share: 80},
+			{Class: types.BATCH, Queue: NewFCFSQueue(), Share: 20},
+		},
+		Selection:  MLQTimeSliced,
+		ClassSlice: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return queue
+}
+
+func TestNewMLQQueue(t *testing.T) {
+	t.Run("should create queue from default config", func(t *testing.T) {
+		queue, err := NewMLQQueue(DefaultMLQConfig())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !queue.IsEmpty() {
+			t.Error("new queue should be empty")
+		}
+	})
+
+	t.Run("should return error without classes", func(t *testing.T) {
+		if _, err := NewMLQQueue(MLQConfig{}); err == nil {
+			t.Error("expected error for empty config")
+		}
+	})
+
+	t.Run("should return error for duplicate classes", func(t *testing.T) {
+		config := MLQConfig{Classes: []MLQClass{
+			{Class: types.BATCH, Queue: NewFCFSQueue()},
+			{Class: types.BATCH, Queue: NewFCFSQueue()},
+		}}
+
+		if _, err := NewMLQQueue(config); err == nil {
+			t.Error("expected error for duplicate class")
+		}
+	})
+
+	t.Run("should return error for missing share in time sliced mode", func(t *testing.T) {
+		config := MLQConfig{
+			Classes:   []MLQClass{{Class: types.BATCH, Queue: NewFCFSQueue()}},
+			Selection: MLQTimeSliced,
+		}
+
+		if _, err := NewMLQQueue(config); err == nil {
+			t.Error("expected error for zero share")
+		}
+	})
+}
+
+func TestMLQQueue_Enqueue(t *testing.T) {
+	t.Run("should return error for a class without a queue", func(t *testing.T) {
+		queue, _ := NewMLQQueue(MLQConfig{Classes: []MLQClass{{Class: types.BATCH, Queue: NewFCFSQueue()}}})
+
+		if err := queue.Enqueue(newClassPCB(1, types.SYSTEM)); err == nil {
+			t.Error("expected error for unconfigured class")
+		}
+	})
+}
+
+func TestMLQQueue_Dequeue(t *testing.T) {
+	t.Run("should serve classes in strict priority order", func(t *testing.T) {
+		queue, _ := NewMLQQueue(DefaultMLQConfig())
+		queue.Enqueue(newClassPCB(1, types.BACKGROUND))
+		queue.Enqueue(newClassPCB(2, types.BATCH))
+		queue.Enqueue(newClassPCB(3, types.SYSTEM))
+		queue.Enqueue(newClassPCB(4, types.INTERACTIVE))
+
+		for _, expected := range []int{3, 4, 2, 1} {
+			p, err := queue.Dequeue()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.GetPID() != expected {
+				t.Errorf("expected PID %d, got %d", expected, p.GetPID())
+			}
+		}
+	})
+
+	t.Run("should split CPU time between classes by share", func(t *testing.T) {
+		queue := newSplitMLQ(t)
+		for i := 0; i < 10; i++ {
+			queue.Enqueue(newClassPCB(i, types.INTERACTIVE))
+			queue.Enqueue(newClassPCB(100+i, types.BATCH))
+		}
+
+		batchRuns := 0
+		for i := 0; i < 10; i++ {
+			p, _ := queue.Dequeue()
+			if p.GetClass() == types.BATCH {
+				batchRuns++
+			}
+			queue.RecordSlice(p, 10*time.Millisecond, types.SliceExpired)
+			queue.RequeueProcess(p)
+		}
+
+		if batchRuns != 2 {
+			t.Errorf("expected batch to get 2 of 10 slices, got %d", batchRuns)
+		}
+
+		metrics := queue.GetMLQMetrics()
+		if metrics.Classes[0].CPUShare != 0.8 || metrics.Classes[1].CPUShare != 0.2 {
+			t.Errorf("expected an 80/20 split, got %v/%v", metrics.Classes[0].CPUShare, metrics.Classes[1].CPUShare)
+		}
+	})
+
+	t.Run("should split CPU time between FCFS classes by share", func(t *testing.T) {
+		queue, _ := NewMLQQueue(MLQConfig{
+			Classes: []MLQClass{
+				{Class: types.SYSTEM, Queue: NewFCFSQueue(), Share: 80},
+				{Class: types.BATCH, Queue: NewFCFSQueue(), Share: 20},
+			},
+			Selection:  MLQTimeSliced,
+			ClassSlice: 10 * time.Millisecond,
+		})
+		queue.Enqueue(newClassPCB(1, types.SYSTEM))
+		queue.Enqueue(newClassPCB(2, types.BATCH))
+
+		for i := 0; i < 1000; i++ {
+			p, _ := queue.Dequeue()
+			queue.RecordSlice(p, queue.GetTimeSlice(p), types.SliceExpired)
+			queue.RequeueProcess(p)
+		}
+
+		metrics := queue.GetMLQMetrics()
+		if metrics.Classes[0].CPUShare != 0.8 || metrics.Classes[1].CPUShare != 0.2 {
+			t.Errorf("expected an 80/20 split, got %v/%v", metrics.Classes[0].CPUShare, metrics.Classes[1].CPUShare)
+		}
+	})
+
+	t.Run("should not let a class that was idle claim its unused time", func(t *testing.T) {
+		queue := newSplitMLQ(t)
+		queue.Enqueue(newClassPCB(1, types.INTERACTIVE))
+		for i := 0; i < 10; i++ {
+			p, _ := queue.Dequeue()
+			queue.RecordSlice(p, 10*time.Millisecond, types.SliceExpired)
+			queue.RequeueProcess(p)
+		}
+
+		queue.Enqueue(newClassPCB(2, types.BATCH))
+		batchRuns := 0
+		for i := 0; i < 5; i++ {
+			p, _ := queue.Dequeue()
+			if p.GetClass() == types.BATCH {
+				batchRuns++
+			}
+			queue.RecordSlice(p, 10*time.Millisecond, types.SliceExpired)
+			queue.RequeueProcess(p)
+		}
+		if batchRuns != 1 {
+			t.Errorf("expected the late class to get 1 of 5 slices, got %d", batchRuns)
+		}
+	})
+
+	t.Run("should return error when empty", func(t *testing.T) {
+		queue, _ := NewMLQQueue(DefaultMLQConfig())
+
+		if _, err := queue.Dequeue(); err == nil {
+			t.Error("expected error when dequeuing from empty queue")
+		}
+	})
+}
+
+func TestMLQQueue_GetTimeSlice(t *testing.T) {
+	t.Run("should use the child quantum or the class slice", func(t *testing.T) {
+		queue := newSplitMLQ(t)
+
+		if got := queue.GetTimeSlice(newClassPCB(1, types.INTERACTIVE)); got != 10*time.Millisecond {
+			t.Errorf("expected child quantum 10ms, got %v", got)
+		}
+		if got := queue.GetTimeSlice(newClassPCB(2, types.BATCH)); got != 10*time.Millisecond {
+			t.Errorf("expected class slice 10ms, got %v", got)
+		}
+	})
+
+	t.Run("should not slice FCFS classes under strict priority", func(t *testing.T) {
+		queue, _ := NewMLQQueue(DefaultMLQConfig())
+
+		if got := queue.GetTimeSlice(newClassPCB(1, types.BATCH)); got != 0 {
+			t.Errorf("expected no time slice, got %v", got)
+		}
+	})
+}
+
+func TestMLQQueue_ShouldPreempt(t *testing.T) {
+	t.Run("should preempt for a more important class under strict priority", func(t *testing.T) {
+		queue, _ := NewMLQQueue(DefaultMLQConfig())
+
+		if !queue.ShouldPreempt(newClassPCB(1, types.BATCH), newClassPCB(2, types.SYSTEM)) {
+			t.Error("expected system arrival to preempt batch process")
+		}
+		if queue.ShouldPreempt(newClassPCB(1, types.SYSTEM), newClassPCB(2, types.BATCH)) {
+			t.Error("expected batch arrival not to preempt system process")
+		}
+	})
+
+	t.Run("should not preempt across classes in time sliced mode", func(t *testing.T) {
+		queue := newSplitMLQ(t)
+
+		if queue.ShouldPreempt(newClassPCB(1, types.BATCH), newClassPCB(2, types.INTERACTIVE)) {
+			t.Error("expected no preemption across classes")
+		}
+	})
+}
@@ -0,0 +1,229 @@
+package queue
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// decodeOptions overlays a generic config map, as read from JSON, YAML or the
+// command line, onto an options struct. Keys match field names ignoring case,
+// underscores and dashes, so "initial_estimate" sets InitialEstimate.
+// Durations are given as strings like "10ms" or as nanoseconds, and types
+// implementing encoding.TextUnmarshaler are decoded from strings
+func decodeOptions(dst any, config map[string]any) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("options must be decoded into a pointer, got %T", dst)
+	}
+	if config == nil {
+		return nil
+	}
+	return decodeValue(v.Elem(), config, "")
+}
+
+func decodeValue(dst reflect.Value, src any, path string) error {
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+
+	if s, ok := src.(string); ok && reflect.PointerTo(dst.Type()).Implements(textUnmarshalerType) {
+		if err := dst.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return optionError(path, err)
+		}
+		return nil
+	}
+
+	if dst.Type() == durationType {
+		d, err := decodeDuration(src)
+		if err != nil {
+			return optionError(path, err)
+		}
+		dst.SetInt(int64(d))
+		return nil
+	}
+
+	sv := reflect.ValueOf(src)
+	switch dst.Kind() {
+	case reflect.Interface:
+		if !sv.Type().AssignableTo(dst.Type()) {
+			return optionError(path, fmt.Errorf("cannot use %T", src))
+		}
+		dst.Set(sv)
+
+	case reflect.Pointer:
+		elem := reflect.New(dst.Type().Elem())
+		if err := decodeValue(elem.Elem(), src, path); err != nil {
+			return err
+		}
+		dst.Set(elem)
+
+	case reflect.Struct:
+		fields, ok := src.(map[string]any)
+		if !ok {
+			return optionError(path, fmt.Errorf("expected a map, got %T", src))
+		}
+		for key, value := range fields {
+			field, ok := fieldByOption(dst, key)
+			if !ok {
+				return optionError(joinOptionPath(path, key), fmt.Errorf("unknown option"))
+			}
+			if err := decodeValue(field, value, joinOptionPath(path, key)); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Slice:
+		if sv.Kind() != reflect.Slice {
+			return optionError(path, fmt.Errorf("expected a list, got %T", src))
+		}
+		list := reflect.MakeSlice(dst.Type(), sv.Len(), sv.Len())
+		for i := 0; i < sv.Len(); i++ {
+			if err := decodeValue(list.Index(i), sv.Index(i).Interface(), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(list)
+
+	case reflect.Map:
+		entries, ok := src.(map[string]any)
+		if !ok || dst.Type().Key().Kind() != reflect.String {
+			return optionError(path, fmt.Errorf("expected a map, got %T", src))
+		}
+		m := reflect.MakeMapWithSize(dst.Type(), len(entries))
+		for key, value := range entries {
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			if err := decodeValue(elem, value, joinOptionPath(path, key)); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(key).Convert(dst.Type().Key()), elem)
+		}
+		dst.Set(m)
+
+	case reflect.String:
+		s, ok := src.(string)
+		if !ok {
+			return optionError(path, fmt.Errorf("expected a string, got %T", src))
+		}
+		dst.SetString(s)
+
+	case reflect.Bool:
+		b, ok := src.(bool)
+		if !ok {
+			return optionError(path, fmt.Errorf("expected a boolean, got %T", src))
+		}
+		dst.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := decodeInt(src)
+		if err != nil {
+			return optionError(path, err)
+		}
+		if dst.OverflowInt(n) {
+			return optionError(path, fmt.Errorf("%d is out of range", n))
+		}
+		dst.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := decodeInt(src)
+		if err != nil {
+			return optionError(path, err)
+		}
+		if n < 0 || dst.OverflowUint(uint64(n)) {
+			return optionError(path, fmt.Errorf("%d is out of range", n))
+		}
+		dst.SetUint(uint64(n))
+
+	case reflect.Float32, reflect.Float64:
+		switch sv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			dst.SetFloat(float64(sv.Int()))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			dst.SetFloat(float64(sv.Uint()))
+		case reflect.Float32, reflect.Float64:
+			dst.SetFloat(sv.Float())
+		default:
+			return optionError(path, fmt.Errorf("expected a number, got %T", src))
+		}
+
+	default:
+		return optionError(path, fmt.Errorf("unsupported option type %s", dst.Type()))
+	}
+
+	return nil
+}
+
+// fieldByOption finds the exported field a config key refers to
+func fieldByOption(v reflect.Value, key string) (reflect.Value, bool) {
+	name := normalizeOptionName(key)
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.IsExported() && normalizeOptionName(field.Name) == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func normalizeOptionName(name string) string {
+	name = strings.ReplaceAll(name, "_", "")
+	name = strings.ReplaceAll(name, "-", "")
+	return strings.ToLower(name)
+}
+
+// decodeDuration accepts a duration string or a whole number of nanoseconds
+func decodeDuration(src any) (time.Duration, error) {
+	if s, ok := src.(string); ok {
+		return time.ParseDuration(s)
+	}
+	n, err := decodeInt(src)
+	if err != nil {
+		return 0, fmt.Errorf("expected a duration, got %T", src)
+	}
+	return time.Duration(n), nil
+}
+
+// decodeInt accepts any integer, and floats without a fractional part since
+// JSON decodes every number as a float64
+func decodeInt(src any) (int64, error) {
+	v := reflect.ValueOf(src)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() > math.MaxInt64 {
+			return 0, fmt.Errorf("%d is out of range", v.Uint())
+		}
+		return int64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, fmt.Errorf("expected a whole number, got %v", f)
+		}
+		return int64(f), nil
+	}
+	return 0, fmt.Errorf("expected a number, got %T", src)
+}
+
+func joinOptionPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func optionError(path string, err error) error {
+	if path == "" {
+		return fmt.Errorf("invalid options: %w", err)
+	}
+	return fmt.Errorf("option %q: %w", path, err)
+}
@@ -0,0 +1,269 @@
+package queue
+
+import (
+	"container/heap"
+	"cpu-scheduling/core/internal/types"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// AgingPolicy raises the effective priority of processes the longer they wait
+// in READY, so low priority processes cannot starve. Every Interval spent
+// waiting raises the priority by Rate levels, up to MaxBoost levels above the
+// base priority. A zero Interval disables aging
+type AgingPolicy struct {
+	Interval time.Duration
+	Rate     int
+	MaxBoost int
+}
+
+func (a AgingPolicy) enabled() bool {
+	return a.Interval > 0 && a.Rate > 0 && a.MaxBoost > 0
+}
+
+// boost returns how many levels a process that has waited for the given
+// duration is raised by
+func (a AgingPolicy) boost(waited time.Duration) int {
+	if !a.enabled() {
+		return 0
+	}
+
+	boost := int(waited/a.Interval) * a.Rate
+	if boost > a.MaxBoost {
+		return a.MaxBoost
+	}
+	return boost
+}
+
+type priorityEntry struct {
+	process    types.Process
+	priority   int
+	enqueuedAt time.Time
+	seq        uint64
+}
+
+// priorityHeap is a min-heap of processes by effective priority, breaking ties
+// in arrival order
+type priorityHeap []*priorityEntry
+
+func (h priorityHeap) Len() int { return len(h) }
+
+func (h priorityHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority < h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h priorityHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *priorityHeap) Push(x any) { *h = append(*h, x.(*priorityEntry)) }
+
+func (h *priorityHeap) Pop() any {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return entry
+}
+
+// PriorityQueue orders processes by effective priority, lowest value first.
+// Waiting processes are aged according to the AgingPolicy, and a process gets
+// its base priority back when it is dequeued to run. In preemptive mode an
+// arriving process with a higher priority preempts the running one
+type PriorityQueue struct {
+	processes  priorityHeap
+	aging      AgingPolicy
+	preemptive bool
+	nextSeq    uint64
+	mu         sync.RWMutex
+
+	metrics metricsTracker
+	clock   types.Clock
+}
+
+func NewPriorityQueue(aging AgingPolicy) *PriorityQueue {
+	return &PriorityQueue{
+		processes: make(priorityHeap, 0),
+		aging:     aging,
+		metrics:   newMetricsTracker(),
+		clock:     types.RealClock{},
+	}
+}
+
+// NewPreemptivePriorityQueue creates a PriorityQueue in preemptive mode
+func NewPreemptivePriorityQueue(aging AgingPolicy) *PriorityQueue {
+	q := NewPriorityQueue(aging)
+	q.preemptive = true
+	return q
+}
+
+func (q *PriorityQueue) Enqueue(p types.Process) error {
+	if p == nil {
+		return fmt.Errorf("cannot enqueue nil process")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	heap.Push(&q.processes, &priorityEntry{
+		process:    p,
+		priority:   p.GetEffectivePriority(),
+		enqueuedAt: q.clock.Now(),
+		seq:        q.nextSeq,
+	})
+	q.nextSeq++
+	return nil
+}
+
+func (q *PriorityQueue) Dequeue() (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.processes) == 0 {
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	q.age()
+
+	// The boost only lasts while waiting. It is dropped before the process
+	// leaves the queue, so a failure leaves it queued
+	head := q.processes[0].process
+	if err := head.SetEffectivePriority(head.GetPriority()); err != nil {
+		return nil, fmt.Errorf("failed to reset effective priority: %v", err)
+	}
+
+	entry := heap.Pop(&q.processes).(*priorityEntry)
+	q.metrics.record(entry.process)
+
+	return entry.process, nil
+}
+
+func (q *PriorityQueue) Peek() (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.processes) == 0 {
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	q.age()
+
+	return q.processes[0].process, nil
+}
+
+func (q *PriorityQueue) IsEmpty() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return len(q.processes) == 0
+}
+
+func (q *PriorityQueue) Size() int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return len(q.processes)
+}
+
+func (q *PriorityQueue) GetMetrics() types.SchedulingMetrics {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.metrics.snapshot()
+}
+
+func (q *PriorityQueue) SetClock(clock types.Clock) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.metrics.setClock(clock)
+	q.clock = clock
+}
+
+// DequeueMatching dequeues the most urgent process that match accepts, after
+// aging the queue like Dequeue
+func (q *PriorityQueue) DequeueMatching(match func(types.Process) bool) (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.age()
+
+	i := firstMatching(len(q.processes), q.processes.Less, func(i int) bool {
+		return match(q.processes[i].process)
+	})
+	if i < 0 {
+		return nil, fmt.Errorf("no queued process matches")
+	}
+
+	p := q.processes[i].process
+	if err := p.SetEffectivePriority(p.GetPriority()); err != nil {
+		return nil, fmt.Errorf("failed to reset effective priority: %v", err)
+	}
+
+	entry := heap.Remove(&q.processes, i).(*priorityEntry)
+	q.metrics.record(entry.process)
+	return entry.process, nil
+}
+
+// Remove takes the process off the queue without counting it as dispatched.
+// It is not going to run yet, so it keeps its aging boost
+func (q *PriorityQueue) Remove(p types.Process) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := indexOf(q.processes, p, func(e *priorityEntry) types.Process { return e.process })
+	if i < 0 {
+		return fmt.Errorf("process %d is not queued", p.GetPID())
+	}
+
+	heap.Remove(&q.processes, i)
+	return nil
+}
+
+// Priority specific methods
+
+// IsPreemptive reports whether arrivals can preempt the running process
+func (q *PriorityQueue) IsPreemptive() bool {
+	return q.preemptive
+}
+
+// ShouldPreempt reports whether the arrived process is more urgent than the
+// running one. It is always false in non-preemptive mode
+func (q *PriorityQueue) ShouldPreempt(running, arrived types.Process) bool {
+	if !q.preemptive || running == nil || arrived == nil {
+		return false
+	}
+	return arrived.GetEffectivePriority() < running.GetEffectivePriority()
+}
+
+// age recomputes the effective priority of every waiting process from the time
+// it has spent in the queue and restores the heap order. The priority stays
+// between the base priority and types.HighestPriority, so it is always valid,
+// but a process that still refuses it keeps its previous priority
+func (q *PriorityQueue) age() {
+	if !q.aging.enabled() {
+		return
+	}
+
+	now := q.clock.Now()
+	for _, entry := range q.processes {
+		base := entry.process.GetPriority()
+		priority := base - q.aging.boost(now.Sub(entry.enqueuedAt))
+		if priority < types.HighestPriority {
+			priority = types.HighestPriority
+		}
+		if priority == entry.priority {
+			continue
+		}
+
+		if err := entry.process.SetEffectivePriority(priority); err != nil {
+			continue
+		}
+		entry.priority = priority
+	}
+
+	heap.Init(&q.processes)
+}
@@ -0,0 +1,185 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/process"
+	"cpu-scheduling/core/internal/types"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// stuckPriorityPCB is a process whose effective priority cannot be changed
+type stuckPriorityPCB struct {
+	*process.PCB
+}
+
+func (p stuckPriorityPCB) SetEffectivePriority(priority int) error {
+	return fmt.Errorf("effective priority is fixed")
+}
+
+func newPriorityPCB(pid, priority int) *process.PCB {
+	p := process.NewPCB(pid, process.NewTask(func() (any, error) { return nil, nil }))
+	p.SetPriority(priority)
+	return p
+}
+
+func TestAgingPolicy_Boost(t *testing.T) {
+	t.Run("should raise priority per interval up to the cap", func(t *testing.T) {
+		aging := AgingPolicy{Interval: 10 * time.Millisecond, Rate: 2, MaxBoost: 5}
+
+		if got := aging.boost(5 * time.Millisecond); got != 0 {
+			t.Errorf("expected boost 0, got %d", got)
+		}
+		if got := aging.boost(20 * time.Millisecond); got != 4 {
+			t.Errorf("expected boost 4, got %d", got)
+		}
+		if got := aging.boost(time.Second); got != 5 {
+			t.Errorf("expected boost capped at 5, got %d", got)
+		}
+	})
+
+	t.Run("should be disabled with zero interval", func(t *testing.T) {
+		aging := AgingPolicy{Rate: 1, MaxBoost: 10}
+
+		if got := aging.boost(time.Hour); got != 0 {
+			t.Errorf("expected boost 0, got %d", got)
+		}
+	})
+}
+
+func TestPriorityQueue_Enqueue(t *testing.T) {
+	t.Run("should return error for nil process", func(t *testing.T) {
+		queue := NewPriorityQueue(AgingPolicy{})
+
+		if err := queue.Enqueue(nil); err == nil {
+			t.Error("expected error for nil process")
+		}
+	})
+}
+
+func TestPriorityQueue_Dequeue(t *testing.T) {
+	t.Run("should dequeue highest priority first", func(t *testing.T) {
+		queue := NewPriorityQueue(AgingPolicy{})
+		queue.Enqueue(newPriorityPCB(1, 30))
+		queue.Enqueue(newPriorityPCB(2, 5))
+		queue.Enqueue(newPriorityPCB(3, 20))
+		queue.Enqueue(newPriorityPCB(4, 5))
+
+		for _, expected := range []int{2, 4, 3, 1} {
+			p, err := queue.Dequeue()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.GetPID() != expected {
+				t.Errorf("expected PID %d, got %d", expected, p.GetPID())
+			}
+		}
+	})
+
+	t.Run("should return error when empty", func(t *testing.T) {
+		queue := NewPriorityQueue(AgingPolicy{})
+
+		if _, err := queue.Dequeue(); err == nil {
+			t.Error("expected error when dequeuing from empty queue")
+		}
+	})
+
+	t.Run("should keep the process queued when its priority cannot be reset", func(t *testing.T) {
+		queue := NewPriorityQueue(AgingPolicy{Interval: time.Millisecond, Rate: 1, MaxBoost: 5})
+		stuck := stuckPriorityPCB{newPriorityPCB(1, 20)}
+		queue.Enqueue(stuck)
+		time.Sleep(3 * time.Millisecond)
+
+		if _, err := queue.Dequeue(); err == nil {
+			t.Fatal("expected error when the priority cannot be reset")
+		}
+		if queue.Size() != 1 {
+			t.Errorf("expected the process to stay queued, got size %d", queue.Size())
+		}
+		if stuck.GetEffectivePriority() != 20 {
+			t.Errorf("expected the refused boost to be ignored, got %d", stuck.GetEffectivePriority())
+		}
+	})
+}
+
+func TestPriorityQueue_Aging(t *testing.T) {
+	t.Run("should let a waiting process overtake newer high priority ones", func(t *testing.T) {
+		queue := NewPriorityQueue(AgingPolicy{Interval: time.Millisecond, Rate: 10, MaxBoost: 39})
+		starving := newPriorityPCB(1, 39)
+		queue.Enqueue(starving)
+
+		time.Sleep(5 * time.Millisecond)
+		queue.Enqueue(newPriorityPCB(2, 10))
+
+		first, _ := queue.Dequeue()
+		if first.GetPID() != 1 {
+			t.Errorf("expected aged process first, got PID %d", first.GetPID())
+		}
+	})
+
+	t.Run("should reset the effective priority when dequeued", func(t *testing.T) {
+		queue := NewPriorityQueue(AgingPolicy{Interval: time.Millisecond, Rate: 1, MaxBoost: 3})
+		p := newPriorityPCB(1, 30)
+		queue.Enqueue(p)
+
+		time.Sleep(5 * time.Millisecond)
+		queue.Peek()
+
+		if p.GetEffectivePriority() != 27 {
+			t.Errorf("expected effective priority capped at 27, got %d", p.GetEffectivePriority())
+		}
+
+		queue.Dequeue()
+
+		if p.GetEffectivePriority() != 30 {
+			t.Errorf("expected effective priority reset to 30, got %d", p.GetEffectivePriority())
+		}
+	})
+
+	t.Run("should not raise priority above the highest level", func(t *testing.T) {
+		queue := NewPriorityQueue(AgingPolicy{Interval: time.Millisecond, Rate: 10, MaxBoost: 20})
+		p := newPriorityPCB(1, 2)
+		queue.Enqueue(p)
+
+		time.Sleep(3 * time.Millisecond)
+		queue.Peek()
+
+		if p.GetEffectivePriority() != types.HighestPriority {
+			t.Errorf("expected effective priority %d, got %d", types.HighestPriority, p.GetEffectivePriority())
+		}
+	})
+}
+
+func TestPriorityQueue_ShouldPreempt(t *testing.T) {
+	t.Run("should preempt for a more urgent arrival", func(t *testing.T) {
+		queue := NewPreemptivePriorityQueue(AgingPolicy{})
+		running := newPriorityPCB(1, 20)
+
+		if !queue.ShouldPreempt(running, newPriorityPCB(2, 10)) {
+			t.Error("expected higher priority arrival to preempt")
+		}
+		if queue.ShouldPreempt(running, newPriorityPCB(3, 20)) {
+			t.Error("expected equal priority arrival not to preempt")
+		}
+	})
+
+	t.Run("should never preempt in non-preemptive mode", func(t *testing.T) {
+		queue := NewPriorityQueue(AgingPolicy{})
+
+		if queue.ShouldPreempt(newPriorityPCB(1, 39), newPriorityPCB(2, 0)) {
+			t.Error("expected non-preemptive queue not to preempt")
+		}
+	})
+}
+
+func TestPriorityQueue_GetMetrics(t *testing.T) {
+	t.Run("should count dequeued processes", func(t *testing.T) {
+		queue := NewPriorityQueue(AgingPolicy{})
+		queue.Enqueue(newPriorityPCB(1, 10))
+		queue.Dequeue()
+
+		if queue.GetMetrics().ThroughputPerMin <= 0 {
+			t.Error("expected non-zero throughput")
+		}
+	})
+}
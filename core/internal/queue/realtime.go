@@ -0,0 +1,99 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/types"
+	"time"
+)
+
+// Utilization returns the fraction of the CPU the processes claim, the sum of
+// their WCET over their period. Processes without a period count with their
+// relative deadline instead, and processes without either claim nothing
+func Utilization(processes ...types.Process) float64 {
+	total := 0.0
+	for _, p := range processes {
+		total += utilization(p)
+	}
+	return total
+}
+
+func utilization(p types.Process) float64 {
+	interval := p.GetPeriod()
+	if interval == 0 {
+		interval = p.GetRelativeDeadline()
+	}
+	if interval == 0 {
+		return 0
+	}
+	return float64(p.GetWCET()) / float64(interval)
+}
+
+// DeadlineMetrics reports how well jobs met their deadlines. Lateness is the
+// completion time minus the deadline and is negative for jobs that finished
+// early, tardiness is the lateness of late jobs and zero otherwise. Jobs whose
+// task failed count as Failed and towards none of the others
+type DeadlineMetrics struct {
+	Jobs             int
+	Misses           int
+	Failed           int
+	MissRatio        float64
+	MaxLateness      time.Duration
+	AverageLateness  time.Duration
+	TotalTardiness   time.Duration
+	AverageTardiness time.Duration
+}
+
+// deadlineTracker accumulates the deadline metrics of finished jobs. It is not
+// safe for concurrent use and relies on the lock of the queue that embeds it
+type deadlineTracker struct {
+	jobs           int
+	misses         int
+	failed         int
+	maxLateness    time.Duration
+	totalLateness  time.Duration
+	totalTardiness time.Duration
+}
+
+// record accounts for a job of the process finishing at the given time. Jobs
+// without a deadline are ignored
+func (d *deadlineTracker) record(p types.Process, finished time.Time) {
+	deadline := p.GetAbsoluteDeadline()
+	if deadline.IsZero() {
+		return
+	}
+
+	lateness := finished.Sub(deadline)
+	if d.jobs == 0 || lateness > d.maxLateness {
+		d.maxLateness = lateness
+	}
+	d.jobs++
+	d.totalLateness += lateness
+	if lateness > 0 {
+		d.misses++
+		d.totalTardiness += lateness
+	}
+}
+
+// fail accounts for a job of the process whose task returned an error. Jobs
+// without a deadline are ignored
+func (d *deadlineTracker) fail(p types.Process) {
+	if !p.GetAbsoluteDeadline().IsZero() {
+		d.failed++
+	}
+}
+
+func (d *deadlineTracker) snapshot() DeadlineMetrics {
+	if d.jobs == 0 {
+		return DeadlineMetrics{Failed: d.failed}
+	}
+
+	return DeadlineMetrics{
+		Jobs:             d.jobs,
+		Misses:           d.misses,
+		Failed:           d.failed,
+		MissRatio:        float64(d.misses) / float64(d.jobs),
+		MaxLateness:      d.maxLateness,
+		AverageLateness:  d.totalLateness / time.Duration(d.jobs),
+		TotalTardiness:   d.totalTardiness,
+		AverageTardiness: d.totalTardiness / time.Duration(d.jobs),
+	}
+}
@@ -0,0 +1,243 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/types"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// policy is a registered scheduling policy. defaults returns a pointer to a
+// fresh copy of its options, and build creates a queue from decoded options
+type policy struct {
+	defaults func() any
+	build    func(options any) (types.SchedulingQueue, error)
+}
+
+var registry = struct {
+	mu       sync.RWMutex
+	policies map[string]policy
+}{policies: make(map[string]policy)}
+
+// Register makes a policy available to New under the given name. Options is
+// the typed configuration of the policy: defaults returns the options used
+// for keys that a config map leaves out, and build creates the queue
+func Register[O any](name string, defaults func() O, build func(O) (types.SchedulingQueue, error)) error {
+	name = normalizePolicyName(name)
+	if name == "" {
+		return fmt.Errorf("policy name cannot be empty")
+	}
+	if defaults == nil || build == nil {
+		return fmt.Errorf("policy %q needs defaults and a build function", name)
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if _, exists := registry.policies[name]; exists {
+		return fmt.Errorf("policy %q is already registered", name)
+	}
+
+	registry.policies[name] = policy{
+		defaults: func() any {
+			options := defaults()
+			return &options
+		},
+		build: func(options any) (types.SchedulingQueue, error) {
+			return build(*options.(*O))
+		},
+	}
+	return nil
+}
+
+// New creates a queue of the named policy. The config map overrides the
+// default options of the policy, see decodeOptions for how keys and values
+// are matched
+func New(name string, config map[string]any) (types.SchedulingQueue, error) {
+	p, err := lookupPolicy(name)
+	if err != nil {
+		return nil, err
+	}
+
+	options := p.defaults()
+	if err := decodeOptions(options, config); err != nil {
+		return nil, fmt.Errorf("policy %q: %w", normalizePolicyName(name), err)
+	}
+
+	q, err := p.build(options)
+	if err != nil {
+		return nil, fmt.Errorf("policy %q: %w", normalizePolicyName(name), err)
+	}
+	return q, nil
+}
+
+// DefaultOptions returns the default options of the named policy, so callers
+// can show what can be configured
+func DefaultOptions(name string) (any, error) {
+	p, err := lookupPolicy(name)
+	if err != nil {
+		return nil, err
+	}
+	return p.defaults(), nil
+}
+
+// Policies returns the names of all registered policies in alphabetical order
+func Policies() []string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	names := make([]string, 0, len(registry.policies))
+	for name := range registry.policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookupPolicy(name string) (policy, error) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	p, ok := registry.policies[normalizePolicyName(name)]
+	if !ok {
+		return policy{}, fmt.Errorf("unknown policy %q", name)
+	}
+	return p, nil
+}
+
+func normalizePolicyName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// mustRegister registers a built-in policy, which can only fail on a
+// programming error
+func mustRegister[O any](name string, defaults func() O, build func(O) (types.SchedulingQueue, error)) {
+	if err := Register(name, defaults, build); err != nil {
+		panic(err)
+	}
+}
+
+// Options of the built-in policies that have no config struct of their own
+
+// RoundRobinOptions configures the "rr" policy
+type RoundRobinOptions struct {
+	Quantum time.Duration
+}
+
+// EDFOptions configures the "edf" policy
+type EDFOptions struct {
+	Admission bool
+}
+
+// MLQClassOptions configures one class of the "mlq" policy. Its queue is
+// created by New from Policy and Options
+type MLQClassOptions struct {
+	Class   types.ProcessClass
+	Policy  string
+	Options map[string]any
+	Share   float64
+}
+
+// MLQOptions configures the "mlq" policy
+type MLQOptions struct {
+	Classes    []MLQClassOptions
+	Selection  MLQSelection
+	ClassSlice time.Duration
+}
+
+func init() {
+	mustRegister("fcfs", func() struct{} { return struct{}{} }, func(struct{}) (types.SchedulingQueue, error) {
+		return NewFCFSQueue(), nil
+	})
+	mustRegister("rr", func() RoundRobinOptions {
+		return RoundRobinOptions{Quantum: 100 * time.Millisecond}
+	}, func(o RoundRobinOptions) (types.SchedulingQueue, error) {
+		return NewRoundRobinQueue(o.Quantum), nil
+	})
+
+	mustRegister("priority", func() AgingPolicy { return AgingPolicy{} }, func(o AgingPolicy) (types.SchedulingQueue, error) {
+		return NewPriorityQueue(o), nil
+	})
+	mustRegister("priority-preemptive", func() AgingPolicy { return AgingPolicy{} }, func(o AgingPolicy) (types.SchedulingQueue, error) {
+		return NewPreemptivePriorityQueue(o), nil
+	})
+
+	defaultPredictor := func() BurstPredictor { return NewBurstPredictor(defaultBurstAlpha, defaultInitialBurst) }
+	mustRegister("sjf", defaultPredictor, func(o BurstPredictor) (types.SchedulingQueue, error) {
+		return NewSJFQueue(o), nil
+	})
+	mustRegister("srtf", defaultPredictor, func(o BurstPredictor) (types.SchedulingQueue, error) {
+		return NewSRTFQueue(o), nil
+	})
+	mustRegister("hrrn", defaultPredictor, func(o BurstPredictor) (types.SchedulingQueue, error) {
+		return NewHRRNQueue(o), nil
+	})
+
+	mustRegister("mlq", func() MLQOptions {
+		return MLQOptions{
+			Classes: []MLQClassOptions{
+				{Class: types.SYSTEM, Policy: "fcfs", Share: 1},
+				{Class: types.INTERACTIVE, Policy: "rr", Options: map[string]any{"quantum": "10ms"}, Share: 1},
+				{Class: types.BATCH, Policy: "fcfs", Share: 1},
+				{Class: types.BACKGROUND, Policy: "fcfs", Share: 1},
+			},
+			Selection: MLQStrictPriority,
+		}
+	}, func(o MLQOptions) (types.SchedulingQueue, error) {
+		config := MLQConfig{Selection: o.Selection, ClassSlice: o.ClassSlice}
+		for _, class := range o.Classes {
+			child, err := New(class.Policy, class.Options)
+			if err != nil {
+				return nil, fmt.Errorf("class %d: %w", class.Class, err)
+			}
+			config.Classes = append(config.Classes, MLQClass{Class: class.Class, Queue: child, Share: class.Share})
+		}
+		q, err := NewMLQQueue(config)
+		if err != nil {
+			return nil, err
+		}
+		return q, nil
+	})
+	mustRegister("mlfq", DefaultMLFQConfig, func(o MLFQConfig) (types.SchedulingQueue, error) {
+		q, err := NewMLFQQueue(o)
+		if err != nil {
+			return nil, err
+		}
+		return q, nil
+	})
+
+	mustRegister("cfs", DefaultCFSConfig, func(o CFSConfig) (types.SchedulingQueue, error) {
+		return NewCFSQueue(o), nil
+	})
+	mustRegister("eevdf", DefaultEEVDFConfig, func(o EEVDFConfig) (types.SchedulingQueue, error) {
+		return NewEEVDFQueue(o), nil
+	})
+
+	mustRegister("edf", func() EDFOptions { return EDFOptions{} }, func(o EDFOptions) (types.SchedulingQueue, error) {
+		if o.Admission {
+			return NewEDFQueueWithAdmission(), nil
+		}
+		return NewEDFQueue(), nil
+	})
+	mustRegister("rm", func() struct{} { return struct{}{} }, func(struct{}) (types.SchedulingQueue, error) {
+		return NewRateMonotonicQueue(), nil
+	})
+
+	mustRegister("lottery", func() LotteryConfig {
+		return LotteryConfig{Quantum: defaultShareQuantum}
+	}, func(o LotteryConfig) (types.SchedulingQueue, error) {
+		return NewLotteryQueue(o), nil
+	})
+	mustRegister("stride", func() StrideConfig {
+		return StrideConfig{Quantum: defaultShareQuantum}
+	}, func(o StrideConfig) (types.SchedulingQueue, error) {
+		return NewStrideQueue(o), nil
+	})
+	mustRegister("fairshare", func() FairShareConfig {
+		return FairShareConfig{Quantum: defaultShareQuantum}
+	}, func(o FairShareConfig) (types.SchedulingQueue, error) {
+		return NewFairShareQueue(o), nil
+	})
+}
@@ -0,0 +1,155 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/types"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("should build every built-in policy with its defaults", func(t *testing.T) {
+		for _, name := range Policies() {
+			q, err := New(name, nil)
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", name, err)
+				continue
+			}
+			if !q.IsEmpty() {
+				t.Errorf("%s: new queue should be empty", name)
+			}
+		}
+	})
+
+	t.Run("should apply the config map to the options", func(t *testing.T) {
+		q, err := New("rr", map[string]any{"quantum": "25ms"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		rr, ok := q.(*RoundRobinQueue)
+		if !ok {
+			t.Fatalf("expected a RoundRobinQueue, got %T", q)
+		}
+		if rr.GetTimeSlice(nil) != 25*time.Millisecond {
+			t.Errorf("expected 25ms quantum, got %v", rr.GetTimeSlice(nil))
+		}
+	})
+
+	t.Run("should keep defaults for options left out", func(t *testing.T) {
+		q, err := New("cfs", map[string]any{"sched_latency": "12ms"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		config := q.(*CFSQueue).config
+		if config.SchedLatency != 12*time.Millisecond || config.MinGranularity != defaultMinGranularity {
+			t.Errorf("expected overridden latency and default granularity, got %+v", config)
+		}
+	})
+
+	t.Run("should build nested policies", func(t *testing.T) {
+		q, err := New("MLQ", map[string]any{
+			"selection":   "sliced",
+			"class_slice": "5ms",
+			"classes": []any{
+				map[string]any{"class": "interactive", "policy": "rr", "options": map[string]any{"quantum": "2ms"}, "share": 3.0},
+				map[string]any{"class": "batch", "policy": "sjf", "share": 1},
+			},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		metrics := q.(*MLQQueue).GetMLQMetrics()
+		if len(metrics.Classes) != 2 || metrics.Classes[1].Class != types.BATCH {
+			t.Errorf("expected interactive and batch classes, got %+v", metrics.Classes)
+		}
+	})
+
+	t.Run("should return error for an unknown policy", func(t *testing.T) {
+		if _, err := New("nope", nil); err == nil {
+			t.Error("expected error for unknown policy")
+		}
+	})
+
+	t.Run("should return error for an unknown option", func(t *testing.T) {
+		if _, err := New("rr", map[string]any{"quantom": "10ms"}); err == nil {
+			t.Error("expected error for unknown option")
+		}
+	})
+
+	t.Run("should return error for a value of the wrong type", func(t *testing.T) {
+		if _, err := New("sjf", map[string]any{"alpha": "high"}); err == nil {
+			t.Error("expected error for a string alpha")
+		}
+		if _, err := New("lottery", map[string]any{"seed": 1.5}); err == nil {
+			t.Error("expected error for a fractional seed")
+		}
+	})
+
+	t.Run("should return error when the policy rejects its options", func(t *testing.T) {
+		if _, err := New("mlfq", map[string]any{"levels": []any{}}); err == nil {
+			t.Error("expected error for MLFQ without levels")
+		}
+	})
+}
+
+// unregister removes a policy registered by a test, so that it neither leaks
+// into Policies nor blocks registering it again on the next run
+func unregister(name string) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	delete(registry.policies, normalizePolicyName(name))
+}
+
+func TestRegister(t *testing.T) {
+	type fixedOptions struct {
+		Size int
+	}
+
+	t.Run("should make a third party policy available by name", func(t *testing.T) {
+		var built fixedOptions
+		t.Cleanup(func() { unregister("test-fixed") })
+		err := Register("test-fixed", func() fixedOptions { return fixedOptions{Size: 1} },
+			func(o fixedOptions) (types.SchedulingQueue, error) {
+				built = o
+				return NewFCFSQueue(), nil
+			})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, err := New("test-fixed", map[string]any{"size": 4}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if built.Size != 4 {
+			t.Errorf("expected typed options with size 4, got %+v", built)
+		}
+
+		defaults, err := DefaultOptions("test-fixed")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if o, ok := defaults.(*fixedOptions); !ok || o.Size != 1 {
+			t.Errorf("expected default options with size 1, got %#v", defaults)
+		}
+	})
+
+	t.Run("should not leak test policies", func(t *testing.T) {
+		for _, name := range Policies() {
+			if name == "test-fixed" {
+				t.Error("expected the test policy to be unregistered")
+			}
+		}
+	})
+
+	t.Run("should return error for a duplicate name", func(t *testing.T) {
+		err := Register("fcfs", func() struct{} { return struct{}{} }, func(struct{}) (types.SchedulingQueue, error) {
+			return NewFCFSQueue(), nil
+		})
+		if err == nil {
+			t.Error("expected error for duplicate policy")
+		}
+	})
+}
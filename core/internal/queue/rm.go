@@ -0,0 +1,185 @@
+package queue
+
+import (
+	"container/heap"
+	"cpu-scheduling/core/internal/types"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// RMMetrics extends the scheduling metrics with deadline statistics
+type RMMetrics struct {
+	types.SchedulingMetrics
+	DeadlineMetrics
+}
+
+// RateMonotonicQueue schedules periodic processes by fixed priorities derived
+// from their period, the shorter the period the higher the priority. An
+// arriving job of a shorter period process preempts the running one.
+// Processes without a period only run when no periodic job is ready
+type RateMonotonicQueue struct {
+	processes burstHeap
+	nextSeq   uint64
+	mu        sync.RWMutex
+
+	metrics   metricsTracker
+	clock     types.Clock
+	deadlines deadlineTracker
+}
+
+func NewRateMonotonicQueue() *RateMonotonicQueue {
+	return &RateMonotonicQueue{
+		processes: make(burstHeap, 0),
+		metrics:   newMetricsTracker(),
+		clock:     types.RealClock{},
+	}
+}
+
+func (q *RateMonotonicQueue) Enqueue(p types.Process) error {
+	if p == nil {
+		return fmt.Errorf("cannot enqueue nil process")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	heap.Push(&q.processes, &burstEntry{
+		process: p,
+		key:     rmPriority(p),
+		seq:     q.nextSeq,
+	})
+	q.nextSeq++
+	return nil
+}
+
+func (q *RateMonotonicQueue) Dequeue() (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.processes) == 0 {
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	entry := heap.Pop(&q.processes).(*burstEntry)
+	q.metrics.record(entry.process)
+	return entry.process, nil
+}
+
+func (q *RateMonotonicQueue) Peek() (types.Process, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if len(q.processes) == 0 {
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	return q.processes[0].process, nil
+}
+
+func (q *RateMonotonicQueue) IsEmpty() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return len(q.processes) == 0
+}
+
+func (q *RateMonotonicQueue) Size() int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return len(q.processes)
+}
+
+func (q *RateMonotonicQueue) GetMetrics() types.SchedulingMetrics {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.metrics.snapshot()
+}
+
+func (q *RateMonotonicQueue) SetClock(clock types.Clock) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.metrics.setClock(clock)
+	q.clock = clock
+}
+
+// DequeueMatching dequeues the process with the shortest period that match
+// accepts
+func (q *RateMonotonicQueue) DequeueMatching(match func(types.Process) bool) (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := firstMatching(len(q.processes), q.processes.Less, func(i int) bool {
+		return match(q.processes[i].process)
+	})
+	if i < 0 {
+		return nil, fmt.Errorf("no queued process matches")
+	}
+
+	entry := heap.Remove(&q.processes, i).(*burstEntry)
+	q.metrics.record(entry.process)
+	return entry.process, nil
+}
+
+// Remove takes the process off the queue without counting it as dispatched
+func (q *RateMonotonicQueue) Remove(p types.Process) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := indexOf(q.processes, p, func(e *burstEntry) types.Process { return e.process })
+	if i < 0 {
+		return fmt.Errorf("process %d is not queued", p.GetPID())
+	}
+
+	heap.Remove(&q.processes, i)
+	return nil
+}
+
+// Rate Monotonic specific methods
+
+// GetRMMetrics returns the scheduling metrics along with deadline misses,
+// lateness and tardiness of the finished jobs
+func (q *RateMonotonicQueue) GetRMMetrics() RMMetrics {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return RMMetrics{
+		SchedulingMetrics: q.metrics.snapshot(),
+		DeadlineMetrics:   q.deadlines.snapshot(),
+	}
+}
+
+// ShouldPreempt reports whether the arrived process has a shorter period than
+// the running one
+func (q *RateMonotonicQueue) ShouldPreempt(running, arrived types.Process) bool {
+	if running == nil || arrived == nil {
+		return false
+	}
+	return rmPriority(arrived) < rmPriority(running)
+}
+
+// RecordSlice accounts for the deadline of every finished job, counting failed
+// ones apart
+func (q *RateMonotonicQueue) RecordSlice(p types.Process, ran time.Duration, outcome types.SliceOutcome) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	switch outcome {
+	case types.SliceCompleted, types.SliceJobCompleted:
+		q.deadlines.record(p, q.clock.Now())
+	case types.SliceFailed:
+		q.deadlines.fail(p)
+	}
+}
+
+// rmPriority is the period of the process, placing aperiodic processes last
+func rmPriority(p types.Process) time.Duration {
+	if period := p.GetPeriod(); period > 0 {
+		return period
+	}
+	return math.MaxInt64
+}
@@ -0,0 +1,63 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/process"
+	"cpu-scheduling/core/internal/types"
+	"testing"
+	"time"
+)
+
+func TestRateMonotonicQueue_Dequeue(t *testing.T) {
+	t.Run("should run the shortest period first", func(t *testing.T) {
+		queue := NewRateMonotonicQueue()
+		queue.Enqueue(process.NewPCB(1, process.NewTask(func() (any, error) { return nil, nil })))
+		queue.Enqueue(newRealtimePCB(2, 50*time.Millisecond, time.Millisecond))
+		queue.Enqueue(newRealtimePCB(3, 10*time.Millisecond, time.Millisecond))
+
+		for _, expected := range []int{3, 2, 1} {
+			p, err := queue.Dequeue()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.GetPID() != expected {
+				t.Errorf("expected PID %d, got %d", expected, p.GetPID())
+			}
+		}
+	})
+
+	t.Run("should return error when empty", func(t *testing.T) {
+		if _, err := NewRateMonotonicQueue().Dequeue(); err == nil {
+			t.Error("expected error when dequeuing from empty queue")
+		}
+	})
+}
+
+func TestRateMonotonicQueue_ShouldPreempt(t *testing.T) {
+	queue := NewRateMonotonicQueue()
+	fast := newRealtimePCB(1, 10*time.Millisecond, time.Millisecond)
+	slow := newRealtimePCB(2, 50*time.Millisecond, time.Millisecond)
+
+	if !queue.ShouldPreempt(slow, fast) {
+		t.Error("expected shorter period to preempt")
+	}
+	if queue.ShouldPreempt(fast, slow) {
+		t.Error("expected longer period not to preempt")
+	}
+}
+
+func TestRateMonotonicQueue_GetRMMetrics(t *testing.T) {
+	t.Run("should count every finished job", func(t *testing.T) {
+		queue := NewRateMonotonicQueue()
+		p := newRealtimePCB(1, time.Hour, time.Millisecond)
+		queue.Enqueue(p)
+		queue.Dequeue()
+
+		queue.RecordSlice(p, time.Millisecond, types.SliceJobCompleted)
+		queue.RecordSlice(p, time.Millisecond, types.SliceCompleted)
+
+		metrics := queue.GetRMMetrics()
+		if metrics.Jobs != 2 || metrics.Misses != 0 {
+			t.Errorf("expected 2 jobs without misses, got %+v", metrics.DeadlineMetrics)
+		}
+	})
+}
@@ -20,6 +20,7 @@ type RoundRobinQueue struct {
 	totalTurnaround time.Duration
 	processedCount  int
 	startTime       time.Time
+	clock           types.Clock
 }
 
 func NewRoundRobinQueue(timeQuantum time.Duration) *RoundRobinQueue {
@@ -32,6 +33,7 @@ func NewRoundRobinQueue(timeQuantum time.Duration) *RoundRobinQueue {
 		timeQuantum:  timeQuantum,
 		currentIndex: 0,
 		startTime:    time.Now(),
+		clock:        types.RealClock{},
 	}
 }
 
@@ -55,24 +57,14 @@ func (q *RoundRobinQueue) Dequeue() (types.Process, error) {
 		return nil, fmt.Errorf("queue is empty")
 	}
 
-	// Get current process
-	p := q.processes[q.currentIndex]
-
-	// Remove it from the queue
-	q.processes = append(q.processes[:q.currentIndex], q.processes[q.currentIndex+1:]...)
+	// Get current process and remove it from the queue
+	p := q.take(q.currentIndex)
 
 	// Update metrics
 	q.processedCount++
 	q.totalWaitTime += p.GetTimeInState()
 	q.totalTurnaround += p.GetTotalTime()
 
-	// Adjust current index if necessary
-	if len(q.processes) > 0 {
-		q.currentIndex = q.currentIndex % len(q.processes)
-	} else {
-		q.currentIndex = 0
-	}
-
 	return p, nil
 }
 
@@ -114,7 +106,7 @@ func (q *RoundRobinQueue) GetMetrics() types.SchedulingMetrics {
 	avgTurnaround := q.totalTurnaround / time.Duration(q.processedCount)
 
 	// Calculate throughput (processes per minute)
-	elapsedMinutes := time.Since(q.startTime).Minutes()
+	elapsedMinutes := q.clock.Since(q.startTime).Minutes()
 	var throughput float64
 	if elapsedMinutes > 0 {
 		throughput = float64(q.processedCount) / elapsedMinutes
@@ -127,12 +119,82 @@ func (q *RoundRobinQueue) GetMetrics() types.SchedulingMetrics {
 	}
 }
 
+func (q *RoundRobinQueue) SetClock(clock types.Clock) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.clock = clock
+	q.startTime = clock.Now()
+	q.totalWaitTime = 0
+	q.totalTurnaround = 0
+	q.processedCount = 0
+}
+
+// DequeueMatching dequeues the first process match accepts, going round from
+// the current process
+func (q *RoundRobinQueue) DequeueMatching(match func(types.Process) bool) (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for n := 0; n < len(q.processes); n++ {
+		i := (q.currentIndex + n) % len(q.processes)
+		if !match(q.processes[i]) {
+			continue
+		}
+		p := q.take(i)
+
+		q.processedCount++
+		q.totalWaitTime += p.GetTimeInState()
+		q.totalTurnaround += p.GetTotalTime()
+		return p, nil
+	}
+
+	return nil, fmt.Errorf("no queued process matches")
+}
+
+// Remove takes the process off the queue without counting it as dispatched
+func (q *RoundRobinQueue) Remove(p types.Process) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := indexOf(q.processes, p, func(p types.Process) types.Process { return p })
+	if i < 0 {
+		return fmt.Errorf("process %d is not queued", p.GetPID())
+	}
+
+	q.take(i)
+	return nil
+}
+
+// take removes the process at index i, keeping the current index on the
+// process that was current unless it is the one removed
+func (q *RoundRobinQueue) take(i int) types.Process {
+	p := q.processes[i]
+	q.processes = append(q.processes[:i], q.processes[i+1:]...)
+
+	if i < q.currentIndex {
+		q.currentIndex--
+	}
+	if len(q.processes) > 0 {
+		q.currentIndex = q.currentIndex % len(q.processes)
+	} else {
+		q.currentIndex = 0
+	}
+	return p
+}
+
 // Round Robin specific methods
 
 func (q *RoundRobinQueue) GetTimeQuantum() time.Duration {
 	return q.timeQuantum
 }
 
+// GetTimeSlice returns the quantum a dispatched process may run before it is
+// preempted, which is the same for every process
+func (q *RoundRobinQueue) GetTimeSlice(p types.Process) time.Duration {
+	return q.timeQuantum
+}
+
 func (q *RoundRobinQueue) MoveToNext() {
 	q.mu.Lock()
 	defer q.mu.Unlock()
@@ -93,6 +93,18 @@ func TestRoundRobinQueue_Dequeue(t *testing.T) {
 	})
 }
 
+func TestRoundRobinQueue_GetTimeSlice(t *testing.T) {
+	t.Run("should return the time quantum for every process", func(t *testing.T) {
+		queue := NewRoundRobinQueue(50 * time.Millisecond)
+		p1 := process.NewPCB(1, process.NewTask(func() (any, error) { return nil, nil }))
+		p2 := process.NewPCB(2, process.NewTask(func() (any, error) { return nil, nil }))
+
+		if queue.GetTimeSlice(p1) != 50*time.Millisecond || queue.GetTimeSlice(p2) != 50*time.Millisecond {
+			t.Errorf("expected time slice to equal the quantum")
+		}
+	})
+}
+
 func TestRoundRobinQueue_MoveToNext(t *testing.T) {
 	t.Run("should cycle through processes", func(t *testing.T) {
 		queue := NewRoundRobinQueue(100 * time.Millisecond)
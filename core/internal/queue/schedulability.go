@@ -0,0 +1,117 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/types"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// RMTaskAnalysis is the result of response time analysis for a single task.
+// ResponseTime is the worst case time from the release of a job to its
+// completion. For an unschedulable task it is the first estimate that
+// exceeded the deadline
+type RMTaskAnalysis struct {
+	PID          int
+	Period       time.Duration
+	Deadline     time.Duration
+	WCET         time.Duration
+	ResponseTime time.Duration
+	Schedulable  bool
+}
+
+// RMAnalysis is the result of analysing a periodic task set under Rate
+// Monotonic scheduling. Passing the Liu & Layland bound is sufficient but not
+// necessary for the set to be schedulable, the exact answer comes from
+// response time analysis. The bound only holds when every deadline equals its
+// period, so no set with other deadlines passes it. Tasks are listed from the
+// highest priority to the lowest
+type RMAnalysis struct {
+	Utilization           float64
+	UtilizationBound      float64
+	PassesUtilizationTest bool
+	Schedulable           bool
+	Tasks                 []RMTaskAnalysis
+}
+
+// LiuLaylandBound returns n(2^(1/n) - 1), the utilization up to which any set
+// of n periodic tasks with deadlines equal to their periods is schedulable
+// under Rate Monotonic
+func LiuLaylandBound(n int) float64 {
+	if n <= 0 {
+		return 1
+	}
+	return float64(n) * (math.Pow(2, 1/float64(n)) - 1)
+}
+
+// AnalyzeRateMonotonic checks whether the periodic processes can meet their
+// deadlines under Rate Monotonic scheduling on one CPU, before anything runs.
+// Every process needs a period and a WCET
+func AnalyzeRateMonotonic(processes []types.Process) (RMAnalysis, error) {
+	tasks := make([]RMTaskAnalysis, 0, len(processes))
+	for _, p := range processes {
+		if p == nil {
+			return RMAnalysis{}, fmt.Errorf("cannot analyze nil process")
+		}
+		if p.GetPeriod() <= 0 || p.GetWCET() <= 0 {
+			return RMAnalysis{}, fmt.Errorf("process %d needs a period and a WCET", p.GetPID())
+		}
+
+		tasks = append(tasks, RMTaskAnalysis{
+			PID:      p.GetPID(),
+			Period:   p.GetPeriod(),
+			Deadline: p.GetRelativeDeadline(),
+			WCET:     p.GetWCET(),
+		})
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].Period != tasks[j].Period {
+			return tasks[i].Period < tasks[j].Period
+		}
+		return tasks[i].PID < tasks[j].PID
+	})
+
+	analysis := RMAnalysis{
+		Utilization:      Utilization(processes...),
+		UtilizationBound: LiuLaylandBound(len(tasks)),
+		Schedulable:      true,
+		Tasks:            tasks,
+	}
+	implicit := true
+	for _, task := range tasks {
+		implicit = implicit && task.Deadline == task.Period
+	}
+	analysis.PassesUtilizationTest = implicit && analysis.Utilization <= analysis.UtilizationBound
+
+	for i := range tasks {
+		tasks[i].ResponseTime, tasks[i].Schedulable = responseTime(tasks[:i], tasks[i])
+		if !tasks[i].Schedulable {
+			analysis.Schedulable = false
+		}
+	}
+
+	return analysis, nil
+}
+
+// responseTime iterates R = C + sum(ceil(R / T_j) * C_j) over the higher
+// priority tasks until it converges or exceeds the deadline of the task
+func responseTime(higher []RMTaskAnalysis, task RMTaskAnalysis) (time.Duration, bool) {
+	r := task.WCET
+	for {
+		next := task.WCET
+		for _, hp := range higher {
+			releases := (int64(r) + int64(hp.Period) - 1) / int64(hp.Period)
+			next += time.Duration(releases) * hp.WCET
+		}
+
+		if next > task.Deadline {
+			return next, false
+		}
+		if next == r {
+			return r, true
+		}
+		r = next
+	}
+}
@@ -0,0 +1,110 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/process"
+	"cpu-scheduling/core/internal/types"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestLiuLaylandBound(t *testing.T) {
+	tests := []struct {
+		n     int
+		bound float64
+	}{
+		{1, 1},
+		{2, 0.8284},
+		{3, 0.7798},
+		{1000, math.Ln2},
+	}
+
+	for _, tt := range tests {
+		if got := LiuLaylandBound(tt.n); math.Abs(got-tt.bound) > 1e-3 {
+			t.Errorf("LiuLaylandBound(%d) = %.4f, expected %.4f", tt.n, got, tt.bound)
+		}
+	}
+}
+
+func TestAnalyzeRateMonotonic(t *testing.T) {
+	t.Run("should find a task set schedulable that fails the utilization bound", func(t *testing.T) {
+		analysis, err := AnalyzeRateMonotonic([]types.Process{
+			newRealtimePCB(3, 20*time.Millisecond, 5*time.Millisecond),
+			newRealtimePCB(1, 4*time.Millisecond, time.Millisecond),
+			newRealtimePCB(2, 5*time.Millisecond, 2*time.Millisecond),
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if analysis.PassesUtilizationTest {
+			t.Errorf("expected utilization %.2f to exceed the bound %.2f",
+				analysis.Utilization, analysis.UtilizationBound)
+		}
+		if !analysis.Schedulable {
+			t.Error("expected response time analysis to find the set schedulable")
+		}
+
+		expected := map[int]time.Duration{
+			1: time.Millisecond,
+			2: 3 * time.Millisecond,
+			3: 15 * time.Millisecond,
+		}
+		for i, task := range analysis.Tasks {
+			if task.PID != i+1 {
+				t.Errorf("expected tasks in priority order, got PID %d at %d", task.PID, i)
+			}
+			if task.ResponseTime != expected[task.PID] {
+				t.Errorf("expected response time %v for PID %d, got %v",
+					expected[task.PID], task.PID, task.ResponseTime)
+			}
+		}
+	})
+
+	t.Run("should report the task that misses its deadline", func(t *testing.T) {
+		analysis, err := AnalyzeRateMonotonic([]types.Process{
+			newRealtimePCB(1, 4*time.Millisecond, 2*time.Millisecond),
+			newRealtimePCB(2, 6*time.Millisecond, 3*time.Millisecond),
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if analysis.Schedulable {
+			t.Error("expected task set to be unschedulable")
+		}
+		if !analysis.Tasks[0].Schedulable || analysis.Tasks[1].Schedulable {
+			t.Errorf("expected only the second task to miss, got %+v", analysis.Tasks)
+		}
+		if analysis.Tasks[1].ResponseTime <= analysis.Tasks[1].Deadline {
+			t.Errorf("expected response time beyond the deadline, got %v", analysis.Tasks[1].ResponseTime)
+		}
+	})
+
+	t.Run("should not apply the utilization bound to deadlines shorter than periods", func(t *testing.T) {
+		constrained := newRealtimePCB(2, 20*time.Millisecond, 3*time.Millisecond)
+		constrained.SetRelativeDeadline(4 * time.Millisecond)
+		analysis, err := AnalyzeRateMonotonic([]types.Process{
+			newRealtimePCB(1, 5*time.Millisecond, 2*time.Millisecond),
+			constrained,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if analysis.Utilization > analysis.UtilizationBound {
+			t.Fatalf("expected utilization %.3f under the bound %.3f", analysis.Utilization, analysis.UtilizationBound)
+		}
+		if analysis.PassesUtilizationTest || analysis.Schedulable {
+			t.Errorf("expected the set to fail response time analysis only, got %+v", analysis)
+		}
+	})
+
+	t.Run("should return error for tasks without period or WCET", func(t *testing.T) {
+		p := process.NewPCB(1, process.NewTask(func() (any, error) { return nil, nil }))
+
+		if _, err := AnalyzeRateMonotonic([]types.Process{p}); err == nil {
+			t.Error("expected error for aperiodic task")
+		}
+	})
+}
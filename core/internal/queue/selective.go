@@ -0,0 +1,56 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/types"
+	"fmt"
+)
+
+// indexOf returns the index of the entry holding the process, or -1 if there
+// is none
+func indexOf[E any](entries []E, p types.Process, process func(E) types.Process) int {
+	for i, entry := range entries {
+		if process(entry).GetPID() == p.GetPID() {
+			return i
+		}
+	}
+	return -1
+}
+
+// firstMatching returns the index of the entry that comes first by less among
+// the n entries accepted by match, or -1 if match accepts none. Heaps only
+// keep their minimum in place, so every entry is looked at
+func firstMatching(n int, less func(i, j int) bool, match func(i int) bool) int {
+	best := -1
+	for i := 0; i < n; i++ {
+		if match(i) && (best < 0 || less(i, best)) {
+			best = i
+		}
+	}
+	return best
+}
+
+// dequeueMatching dequeues the first process match accepts from a child
+// queue. Queues that cannot skip processes only hand out their head
+func dequeueMatching(q types.SchedulingQueue, match func(types.Process) bool) (types.Process, error) {
+	if selective, ok := q.(types.SelectiveQueue); ok {
+		return selective.DequeueMatching(match)
+	}
+
+	head, err := q.Peek()
+	if err != nil {
+		return nil, err
+	}
+	if !match(head) {
+		return nil, fmt.Errorf("no queued process matches")
+	}
+	return q.Dequeue()
+}
+
+// remove takes a process off a child queue without dispatching it
+func remove(q types.SchedulingQueue, p types.Process) error {
+	selective, ok := q.(types.SelectiveQueue)
+	if !ok {
+		return fmt.Errorf("queue cannot remove process %d", p.GetPID())
+	}
+	return selective.Remove(p)
+}
@@ -0,0 +1,124 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/process"
+	"cpu-scheduling/core/internal/types"
+	"reflect"
+	"testing"
+)
+
+// newSelectiveQueues builds every built-in policy with its defaults, each
+// holding processes 1 to 3
+func newSelectiveQueues(t *testing.T) map[string]types.SelectiveQueue {
+	t.Helper()
+
+	queues := make(map[string]types.SelectiveQueue)
+	for _, name := range Policies() {
+		q, err := New(name, nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		selective, ok := q.(types.SelectiveQueue)
+		if !ok {
+			t.Fatalf("%s: expected a SelectiveQueue, got %T", name, q)
+		}
+		for pid := 1; pid <= 3; pid++ {
+			if err := q.Enqueue(process.NewPCB(pid, process.NewTask(func() (any, error) { return nil, nil }))); err != nil {
+				t.Fatalf("%s: unexpected error: %v", name, err)
+			}
+		}
+		queues[name] = selective
+	}
+	return queues
+}
+
+func hasPID(pid int) func(types.Process) bool {
+	return func(p types.Process) bool { return p.GetPID() == pid }
+}
+
+func TestSelectiveQueue_DequeueMatching(t *testing.T) {
+	t.Run("should dequeue the process match accepts in every policy", func(t *testing.T) {
+		for name, q := range newSelectiveQueues(t) {
+			p, err := q.DequeueMatching(hasPID(2))
+			if err != nil || p.GetPID() != 2 {
+				t.Errorf("%s: expected process 2, got %v, %v", name, p, err)
+				continue
+			}
+			if q.Size() != 2 {
+				t.Errorf("%s: expected the others to stay queued, got size %d", name, q.Size())
+			}
+		}
+	})
+
+	t.Run("should return error and leave the queue alone when nothing matches", func(t *testing.T) {
+		for name, q := range newSelectiveQueues(t) {
+			if _, err := q.DequeueMatching(hasPID(4)); err == nil {
+				t.Errorf("%s: expected error", name)
+			}
+			if q.Size() != 3 || q.GetMetrics() != (types.SchedulingMetrics{}) {
+				t.Errorf("%s: expected three processes and no dispatches, got size %d and %+v", name, q.Size(), q.GetMetrics())
+			}
+		}
+	})
+
+	t.Run("should keep skipped processes in their place", func(t *testing.T) {
+		queue := NewFCFSQueue()
+		for pid := 1; pid <= 3; pid++ {
+			queue.Enqueue(process.NewPCB(pid, process.NewTask(func() (any, error) { return nil, nil })))
+		}
+
+		p, _ := queue.DequeueMatching(func(p types.Process) bool { return p.GetPID() != 1 })
+		order := []int{p.GetPID()}
+		for !queue.IsEmpty() {
+			p, _ := queue.Dequeue()
+			order = append(order, p.GetPID())
+		}
+		if !reflect.DeepEqual(order, []int{2, 1, 3}) {
+			t.Errorf("expected order [2 1 3], got %v", order)
+		}
+	})
+
+	t.Run("should go round from the current process", func(t *testing.T) {
+		queue := NewRoundRobinQueue(0)
+		for pid := 1; pid <= 3; pid++ {
+			queue.Enqueue(process.NewPCB(pid, process.NewTask(func() (any, error) { return nil, nil })))
+		}
+		queue.MoveToNext()
+
+		p, _ := queue.DequeueMatching(func(p types.Process) bool { return p.GetPID() != 2 })
+		if p.GetPID() != 3 {
+			t.Errorf("expected process 3, got %d", p.GetPID())
+		}
+		if head, _ := queue.Peek(); head.GetPID() != 2 {
+			t.Errorf("expected process 2 to stay current, got %d", head.GetPID())
+		}
+	})
+}
+
+func TestSelectiveQueue_Remove(t *testing.T) {
+	t.Run("should take the process off without dispatching it in every policy", func(t *testing.T) {
+		for name, q := range newSelectiveQueues(t) {
+			p := process.NewPCB(2, nil)
+			if err := q.Remove(p); err != nil {
+				t.Errorf("%s: unexpected error: %v", name, err)
+				continue
+			}
+			if q.Size() != 2 || q.GetMetrics() != (types.SchedulingMetrics{}) {
+				t.Errorf("%s: expected two processes and no dispatches, got size %d and %+v", name, q.Size(), q.GetMetrics())
+			}
+			if err := q.Remove(p); err == nil {
+				t.Errorf("%s: expected error removing a process that is not queued", name)
+			}
+
+			for !q.IsEmpty() {
+				next, err := q.Dequeue()
+				if err != nil {
+					t.Fatalf("%s: unexpected error: %v", name, err)
+				}
+				if next.GetPID() == 2 {
+					t.Errorf("%s: expected the removed process not to be dispatched", name)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,256 @@
+package queue
+
+import (
+	"container/heap"
+	"cpu-scheduling/core/internal/types"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBurstAlpha   = 0.5
+	defaultInitialBurst = 100 * time.Millisecond
+)
+
+// BurstPredictor estimates the next CPU burst of a process by exponential
+// averaging of its previous bursts: tau_{n+1} = alpha*t_n + (1-alpha)*tau_n.
+// The first estimate tau_0 is the burst declared on the process, or
+// InitialEstimate when none was declared. An Alpha of 0 ignores history and
+// always uses the declared burst
+type BurstPredictor struct {
+	Alpha           float64
+	InitialEstimate time.Duration
+}
+
+// NewBurstPredictor creates a predictor, falling back to defaults for an alpha
+// outside [0, 1] or a non-positive initial estimate
+func NewBurstPredictor(alpha float64, initialEstimate time.Duration) BurstPredictor {
+	if alpha < 0 || alpha > 1 {
+		alpha = defaultBurstAlpha
+	}
+	if initialEstimate <= 0 {
+		initialEstimate = defaultInitialBurst
+	}
+
+	return BurstPredictor{
+		Alpha:           alpha,
+		InitialEstimate: initialEstimate,
+	}
+}
+
+// Estimate predicts the length of the next CPU burst of the process
+func (b BurstPredictor) Estimate(p types.Process) time.Duration {
+	tau := float64(p.GetExpectedBurst())
+	if tau <= 0 {
+		tau = float64(b.InitialEstimate)
+	}
+
+	for _, burst := range p.GetBurstHistory() {
+		tau = b.Alpha*float64(burst) + (1-b.Alpha)*tau
+	}
+
+	return time.Duration(tau)
+}
+
+// burstEntry is a queued process together with the key it is ordered by
+type burstEntry struct {
+	process types.Process
+	key     time.Duration
+	seq     uint64
+}
+
+// burstHeap is a min-heap of processes by key, breaking ties in arrival order
+type burstHeap []*burstEntry
+
+func (h burstHeap) Len() int { return len(h) }
+
+func (h burstHeap) Less(i, j int) bool {
+	if h[i].key != h[j].key {
+		return h[i].key < h[j].key
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h burstHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *burstHeap) Push(x any) { *h = append(*h, x.(*burstEntry)) }
+
+func (h *burstHeap) Pop() any {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return entry
+}
+
+// SJFQueue is a Shortest Job First queue. Processes are ordered by their
+// predicted next CPU burst, computed when they are enqueued. In the preemptive
+// Shortest Remaining Time First mode they are ordered by what is left of that
+// prediction, and an arriving process with less remaining time than the
+// running one preempts it
+type SJFQueue struct {
+	processes  burstHeap
+	predictor  BurstPredictor
+	preemptive bool
+	nextSeq    uint64
+	mu         sync.RWMutex
+
+	metrics metricsTracker
+}
+
+func NewSJFQueue(predictor BurstPredictor) *SJFQueue {
+	return &SJFQueue{
+		processes: make(burstHeap, 0),
+		predictor: NewBurstPredictor(predictor.Alpha, predictor.InitialEstimate),
+		metrics:   newMetricsTracker(),
+	}
+}
+
+// NewSRTFQueue creates an SJFQueue in Shortest Remaining Time First mode
+func NewSRTFQueue(predictor BurstPredictor) *SJFQueue {
+	q := NewSJFQueue(predictor)
+	q.preemptive = true
+	return q
+}
+
+func (q *SJFQueue) Enqueue(p types.Process) error {
+	if p == nil {
+		return fmt.Errorf("cannot enqueue nil process")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	heap.Push(&q.processes, &burstEntry{
+		process: p,
+		key:     q.key(p),
+		seq:     q.nextSeq,
+	})
+	q.nextSeq++
+	return nil
+}
+
+func (q *SJFQueue) Dequeue() (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.processes) == 0 {
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	entry := heap.Pop(&q.processes).(*burstEntry)
+	q.metrics.record(entry.process)
+
+	return entry.process, nil
+}
+
+func (q *SJFQueue) Peek() (types.Process, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if len(q.processes) == 0 {
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	return q.processes[0].process, nil
+}
+
+func (q *SJFQueue) IsEmpty() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return len(q.processes) == 0
+}
+
+func (q *SJFQueue) Size() int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return len(q.processes)
+}
+
+func (q *SJFQueue) GetMetrics() types.SchedulingMetrics {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.metrics.snapshot()
+}
+
+func (q *SJFQueue) SetClock(clock types.Clock) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.metrics.setClock(clock)
+}
+
+// DequeueMatching dequeues the process with the shortest predicted burst that
+// match accepts
+func (q *SJFQueue) DequeueMatching(match func(types.Process) bool) (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := firstMatching(len(q.processes), q.processes.Less, func(i int) bool {
+		return match(q.processes[i].process)
+	})
+	if i < 0 {
+		return nil, fmt.Errorf("no queued process matches")
+	}
+
+	entry := heap.Remove(&q.processes, i).(*burstEntry)
+	q.metrics.record(entry.process)
+	return entry.process, nil
+}
+
+// Remove takes the process off the queue without counting it as dispatched
+func (q *SJFQueue) Remove(p types.Process) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := indexOf(q.processes, p, func(e *burstEntry) types.Process { return e.process })
+	if i < 0 {
+		return fmt.Errorf("process %d is not queued", p.GetPID())
+	}
+
+	heap.Remove(&q.processes, i)
+	return nil
+}
+
+// SJF specific methods
+
+// GetBurstEstimate returns the predicted next CPU burst of the process
+func (q *SJFQueue) GetBurstEstimate(p types.Process) time.Duration {
+	return q.predictor.Estimate(p)
+}
+
+// GetRemainingEstimate returns the predicted CPU time left in the ongoing
+// burst of the process
+func (q *SJFQueue) GetRemainingEstimate(p types.Process) time.Duration {
+	remaining := q.predictor.Estimate(p) - p.GetCurrentBurst()
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// IsPreemptive reports whether the queue runs in SRTF mode
+func (q *SJFQueue) IsPreemptive() bool {
+	return q.preemptive
+}
+
+// ShouldPreempt reports whether the arrived process has less remaining time
+// than the running one. It is always false outside SRTF mode
+func (q *SJFQueue) ShouldPreempt(running, arrived types.Process) bool {
+	if !q.preemptive || running == nil || arrived == nil {
+		return false
+	}
+	return q.GetRemainingEstimate(arrived) < q.GetRemainingEstimate(running)
+}
+
+func (q *SJFQueue) key(p types.Process) time.Duration {
+	if q.preemptive {
+		return q.GetRemainingEstimate(p)
+	}
+	return q.predictor.Estimate(p)
+}
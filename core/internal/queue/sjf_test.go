@@ -0,0 +1,253 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/process"
+	"cpu-scheduling/core/internal/types"
+	"testing"
+	"time"
+)
+
+func newBurstPCB(pid int, burst time.Duration) *process.PCB {
+	p := process.NewPCB(pid, process.NewTask(func() (any, error) { return nil, nil }))
+	p.SetExpectedBurst(burst)
+	return p
+}
+
+// runBurst drives the process through a single CPU burst of roughly d
+func runBurst(p *process.PCB, d time.Duration) {
+	if p.GetState() == types.NEW || p.GetState() == types.WAITING {
+		p.SetState(types.READY)
+	}
+	p.SetState(types.RUNNING)
+	time.Sleep(d)
+	p.SetState(types.WAITING)
+}
+
+func TestNewBurstPredictor(t *testing.T) {
+	t.Run("should fall back to defaults for invalid values", func(t *testing.T) {
+		predictor := NewBurstPredictor(1.5, 0)
+
+		if predictor.Alpha != defaultBurstAlpha {
+			t.Errorf("expected default alpha %v, got %v", defaultBurstAlpha, predictor.Alpha)
+		}
+		if predictor.InitialEstimate != defaultInitialBurst {
+			t.Errorf("expected default initial estimate %v, got %v", defaultInitialBurst, predictor.InitialEstimate)
+		}
+	})
+}
+
+func TestBurstPredictor_Estimate(t *testing.T) {
+	t.Run("should use the declared burst without history", func(t *testing.T) {
+		predictor := NewBurstPredictor(0.5, 10*time.Millisecond)
+		p := newBurstPCB(1, 40*time.Millisecond)
+
+		if got := predictor.Estimate(p); got != 40*time.Millisecond {
+			t.Errorf("expected estimate 40ms, got %v", got)
+		}
+	})
+
+	t.Run("should use the initial estimate when nothing was declared", func(t *testing.T) {
+		predictor := NewBurstPredictor(0.5, 10*time.Millisecond)
+		p := newBurstPCB(1, 0)
+
+		if got := predictor.Estimate(p); got != 10*time.Millisecond {
+			t.Errorf("expected estimate 10ms, got %v", got)
+		}
+	})
+
+	t.Run("should average previous bursts exponentially", func(t *testing.T) {
+		predictor := NewBurstPredictor(0.5, time.Millisecond)
+		p := newBurstPCB(1, 100*time.Millisecond)
+
+		runBurst(p, 20*time.Millisecond)
+		history := p.GetBurstHistory()
+		if len(history) != 1 {
+			t.Fatalf("expected one recorded burst, got %d", len(history))
+		}
+
+		expected := time.Duration(0.5*float64(history[0]) + 0.5*float64(100*time.Millisecond))
+		if got := predictor.Estimate(p); got != expected {
+			t.Errorf("expected estimate %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("should ignore history when alpha is zero", func(t *testing.T) {
+		predictor := NewBurstPredictor(0, time.Millisecond)
+		p := newBurstPCB(1, 100*time.Millisecond)
+
+		runBurst(p, time.Millisecond)
+
+		if got := predictor.Estimate(p); got != 100*time.Millisecond {
+			t.Errorf("expected estimate 100ms, got %v", got)
+		}
+	})
+}
+
+func TestSJFQueue_Enqueue(t *testing.T) {
+	t.Run("should return error for nil process", func(t *testing.T) {
+		queue := NewSJFQueue(NewBurstPredictor(0.5, 0))
+
+		if err := queue.Enqueue(nil); err == nil {
+			t.Error("expected error for nil process")
+		}
+	})
+}
+
+func TestSJFQueue_Dequeue(t *testing.T) {
+	t.Run("should dequeue shortest expected burst first", func(t *testing.T) {
+		queue := NewSJFQueue(NewBurstPredictor(0.5, 0))
+		queue.Enqueue(newBurstPCB(1, 30*time.Millisecond))
+		queue.Enqueue(newBurstPCB(2, 10*time.Millisecond))
+		queue.Enqueue(newBurstPCB(3, 20*time.Millisecond))
+
+		for _, expected := range []int{2, 3, 1} {
+			p, err := queue.Dequeue()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.GetPID() != expected {
+				t.Errorf("expected PID %d, got %d", expected, p.GetPID())
+			}
+		}
+	})
+
+	t.Run("should keep arrival order for equal bursts", func(t *testing.T) {
+		queue := NewSJFQueue(NewBurstPredictor(0.5, 0))
+		queue.Enqueue(newBurstPCB(1, 10*time.Millisecond))
+		queue.Enqueue(newBurstPCB(2, 10*time.Millisecond))
+
+		first, _ := queue.Dequeue()
+		if first.GetPID() != 1 {
+			t.Errorf("expected PID 1, got %d", first.GetPID())
+		}
+	})
+
+	t.Run("should order by predicted burst learned from history", func(t *testing.T) {
+		queue := NewSJFQueue(NewBurstPredictor(1, 0))
+		interactive := newBurstPCB(1, 50*time.Millisecond)
+		batch := newBurstPCB(2, 20*time.Millisecond)
+
+		runBurst(interactive, time.Millisecond)
+		runBurst(batch, 15*time.Millisecond)
+		interactive.SetState(types.READY)
+		batch.SetState(types.READY)
+
+		queue.Enqueue(batch)
+		queue.Enqueue(interactive)
+
+		first, _ := queue.Dequeue()
+		if first.GetPID() != 1 {
+			t.Errorf("expected process with short observed bursts first, got PID %d", first.GetPID())
+		}
+	})
+
+	t.Run("should return error when empty", func(t *testing.T) {
+		queue := NewSJFQueue(NewBurstPredictor(0.5, 0))
+
+		if _, err := queue.Dequeue(); err == nil {
+			t.Error("expected error when dequeuing from empty queue")
+		}
+	})
+}
+
+func TestSJFQueue_Peek(t *testing.T) {
+	t.Run("should return shortest job without removing it", func(t *testing.T) {
+		queue := NewSJFQueue(NewBurstPredictor(0.5, 0))
+		queue.Enqueue(newBurstPCB(1, 30*time.Millisecond))
+		queue.Enqueue(newBurstPCB(2, 10*time.Millisecond))
+
+		p, err := queue.Peek()
+		if err != nil || p.GetPID() != 2 {
+			t.Errorf("expected PID 2, got %v (err %v)", p, err)
+		}
+		if queue.Size() != 2 {
+			t.Errorf("expected size 2, got %d", queue.Size())
+		}
+	})
+}
+
+func TestSJFQueue_GetMetrics(t *testing.T) {
+	t.Run("should return zero metrics before any dequeue", func(t *testing.T) {
+		queue := NewSJFQueue(NewBurstPredictor(0.5, 0))
+
+		if queue.GetMetrics() != (types.SchedulingMetrics{}) {
+			t.Error("expected zero metrics")
+		}
+	})
+
+	t.Run("should track wait and turnaround", func(t *testing.T) {
+		queue := NewSJFQueue(NewBurstPredictor(0.5, 0))
+		queue.Enqueue(newBurstPCB(1, 10*time.Millisecond))
+
+		time.Sleep(2 * time.Millisecond)
+		queue.Dequeue()
+
+		metrics := queue.GetMetrics()
+		if metrics.AverageTurnaround < 2*time.Millisecond {
+			t.Errorf("expected average turnaround >= 2ms, got %v", metrics.AverageTurnaround)
+		}
+		if metrics.ThroughputPerMin <= 0 {
+			t.Error("expected non-zero throughput")
+		}
+	})
+}
+
+func TestSRTFQueue_Dequeue(t *testing.T) {
+	t.Run("should order by remaining burst", func(t *testing.T) {
+		queue := NewSRTFQueue(NewBurstPredictor(0, 0))
+		started := newBurstPCB(1, 30*time.Millisecond)
+		fresh := newBurstPCB(2, 20*time.Millisecond)
+
+		// Run the first process for most of its burst, then preempt it
+		started.SetState(types.READY)
+		started.SetState(types.RUNNING)
+		time.Sleep(15 * time.Millisecond)
+		started.SetState(types.READY)
+
+		queue.Enqueue(fresh)
+		queue.Enqueue(started)
+
+		first, _ := queue.Dequeue()
+		if first.GetPID() != 1 {
+			t.Errorf("expected partially run process first, got PID %d", first.GetPID())
+		}
+	})
+}
+
+func TestSJFQueue_ShouldPreempt(t *testing.T) {
+	t.Run("should preempt when the arrival has less remaining time", func(t *testing.T) {
+		queue := NewSRTFQueue(NewBurstPredictor(0, 0))
+		running := newBurstPCB(1, 50*time.Millisecond)
+		short := newBurstPCB(2, 10*time.Millisecond)
+		long := newBurstPCB(3, 80*time.Millisecond)
+
+		if !queue.ShouldPreempt(running, short) {
+			t.Error("expected shorter arrival to preempt")
+		}
+		if queue.ShouldPreempt(running, long) {
+			t.Error("expected longer arrival not to preempt")
+		}
+	})
+
+	t.Run("should account for time already run", func(t *testing.T) {
+		queue := NewSRTFQueue(NewBurstPredictor(0, 0))
+		running := newBurstPCB(1, 20*time.Millisecond)
+		arrived := newBurstPCB(2, 15*time.Millisecond)
+
+		running.SetState(types.READY)
+		running.SetState(types.RUNNING)
+		time.Sleep(10 * time.Millisecond)
+
+		if queue.ShouldPreempt(running, arrived) {
+			t.Error("expected arrival not to preempt a nearly finished process")
+		}
+	})
+
+	t.Run("should never preempt in SJF mode", func(t *testing.T) {
+		queue := NewSJFQueue(NewBurstPredictor(0, 0))
+
+		if queue.ShouldPreempt(newBurstPCB(1, time.Second), newBurstPCB(2, time.Millisecond)) {
+			t.Error("expected non-preemptive queue not to preempt")
+		}
+	})
+}
@@ -0,0 +1,279 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/rbtree"
+	"cpu-scheduling/core/internal/types"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// stride1 is the stride of a process holding a single base ticket
+const stride1 = 1 << 20
+
+// StrideConfig configures a stride queue. Every process runs for at most
+// Quantum per turn
+type StrideConfig struct {
+	Quantum time.Duration
+}
+
+// StrideQueue is the deterministic counterpart of the LotteryQueue. Every
+// process has a stride inversely proportional to the value of its tickets and
+// a pass that advances by its stride each time it runs. The process with the
+// lowest pass runs next, so over any interval processes run in proportion to
+// their tickets with bounded error. Currencies, transfers and compensation
+// tickets work as in the LotteryQueue
+type StrideQueue struct {
+	timeline *rbtree.Tree[*ticketHolder]
+	ledger   ticketLedger
+	nextSeq  uint64
+	mu       sync.RWMutex
+
+	// globalPass is the lowest pass of the active processes and never moves
+	// backwards. Joining processes are placed relative to it
+	globalPass float64
+
+	metrics metricsTracker
+}
+
+func NewStrideQueue(config StrideConfig) *StrideQueue {
+	return &StrideQueue{
+		timeline: rbtree.New(func(a, b *ticketHolder) bool {
+			if a.pass != b.pass {
+				return a.pass < b.pass
+			}
+			return a.seq < b.seq
+		}),
+		ledger:  newTicketLedger(config.Quantum),
+		metrics: newMetricsTracker(),
+	}
+}
+
+func (q *StrideQueue) Enqueue(p types.Process) error {
+	if p == nil {
+		return fmt.Errorf("cannot enqueue nil process")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	_, known := q.ledger.holders[p.GetPID()]
+	h, err := q.ledger.holder(p)
+	if err != nil {
+		return err
+	}
+	if h.queued {
+		return fmt.Errorf("process %d is already queued", p.GetPID())
+	}
+
+	if !h.active {
+		h.active = true
+		// A new process joins one stride ahead of the global pass, a waking
+		// one keeps the distance it had when it left
+		if !known {
+			h.pass = q.stride(h, q.ledger.rates())
+		}
+		h.pass += q.globalPass
+	}
+
+	h.queued = true
+	h.seq = q.nextSeq
+	q.nextSeq++
+	h.node = q.timeline.Insert(h)
+
+	q.updateGlobalPass()
+	return nil
+}
+
+func (q *StrideQueue) Dequeue() (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	next := q.timeline.Min()
+	if next == nil {
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	h := q.take(next)
+	q.metrics.record(h.process)
+	return h.process, nil
+}
+
+func (q *StrideQueue) Peek() (types.Process, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	next := q.timeline.Min()
+	if next == nil {
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	return next.Value.process, nil
+}
+
+func (q *StrideQueue) IsEmpty() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.timeline.Len() == 0
+}
+
+func (q *StrideQueue) Size() int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.timeline.Len()
+}
+
+func (q *StrideQueue) GetMetrics() types.SchedulingMetrics {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.metrics.snapshot()
+}
+
+func (q *StrideQueue) SetClock(clock types.Clock) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.metrics.setClock(clock)
+}
+
+// DequeueMatching dequeues the process with the lowest pass that match accepts
+func (q *StrideQueue) DequeueMatching(match func(types.Process) bool) (types.Process, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for n := q.timeline.Min(); n != nil; n = q.timeline.Next(n) {
+		if match(n.Value.process) {
+			h := q.take(n)
+			q.metrics.record(h.process)
+			return h.process, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no queued process matches")
+}
+
+// Remove takes the process off the queue without counting it as dispatched.
+// It stays active until RecordSlice, like a dequeued process
+func (q *StrideQueue) Remove(p types.Process) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	h, ok := q.ledger.holders[p.GetPID()]
+	if !ok || !h.queued {
+		return fmt.Errorf("process %d is not queued", p.GetPID())
+	}
+
+	q.take(h.node)
+	return nil
+}
+
+// Stride specific methods
+
+// AddCurrency creates a currency funded with the given number of base tickets
+func (q *StrideQueue) AddCurrency(name string, funding int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.ledger.addCurrency(name, funding)
+}
+
+// TransferTickets moves tickets of the currency of from to to. Transferring
+// them back undoes the transfer
+func (q *StrideQueue) TransferTickets(from, to types.Process, tickets int) error {
+	if from == nil || to == nil {
+		return fmt.Errorf("cannot transfer tickets to or from nil process")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.ledger.transfer(from, to, tickets)
+}
+
+// GetShareMetrics returns the scheduling metrics along with the entitled and
+// actual CPU share of every known process
+func (q *StrideQueue) GetShareMetrics() ShareMetrics {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.ledger.metrics(q.metrics.snapshot())
+}
+
+// GetPass returns the pass of the process, relative to the global pass while
+// it is not active
+func (q *StrideQueue) GetPass(p types.Process) float64 {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if h, ok := q.ledger.holders[p.GetPID()]; ok {
+		return h.pass
+	}
+	return 0
+}
+
+// GetTimeSlice returns the quantum a process may run for per turn
+func (q *StrideQueue) GetTimeSlice(p types.Process) time.Duration {
+	return q.ledger.quantum
+}
+
+// RequeueProcess returns a preempted process to the queue
+func (q *StrideQueue) RequeueProcess(p types.Process) error {
+	return q.Enqueue(p)
+}
+
+// RecordSlice advances the pass of the process by its stride and grants
+// compensation tickets when it blocked early
+func (q *StrideQueue) RecordSlice(p types.Process, ran time.Duration, outcome types.SliceOutcome) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	h, ok := q.ledger.holders[p.GetPID()]
+	if !ok {
+		return
+	}
+
+	if outcome != types.SliceMigrated {
+		h.pass += q.stride(h, q.ledger.rates())
+	}
+	q.ledger.recordSlice(h, ran, outcome)
+
+	if outcome != types.SliceCompleted && outcome != types.SliceFailed && !h.active {
+		h.pass -= q.globalPass
+	}
+	q.updateGlobalPass()
+}
+
+// take removes the node from the timeline
+func (q *StrideQueue) take(n *rbtree.Node[*ticketHolder]) *ticketHolder {
+	h := n.Value
+	q.timeline.Delete(n)
+	h.node = nil
+	h.queued = false
+	return h
+}
+
+func (q *StrideQueue) stride(h *ticketHolder, rates map[string]float64) float64 {
+	weight := q.ledger.weight(h, rates)
+	if weight <= 0 {
+		return stride1
+	}
+	return stride1 / weight
+}
+
+func (q *StrideQueue) updateGlobalPass() {
+	found := false
+	min := 0.0
+	for _, h := range q.ledger.holders {
+		if h.active && (!found || h.pass < min) {
+			min = h.pass
+			found = true
+		}
+	}
+
+	if found && min > q.globalPass {
+		q.globalPass = min
+	}
+}
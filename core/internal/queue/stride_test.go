@@ -0,0 +1,101 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/types"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestStrideQueue_Dequeue(t *testing.T) {
+	t.Run("should share quanta by tickets within one quantum", func(t *testing.T) {
+		queue := NewStrideQueue(StrideConfig{})
+		queue.Enqueue(newTicketPCB(1, 300, BaseCurrency))
+		queue.Enqueue(newTicketPCB(2, 100, BaseCurrency))
+
+		wins := runShares(t, queue, 400)
+
+		if wins[1] < 299 || wins[1] > 301 {
+			t.Errorf("expected about 300 and 100 quanta, got %v", wins)
+		}
+	})
+
+	t.Run("should return error when empty", func(t *testing.T) {
+		if _, err := NewStrideQueue(StrideConfig{}).Dequeue(); err == nil {
+			t.Error("expected error when dequeuing from empty queue")
+		}
+	})
+}
+
+func TestStrideQueue_Enqueue(t *testing.T) {
+	t.Run("should not let a waking process catch up on missed turns", func(t *testing.T) {
+		queue := NewStrideQueue(StrideConfig{})
+		sleeper := newTicketPCB(1, 100, BaseCurrency)
+		worker := newTicketPCB(2, 100, BaseCurrency)
+		queue.Enqueue(sleeper)
+		queue.Enqueue(worker)
+
+		p, _ := queue.Dequeue()
+		queue.RecordSlice(p, defaultShareQuantum, types.SliceBlocked)
+		runShares(t, queue, 20)
+		queue.Enqueue(p)
+
+		wins := runShares(t, queue, 10)
+		if wins[1] > 6 {
+			t.Errorf("expected the waking process to share evenly, got %v", wins)
+		}
+	})
+}
+
+func TestStrideQueue_RecordSlice(t *testing.T) {
+	t.Run("should advance the pass by the stride", func(t *testing.T) {
+		queue := NewStrideQueue(StrideConfig{})
+		p := newTicketPCB(1, 100, BaseCurrency)
+		queue.Enqueue(p)
+		queue.Dequeue()
+
+		before := queue.GetPass(p)
+		queue.RecordSlice(p, defaultShareQuantum, types.SliceExpired)
+
+		expected := float64(stride1) / 100
+		if got := queue.GetPass(p) - before; math.Abs(got-expected) > 1e-9 {
+			t.Errorf("expected pass to advance by %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("should shorten the stride after a partial quantum", func(t *testing.T) {
+		queue := NewStrideQueue(StrideConfig{Quantum: 10 * time.Millisecond})
+		p := newTicketPCB(1, 100, BaseCurrency)
+		queue.Enqueue(p)
+		queue.Dequeue()
+		queue.RecordSlice(p, 2500*time.Microsecond, types.SliceBlocked)
+		queue.Enqueue(p)
+		queue.Dequeue()
+
+		before := queue.GetPass(p)
+		queue.RecordSlice(p, 10*time.Millisecond, types.SliceExpired)
+
+		expected := float64(stride1) / 400
+		if got := queue.GetPass(p) - before; math.Abs(got-expected) > 1e-9 {
+			t.Errorf("expected compensated stride %v, got %v", expected, got)
+		}
+	})
+}
+
+func TestStrideQueue_GetShareMetrics(t *testing.T) {
+	t.Run("should match actual and entitled shares", func(t *testing.T) {
+		queue := NewStrideQueue(StrideConfig{})
+		queue.AddCurrency("alice", 200)
+		queue.Enqueue(newTicketPCB(1, 5, "alice"))
+		queue.Enqueue(newTicketPCB(2, 100, BaseCurrency))
+
+		runShares(t, queue, 300)
+
+		for _, m := range queue.GetShareMetrics().Processes {
+			if math.Abs(m.ActualShare-m.EntitledShare) > 0.01 {
+				t.Errorf("expected PID %d to get its entitled share %.3f, got %.3f",
+					m.PID, m.EntitledShare, m.ActualShare)
+			}
+		}
+	})
+}
@@ -0,0 +1,244 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/rbtree"
+	"cpu-scheduling/core/internal/types"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const defaultShareQuantum = 10 * time.Millisecond
+
+// BaseCurrency is the currency every other currency is funded in. A base
+// ticket is worth the same no matter how many are issued
+const BaseCurrency = ""
+
+// ShareProcessMetrics compares the CPU share a process got with the share its
+// tickets entitle it to. Tickets is the current value of its holdings in base
+// tickets, including transferred tickets but not compensation
+type ShareProcessMetrics struct {
+	PID           int
+	Tickets       float64
+	CPUTime       time.Duration
+	EntitledShare float64
+	ActualShare   float64
+}
+
+// ShareMetrics extends the scheduling metrics with the share of every known
+// process
+type ShareMetrics struct {
+	types.SchedulingMetrics
+	Processes []ShareProcessMetrics
+}
+
+// currency is funded with base tickets and divides their value among the
+// tickets it has issued to active processes
+type currency struct {
+	name    string
+	funding int
+}
+
+// ticketHolder is the account a proportional share queue keeps per process
+type ticketHolder struct {
+	process types.Process
+	// holdings counts the tickets held per currency, including those
+	// transferred from other processes
+	holdings map[string]int
+	// compensation inflates the value of a process that gave up the CPU
+	// before its quantum ended, until it runs again
+	compensation float64
+	// active holders are queued or running, and only they draw on the
+	// funding of their currencies
+	active  bool
+	queued  bool
+	cpuTime time.Duration
+
+	// Stride scheduling state. While the holder is inactive, pass is kept
+	// relative to the global pass
+	pass float64
+	seq  uint64
+	node *rbtree.Node[*ticketHolder]
+}
+
+// ticketLedger keeps the currencies and ticket accounts shared by the lottery
+// and stride queues. It is not safe for concurrent use and relies on the lock
+// of the queue that embeds it
+type ticketLedger struct {
+	currencies map[string]*currency
+	holders    map[int]*ticketHolder
+	quantum    time.Duration
+}
+
+func newTicketLedger(quantum time.Duration) ticketLedger {
+	if quantum <= 0 {
+		quantum = defaultShareQuantum
+	}
+
+	return ticketLedger{
+		currencies: make(map[string]*currency),
+		holders:    make(map[int]*ticketHolder),
+		quantum:    quantum,
+	}
+}
+
+func (l *ticketLedger) addCurrency(name string, funding int) error {
+	if name == BaseCurrency {
+		return fmt.Errorf("cannot redefine the base currency")
+	}
+	if _, exists := l.currencies[name]; exists {
+		return fmt.Errorf("currency %q already exists", name)
+	}
+	if funding <= 0 {
+		return fmt.Errorf("currency %q needs positive funding, got %d", name, funding)
+	}
+
+	l.currencies[name] = &currency{name: name, funding: funding}
+	return nil
+}
+
+// holder returns the account of the process, opening it with the tickets the
+// process was created with
+func (l *ticketLedger) holder(p types.Process) (*ticketHolder, error) {
+	if h, ok := l.holders[p.GetPID()]; ok {
+		return h, nil
+	}
+
+	name := p.GetCurrency()
+	if _, ok := l.currencies[name]; !ok && name != BaseCurrency {
+		return nil, fmt.Errorf("process %d holds tickets in unknown currency %q", p.GetPID(), name)
+	}
+
+	tickets := p.GetTickets()
+	if tickets <= 0 {
+		tickets = types.DefaultTickets
+	}
+
+	h := &ticketHolder{
+		process:      p,
+		holdings:     map[string]int{name: tickets},
+		compensation: 1,
+	}
+	l.holders[p.GetPID()] = h
+	return h, nil
+}
+
+// transfer moves tickets of the currency of from to to, for example while a
+// client waits for a server to handle its request
+func (l *ticketLedger) transfer(from, to types.Process, tickets int) error {
+	if tickets <= 0 {
+		return fmt.Errorf("can only transfer a positive number of tickets, got %d", tickets)
+	}
+
+	sender, err := l.holder(from)
+	if err != nil {
+		return err
+	}
+	receiver, err := l.holder(to)
+	if err != nil {
+		return err
+	}
+
+	name := from.GetCurrency()
+	if sender.holdings[name] < tickets {
+		return fmt.Errorf("process %d holds only %d tickets in %q, cannot transfer %d",
+			from.GetPID(), sender.holdings[name], name, tickets)
+	}
+
+	sender.holdings[name] -= tickets
+	receiver.holdings[name] += tickets
+	return nil
+}
+
+// rates returns the value of a ticket in base tickets for every currency. A
+// currency splits its funding among the tickets held by active processes, or
+// by all its holders while none of them is active
+func (l *ticketLedger) rates() map[string]float64 {
+	issued := make(map[string]int)
+	held := make(map[string]int)
+	for _, h := range l.holders {
+		for name, tickets := range h.holdings {
+			held[name] += tickets
+			if h.active {
+				issued[name] += tickets
+			}
+		}
+	}
+
+	rates := map[string]float64{BaseCurrency: 1}
+	for name, c := range l.currencies {
+		switch {
+		case issued[name] > 0:
+			rates[name] = float64(c.funding) / float64(issued[name])
+		case held[name] > 0:
+			rates[name] = float64(c.funding) / float64(held[name])
+		default:
+			rates[name] = float64(c.funding)
+		}
+	}
+	return rates
+}
+
+// value returns the holdings of the account in base tickets
+func (l *ticketLedger) value(h *ticketHolder, rates map[string]float64) float64 {
+	total := 0.0
+	for name, tickets := range h.holdings {
+		total += float64(tickets) * rates[name]
+	}
+	return total
+}
+
+// weight is the value the account competes with, including compensation
+func (l *ticketLedger) weight(h *ticketHolder, rates map[string]float64) float64 {
+	return l.value(h, rates) * h.compensation
+}
+
+// recordSlice charges the CPU time to the account and grants compensation to a
+// process that blocked before its quantum ended
+func (l *ticketLedger) recordSlice(h *ticketHolder, ran time.Duration, outcome types.SliceOutcome) {
+	h.cpuTime += ran
+
+	h.compensation = 1
+	switch outcome {
+	case types.SliceCompleted, types.SliceFailed, types.SliceMigrated:
+		delete(l.holders, h.process.GetPID())
+	case types.SliceBlocked, types.SliceJobCompleted:
+		h.active = false
+		if ran > 0 && ran < l.quantum {
+			h.compensation = float64(l.quantum) / float64(ran)
+		}
+	}
+}
+
+func (l *ticketLedger) metrics(base types.SchedulingMetrics) ShareMetrics {
+	rates := l.rates()
+
+	var totalTickets float64
+	var totalCPU time.Duration
+	for _, h := range l.holders {
+		totalTickets += l.value(h, rates)
+		totalCPU += h.cpuTime
+	}
+
+	processes := make([]ShareProcessMetrics, 0, len(l.holders))
+	for pid, h := range l.holders {
+		m := ShareProcessMetrics{
+			PID:     pid,
+			Tickets: l.value(h, rates),
+			CPUTime: h.cpuTime,
+		}
+		if totalTickets > 0 {
+			m.EntitledShare = m.Tickets / totalTickets
+		}
+		if totalCPU > 0 {
+			m.ActualShare = float64(h.cpuTime) / float64(totalCPU)
+		}
+		processes = append(processes, m)
+	}
+	sort.Slice(processes, func(i, j int) bool { return processes[i].PID < processes[j].PID })
+
+	return ShareMetrics{
+		SchedulingMetrics: base,
+		Processes:         processes,
+	}
+}
@@ -0,0 +1,46 @@
+package queue
+
+import (
+	"cpu-scheduling/core/internal/types"
+	"time"
+)
+
+// nice0Weight is the load weight of a process with nice value 0
+const nice0Weight = 1024
+
+// niceToWeight is the Linux sched_prio_to_weight table. Each nice level
+// changes the weight by roughly 1.25x, so a process gets about 10% more or
+// less CPU than one a level away
+var niceToWeight = [40]int{
+	/* -20 */ 88761, 71755, 56483, 46273, 36291,
+	/* -15 */ 29154, 23254, 18705, 14949, 11916,
+	/* -10 */ 9548, 7620, 6100, 4904, 3906,
+	/*  -5 */ 3121, 2501, 1991, 1586, 1277,
+	/*   0 */ 1024, 820, 655, 526, 423,
+	/*   5 */ 335, 272, 215, 172, 137,
+	/*  10 */ 110, 87, 70, 56, 45,
+	/*  15 */ 36, 29, 23, 18, 15,
+}
+
+// NiceWeight returns the load weight for a nice value, clamping values outside
+// the valid range
+func NiceWeight(nice int) int {
+	if nice < types.MinNice {
+		nice = types.MinNice
+	}
+	if nice > types.MaxNice {
+		nice = types.MaxNice
+	}
+	return niceToWeight[nice-types.MinNice]
+}
+
+// toVirtual converts CPU time to virtual time for the given weight, so heavier
+// processes accumulate virtual time more slowly
+func toVirtual(d time.Duration, weight int) time.Duration {
+	return time.Duration(int64(d) * nice0Weight / int64(weight))
+}
+
+// fromVirtual converts virtual time back to CPU time for the given weight
+func fromVirtual(d time.Duration, weight int) time.Duration {
+	return time.Duration(int64(d) * int64(weight) / nice0Weight)
+}
@@ -0,0 +1,303 @@
+package rbtree
+
+// color of a red-black tree node
+type color bool
+
+const (
+	red   color = false
+	black color = true
+)
+
+// Node is an element of a Tree. Nodes returned by Insert stay valid until they
+// are deleted, so callers can keep them to remove the value later
+type Node[T any] struct {
+	Value T
+
+	color               color
+	left, right, parent *Node[T]
+}
+
+// Tree is a red-black tree ordered by a less function. Values that compare
+// equal are kept in insertion order. Tree is not safe for concurrent use
+type Tree[T any] struct {
+	root *Node[T]
+	less func(a, b T) bool
+	size int
+}
+
+func New[T any](less func(a, b T) bool) *Tree[T] {
+	return &Tree[T]{less: less}
+}
+
+func (t *Tree[T]) Len() int {
+	return t.size
+}
+
+// Min returns the leftmost node, or nil if the tree is empty
+func (t *Tree[T]) Min() *Node[T] {
+	if t.root == nil {
+		return nil
+	}
+	return minimum(t.root)
+}
+
+// Next returns the in-order successor of n, or nil if n is the last node
+func (t *Tree[T]) Next(n *Node[T]) *Node[T] {
+	if n.right != nil {
+		return minimum(n.right)
+	}
+
+	parent := n.parent
+	for parent != nil && n == parent.right {
+		n = parent
+		parent = parent.parent
+	}
+	return parent
+}
+
+// Walk calls fn for every value in order until fn returns false
+func (t *Tree[T]) Walk(fn func(value T) bool) {
+	for n := t.Min(); n != nil; n = t.Next(n) {
+		if !fn(n.Value) {
+			return
+		}
+	}
+}
+
+// Insert adds a value and returns the node holding it
+func (t *Tree[T]) Insert(value T) *Node[T] {
+	n := &Node[T]{Value: value, color: red}
+
+	var parent *Node[T]
+	cur := t.root
+	for cur != nil {
+		parent = cur
+		if t.less(value, cur.Value) {
+			cur = cur.left
+		} else {
+			cur = cur.right
+		}
+	}
+
+	n.parent = parent
+	switch {
+	case parent == nil:
+		t.root = n
+	case t.less(value, parent.Value):
+		parent.left = n
+	default:
+		parent.right = n
+	}
+
+	t.insertFixup(n)
+	t.size++
+	return n
+}
+
+// Delete removes a node previously returned by Insert
+func (t *Tree[T]) Delete(z *Node[T]) {
+	y := z
+	yColor := y.color
+	var x, xParent *Node[T]
+
+	switch {
+	case z.left == nil:
+		x = z.right
+		xParent = z.parent
+		t.transplant(z, z.right)
+	case z.right == nil:
+		x = z.left
+		xParent = z.parent
+		t.transplant(z, z.left)
+	default:
+		y = minimum(z.right)
+		yColor = y.color
+		x = y.right
+		if y.parent == z {
+			xParent = y
+		} else {
+			xParent = y.parent
+			t.transplant(y, y.right)
+			y.right = z.right
+			y.right.parent = y
+		}
+		t.transplant(z, y)
+		y.left = z.left
+		y.left.parent = y
+		y.color = z.color
+	}
+
+	if yColor == black {
+		t.deleteFixup(x, xParent)
+	}
+
+	z.left, z.right, z.parent = nil, nil, nil
+	t.size--
+}
+
+func minimum[T any](n *Node[T]) *Node[T] {
+	for n.left != nil {
+		n = n.left
+	}
+	return n
+}
+
+func colorOf[T any](n *Node[T]) color {
+	if n == nil {
+		return black
+	}
+	return n.color
+}
+
+func (t *Tree[T]) rotateLeft(x *Node[T]) {
+	y := x.right
+	x.right = y.left
+	if y.left != nil {
+		y.left.parent = x
+	}
+	y.parent = x.parent
+	switch {
+	case x.parent == nil:
+		t.root = y
+	case x == x.parent.left:
+		x.parent.left = y
+	default:
+		x.parent.right = y
+	}
+	y.left = x
+	x.parent = y
+}
+
+func (t *Tree[T]) rotateRight(x *Node[T]) {
+	y := x.left
+	x.left = y.right
+	if y.right != nil {
+		y.right.parent = x
+	}
+	y.parent = x.parent
+	switch {
+	case x.parent == nil:
+		t.root = y
+	case x == x.parent.right:
+		x.parent.right = y
+	default:
+		x.parent.left = y
+	}
+	y.right = x
+	x.parent = y
+}
+
+func (t *Tree[T]) insertFixup(z *Node[T]) {
+	for colorOf(z.parent) == red {
+		grandparent := z.parent.parent
+		if z.parent == grandparent.left {
+			uncle := grandparent.right
+			if colorOf(uncle) == red {
+				z.parent.color = black
+				uncle.color = black
+				grandparent.color = red
+				z = grandparent
+				continue
+			}
+			if z == z.parent.right {
+				z = z.parent
+				t.rotateLeft(z)
+			}
+			z.parent.color = black
+			z.parent.parent.color = red
+			t.rotateRight(z.parent.parent)
+		} else {
+			uncle := grandparent.left
+			if colorOf(uncle) == red {
+				z.parent.color = black
+				uncle.color = black
+				grandparent.color = red
+				z = grandparent
+				continue
+			}
+			if z == z.parent.left {
+				z = z.parent
+				t.rotateRight(z)
+			}
+			z.parent.color = black
+			z.parent.parent.color = red
+			t.rotateLeft(z.parent.parent)
+		}
+	}
+	t.root.color = black
+}
+
+func (t *Tree[T]) transplant(u, v *Node[T]) {
+	switch {
+	case u.parent == nil:
+		t.root = v
+	case u == u.parent.left:
+		u.parent.left = v
+	default:
+		u.parent.right = v
+	}
+	if v != nil {
+		v.parent = u.parent
+	}
+}
+
+// deleteFixup restores the red-black properties after a black node was
+// removed. x may be nil, so its parent is passed separately
+func (t *Tree[T]) deleteFixup(x, parent *Node[T]) {
+	for x != t.root && colorOf(x) == black {
+		if x == parent.left {
+			sibling := parent.right
+			if colorOf(sibling) == red {
+				sibling.color = black
+				parent.color = red
+				t.rotateLeft(parent)
+				sibling = parent.right
+			}
+			if colorOf(sibling.left) == black && colorOf(sibling.right) == black {
+				sibling.color = red
+				x = parent
+				parent = x.parent
+				continue
+			}
+			if colorOf(sibling.right) == black {
+				sibling.left.color = black
+				sibling.color = red
+				t.rotateRight(sibling)
+				sibling = parent.right
+			}
+			sibling.color = parent.color
+			parent.color = black
+			sibling.right.color = black
+			t.rotateLeft(parent)
+			x = t.root
+		} else {
+			sibling := parent.left
+			if colorOf(sibling) == red {
+				sibling.color = black
+				parent.color = red
+				t.rotateRight(parent)
+				sibling = parent.left
+			}
+			if colorOf(sibling.left) == black && colorOf(sibling.right) == black {
+				sibling.color = red
+				x = parent
+				parent = x.parent
+				continue
+			}
+			if colorOf(sibling.left) == black {
+				sibling.right.color = black
+				sibling.color = red
+				t.rotateLeft(sibling)
+				sibling = parent.left
+			}
+			sibling.color = parent.color
+			parent.color = black
+			sibling.left.color = black
+			t.rotateRight(parent)
+			x = t.root
+		}
+	}
+	if x != nil {
+		x.color = black
+	}
+}
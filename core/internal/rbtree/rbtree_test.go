@@ -0,0 +1,138 @@
+package rbtree
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// checkInvariants verifies the red-black properties and returns the black
+// height of the subtree
+func checkInvariants[T any](t *testing.T, n *Node[T]) int {
+	t.Helper()
+
+	if n == nil {
+		return 1
+	}
+	if n.color == red && (colorOf(n.left) == red || colorOf(n.right) == red) {
+		t.Fatal("red node has a red child")
+	}
+	if n.left != nil && n.left.parent != n || n.right != nil && n.right.parent != n {
+		t.Fatal("broken parent link")
+	}
+
+	left := checkInvariants(t, n.left)
+	right := checkInvariants(t, n.right)
+	if left != right {
+		t.Fatalf("black height mismatch: %d vs %d", left, right)
+	}
+	if n.color == black {
+		return left + 1
+	}
+	return left
+}
+
+func values(tree *Tree[int]) []int {
+	var result []int
+	tree.Walk(func(v int) bool {
+		result = append(result, v)
+		return true
+	})
+	return result
+}
+
+func TestTree_Insert(t *testing.T) {
+	t.Run("should keep values in order", func(t *testing.T) {
+		tree := New(func(a, b int) bool { return a < b })
+		for _, v := range []int{5, 3, 8, 1, 4, 7, 9, 2, 6} {
+			tree.Insert(v)
+		}
+
+		got := values(tree)
+		for i, v := range got {
+			if v != i+1 {
+				t.Fatalf("expected sorted values, got %v", got)
+			}
+		}
+		if tree.Len() != 9 {
+			t.Errorf("expected length 9, got %d", tree.Len())
+		}
+		checkInvariants(t, tree.root)
+	})
+
+	t.Run("should keep equal values in insertion order", func(t *testing.T) {
+		type item struct{ key, id int }
+		tree := New(func(a, b item) bool { return a.key < b.key })
+		for id := 0; id < 5; id++ {
+			tree.Insert(item{key: 1, id: id})
+		}
+
+		id := 0
+		tree.Walk(func(v item) bool {
+			if v.id != id {
+				t.Errorf("expected id %d, got %d", id, v.id)
+			}
+			id++
+			return true
+		})
+	})
+}
+
+func TestTree_Delete(t *testing.T) {
+	t.Run("should remove nodes and keep the tree balanced", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+		tree := New(func(a, b int) bool { return a < b })
+		nodes := make(map[int]*Node[int])
+		for _, v := range rng.Perm(500) {
+			nodes[v] = tree.Insert(v)
+		}
+
+		remaining := make([]int, 0)
+		for _, v := range rng.Perm(500) {
+			if v%3 == 0 {
+				remaining = append(remaining, v)
+				continue
+			}
+			tree.Delete(nodes[v])
+			checkInvariants(t, tree.root)
+		}
+
+		sort.Ints(remaining)
+		got := values(tree)
+		if len(got) != len(remaining) {
+			t.Fatalf("expected %d values, got %d", len(remaining), len(got))
+		}
+		for i := range got {
+			if got[i] != remaining[i] {
+				t.Fatalf("expected %v, got %v", remaining, got)
+			}
+		}
+	})
+
+	t.Run("should empty the tree", func(t *testing.T) {
+		tree := New(func(a, b int) bool { return a < b })
+		n := tree.Insert(1)
+		tree.Delete(n)
+
+		if tree.Len() != 0 || tree.Min() != nil {
+			t.Error("expected empty tree")
+		}
+	})
+}
+
+func TestTree_Min(t *testing.T) {
+	t.Run("should return the smallest value", func(t *testing.T) {
+		tree := New(func(a, b int) bool { return a < b })
+		if tree.Min() != nil {
+			t.Error("expected nil minimum for empty tree")
+		}
+
+		tree.Insert(3)
+		tree.Insert(1)
+		tree.Insert(2)
+
+		if tree.Min().Value != 1 {
+			t.Errorf("expected minimum 1, got %d", tree.Min().Value)
+		}
+	})
+}
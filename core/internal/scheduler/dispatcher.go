@@ -0,0 +1,597 @@
+package scheduler
+
+import (
+	"context"
+	"cpu-scheduling/core/internal/process"
+	"cpu-scheduling/core/internal/types"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// idlePollInterval bounds how long the dispatch loop sleeps on an empty queue
+// before checking it again, so processes enqueued directly on the queue are
+// still picked up
+const idlePollInterval = 10 * time.Millisecond
+
+// Completion describes a process that has finished executing
+type Completion struct {
+	PID        int
+	Result     any
+	Err        error
+	FinishedAt time.Time
+}
+
+// DispatchEvent records a slice a process held a CPU, from its dispatch to
+// when it left the CPU. Overhead is the start of the slice spent refilling
+// caches after a migration, before the process ran
+type DispatchEvent struct {
+	CPU      int
+	PID      int
+	Start    time.Time
+	End      time.Time
+	Overhead time.Duration
+	Outcome  types.SliceOutcome
+}
+
+// Dispatcher pulls processes from a scheduling queue and runs them on the CPU,
+// moving them through READY -> RUNNING -> TERMINATED via the process manager.
+// When the queue is a types.TimeSlicedQueue, running processes are preempted
+// at the end of their slice and requeued at the tail. When it is a
+// types.PreemptiveQueue, an arriving process may preempt the running one.
+// Tasks that return a types.IOWaitError are moved to WAITING until their I/O
+// completes. Periodic processes wait in WAITING after each job until their
+// next job is released, and terminate once their job limit is reached
+type Dispatcher struct {
+	queue   types.SchedulingQueue
+	manager *process.Manager
+
+	mu          sync.Mutex
+	idle        *sync.Cond
+	running     bool
+	stop        chan struct{}
+	done        chan struct{}
+	wake        chan struct{}
+	current     types.Process
+	cancel      context.CancelFunc
+	submitted   map[int]bool
+	completions []Completion
+	timeline    []DispatchEvent
+
+	// busy is the CPU time spent running processes, not counting the slice
+	// that started at sliceStart and is still running
+	busy       time.Duration
+	sliceStart time.Time
+	dispatched int
+
+	// lastRun is when each process last left the CPU, to tell whether its
+	// cache is still hot. penalties holds the overhead migrated processes
+	// pay before they run here for the first time
+	lastRun   map[int]time.Time
+	penalties map[int]time.Duration
+
+	// wakeups holds the pending wake-ups of processes waiting for their I/O
+	// or their next job. Stop halts their timers and Start sets them again
+	wakeups map[int]*wakeup
+
+	// machine is set for dispatchers that run a core of a Machine, which
+	// then routes arrivals and tracks completions across all cores
+	machine *Machine
+}
+
+// NewDispatcher creates a dispatcher that drives the given queue
+func NewDispatcher(queue types.SchedulingQueue, manager *process.Manager) (*Dispatcher, error) {
+	if queue == nil {
+		return nil, fmt.Errorf("cannot create dispatcher with nil queue")
+	}
+	if manager == nil {
+		return nil, fmt.Errorf("cannot create dispatcher with nil manager")
+	}
+
+	d := &Dispatcher{
+		queue:     queue,
+		manager:   manager,
+		wake:      make(chan struct{}, 1),
+		submitted: make(map[int]bool),
+		lastRun:   make(map[int]time.Time),
+		penalties: make(map[int]time.Duration),
+		wakeups:   make(map[int]*wakeup),
+	}
+	d.idle = sync.NewCond(&d.mu)
+
+	return d, nil
+}
+
+// Start launches the dispatch loop in its own goroutine
+func (d *Dispatcher) Start() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.running {
+		return fmt.Errorf("dispatcher is already running")
+	}
+
+	d.running = true
+	d.stop = make(chan struct{})
+	d.done = make(chan struct{})
+	for pid, w := range d.wakeups {
+		d.arm(pid, w)
+	}
+
+	go d.loop(d.stop, d.done)
+	return nil
+}
+
+// Stop signals the dispatch loop to exit and blocks until it has. A process
+// that is currently running is allowed to finish first. Waiting processes
+// stay WAITING until the dispatcher is started again
+func (d *Dispatcher) Stop() {
+	d.mu.Lock()
+	if !d.running {
+		d.mu.Unlock()
+		return
+	}
+	d.running = false
+	close(d.stop)
+	for _, w := range d.wakeups {
+		w.timer.Stop()
+	}
+	done := d.done
+	d.idle.Broadcast()
+	d.mu.Unlock()
+
+	<-done
+}
+
+// Wait blocks until every process handed to Submit has completed, or until
+// the dispatcher is stopped
+func (d *Dispatcher) Wait() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for d.running && len(d.submitted) > 0 {
+		d.idle.Wait()
+	}
+}
+
+// Submit admits a process to the ready queue, moving it to READY if it is NEW
+func (d *Dispatcher) Submit(p types.Process) error {
+	if p == nil {
+		return fmt.Errorf("cannot submit nil process")
+	}
+
+	if p.GetState() == types.NEW {
+		if err := d.manager.SetProcessState(p.GetPID(), types.READY); err != nil {
+			return fmt.Errorf("failed to admit process %d: %v", p.GetPID(), err)
+		}
+	}
+
+	d.mu.Lock()
+	d.submitted[p.GetPID()] = true
+	d.mu.Unlock()
+
+	if err := d.arrive(p); err != nil {
+		d.finish(p.GetPID())
+		return fmt.Errorf("failed to enqueue process %d: %v", p.GetPID(), err)
+	}
+	return nil
+}
+
+// Preempt ends the slice of the running process early. The process stops at
+// its next checkpoint and goes back on the queue
+func (d *Dispatcher) Preempt() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.cancel != nil {
+		d.cancel()
+	}
+}
+
+// Current returns the process that is running on the CPU, or nil when idle
+func (d *Dispatcher) Current() types.Process {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.current
+}
+
+// BusyTime returns how long the CPU has spent running processes, including
+// the slice in progress
+func (d *Dispatcher) BusyTime() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.busyTime(time.Now())
+}
+
+func (d *Dispatcher) busyTime(now time.Time) time.Duration {
+	if d.current == nil {
+		return d.busy
+	}
+	return d.busy + now.Sub(d.sliceStart)
+}
+
+// Timeline returns every slice run so far, in dispatch order
+func (d *Dispatcher) Timeline() []DispatchEvent {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	result := make([]DispatchEvent, len(d.timeline))
+	copy(result, d.timeline)
+	return result
+}
+
+// Completions returns the processes that have finished, in completion order
+func (d *Dispatcher) Completions() []Completion {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	result := make([]Completion, len(d.completions))
+	copy(result, d.completions)
+	return result
+}
+
+// arrive puts a ready process on a run queue. Cores of a machine leave the
+// choice of queue to the machine
+func (d *Dispatcher) arrive(p types.Process) error {
+	if d.machine != nil {
+		return d.machine.arrive(p, d)
+	}
+	return d.enqueue(p)
+}
+
+// enqueue puts a ready process on the queue of the dispatcher, preempting the
+// running process if the queue asks for it
+func (d *Dispatcher) enqueue(p types.Process) error {
+	if err := d.queue.Enqueue(p); err != nil {
+		return err
+	}
+
+	d.notify()
+
+	if q, ok := d.queue.(types.PreemptiveQueue); ok {
+		if running := d.Current(); running != nil && q.ShouldPreempt(running, p) {
+			d.Preempt()
+		}
+	}
+	return nil
+}
+
+func (d *Dispatcher) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (d *Dispatcher) loop(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		p, err := d.dequeue()
+		if err != nil {
+			// An idle core of a machine may pull work from a busier one
+			if d.machine != nil && d.machine.steal(d) {
+				continue
+			}
+
+			select {
+			case <-stop:
+				return
+			case <-d.wake:
+			case <-time.After(idlePollInterval):
+			}
+			continue
+		}
+
+		d.dispatch(p)
+	}
+}
+
+// dequeue returns the next process to run. Cores of a machine leave it to
+// the machine, which enforces affinity
+func (d *Dispatcher) dequeue() (types.Process, error) {
+	if d.machine != nil {
+		return d.machine.next(d)
+	}
+	return d.queue.Dequeue()
+}
+
+// dispatch runs a single process on the CPU until its task completes or, for
+// time sliced queues, until its slice expires and it is preempted
+func (d *Dispatcher) dispatch(p types.Process) {
+	pid := p.GetPID()
+
+	if p.GetState() == types.NEW {
+		if err := d.manager.SetProcessState(pid, types.READY); err != nil {
+			d.complete(pid, nil, fmt.Errorf("failed to admit process: %v", err))
+			return
+		}
+	}
+
+	preempted, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	begin := time.Now()
+	d.mu.Lock()
+	d.current = p
+	d.cancel = cancel
+	d.sliceStart = begin
+	d.dispatched++
+	penalty := d.penalties[pid]
+	delete(d.penalties, pid)
+	d.mu.Unlock()
+
+	// A migrated process first refills the caches of its new CPU. It does not
+	// run meanwhile, so the wait is not CPU time of its own, but it can
+	// already be preempted
+	if penalty > 0 {
+		timer := time.NewTimer(penalty)
+		select {
+		case <-timer.C:
+		case <-preempted.Done():
+			timer.Stop()
+			penalty = time.Since(begin)
+		}
+	}
+
+	if err := d.manager.SetProcessState(pid, types.RUNNING); err != nil {
+		d.mu.Lock()
+		d.current = nil
+		d.cancel = nil
+		d.mu.Unlock()
+		d.complete(pid, nil, fmt.Errorf("failed to dispatch process: %v", err))
+		return
+	}
+
+	// Restore the registers saved when the process last left the CPU
+	pctx := p.GetContext()
+	pctx.LoadState()
+
+	slice, cancelSlice := d.sliceContext(preempted, p)
+	slowdown := 1.0
+	if d.machine != nil {
+		slowdown = d.machine.slowdown(d, p)
+	}
+	work, cancelWork := workContext(slice, slowdown)
+
+	start := time.Now()
+	var result any
+	execErr := types.ErrPreempted
+	if preempted.Err() == nil {
+		result, execErr = p.ExecuteSlice(work)
+	}
+	expired := errors.Is(work.Err(), context.DeadlineExceeded)
+	cancelWork()
+
+	// Memory accesses to a remote NUMA node stall the process within its
+	// slice
+	if d.machine != nil {
+		d.machine.stall(slice, time.Since(start), slowdown)
+	}
+	ran := time.Since(start)
+	cancelSlice()
+
+	pctx.SaveState()
+
+	d.mu.Lock()
+	event := DispatchEvent{PID: pid, Start: d.sliceStart, End: time.Now(), Overhead: penalty}
+	d.current = nil
+	d.cancel = nil
+	d.busy += event.End.Sub(event.Start)
+	d.lastRun[pid] = event.End
+	d.mu.Unlock()
+
+	var ioWait *types.IOWaitError
+	switch {
+	case errors.Is(execErr, types.ErrPreempted):
+		outcome := types.SlicePreempted
+		if expired {
+			outcome = types.SliceExpired
+		}
+		d.recordSlice(p, event, ran, outcome)
+
+		if err := d.preempt(p); err != nil {
+			d.complete(pid, nil, err)
+		}
+	case errors.As(execErr, &ioWait):
+		d.recordSlice(p, event, ran, types.SliceBlocked)
+
+		if err := d.block(p, ioWait.Duration); err != nil {
+			d.complete(pid, nil, err)
+		}
+	case execErr == nil && hasNextJob(p):
+		d.recordSlice(p, event, ran, types.SliceJobCompleted)
+
+		if err := d.release(p); err != nil {
+			d.complete(pid, nil, err)
+		}
+	default:
+		outcome := types.SliceCompleted
+		if execErr != nil {
+			outcome = types.SliceFailed
+		}
+		d.recordSlice(p, event, ran, outcome)
+
+		if err := d.manager.SetProcessState(pid, types.TERMINATED); err != nil && execErr == nil {
+			execErr = fmt.Errorf("failed to terminate process: %v", err)
+		}
+		d.complete(pid, result, execErr)
+	}
+}
+
+// recordSlice adds the slice to the timeline and reports how long the process
+// ran to the queue
+func (d *Dispatcher) recordSlice(p types.Process, event DispatchEvent, ran time.Duration, outcome types.SliceOutcome) {
+	event.Outcome = outcome
+	d.mu.Lock()
+	d.timeline = append(d.timeline, event)
+	d.mu.Unlock()
+
+	if q, ok := d.queue.(types.FeedbackQueue); ok {
+		q.RecordSlice(p, ran, outcome)
+	}
+}
+
+// block moves a process to WAITING for the duration of its I/O, after which it
+// is made ready and returned to the queue
+func (d *Dispatcher) block(p types.Process, wait time.Duration) error {
+	if err := d.manager.SetProcessState(p.GetPID(), types.WAITING); err != nil {
+		return fmt.Errorf("failed to block process: %v", err)
+	}
+
+	d.after(p.GetPID(), time.Now().Add(wait), func() {
+		if err := d.manager.SetProcessState(p.GetPID(), types.READY); err != nil {
+			d.complete(p.GetPID(), nil, fmt.Errorf("failed to wake process: %v", err))
+			return
+		}
+		if err := d.arrive(p); err != nil {
+			d.complete(p.GetPID(), nil, fmt.Errorf("failed to requeue process: %v", err))
+		}
+	})
+	return nil
+}
+
+// release puts a periodic process to sleep until its next job is due, after
+// which the job is released and returned to the queue. Jobs are released on
+// the grid of the period, so an overrunning job does not shift later releases
+func (d *Dispatcher) release(p types.Process) error {
+	if err := d.manager.SetProcessState(p.GetPID(), types.WAITING); err != nil {
+		return fmt.Errorf("failed to suspend periodic process: %v", err)
+	}
+
+	next := p.GetReleaseTime().Add(p.GetPeriod())
+	d.after(p.GetPID(), next, func() {
+		if err := p.ReleaseJob(next); err != nil {
+			d.complete(p.GetPID(), nil, fmt.Errorf("failed to release job: %v", err))
+			return
+		}
+		if err := d.manager.SetProcessState(p.GetPID(), types.READY); err != nil {
+			d.complete(p.GetPID(), nil, fmt.Errorf("failed to release job: %v", err))
+			return
+		}
+		if err := d.arrive(p); err != nil {
+			d.complete(p.GetPID(), nil, fmt.Errorf("failed to requeue process: %v", err))
+		}
+	})
+	return nil
+}
+
+// wakeup is a pending wake-up of a waiting process
+type wakeup struct {
+	at    time.Time
+	fn    func()
+	timer *time.Timer
+}
+
+// after calls fn for the process at the given time, as long as the dispatcher
+// is running then
+func (d *Dispatcher) after(pid int, at time.Time, fn func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	w := &wakeup{at: at, fn: fn}
+	d.wakeups[pid] = w
+	if d.running {
+		d.arm(pid, w)
+	}
+}
+
+// arm starts the timer of a wake-up. A timer that fires while the dispatcher
+// is stopped leaves the wake-up for Start to set again. It must be called
+// with mu held
+func (d *Dispatcher) arm(pid int, w *wakeup) {
+	w.timer = time.AfterFunc(time.Until(w.at), func() {
+		d.mu.Lock()
+		if !d.running || d.wakeups[pid] != w {
+			d.mu.Unlock()
+			return
+		}
+		delete(d.wakeups, pid)
+		d.mu.Unlock()
+
+		w.fn()
+	})
+}
+
+// hasNextJob reports whether a periodic process has jobs left to release
+func hasNextJob(p types.Process) bool {
+	if p.GetPeriod() == 0 {
+		return false
+	}
+	return p.GetJobLimit() == 0 || p.GetJob() < p.GetJobLimit()
+}
+
+// sliceContext returns the context a process runs under, which ends with the
+// parent and also expires at the end of its time slice when the queue is time
+// sliced
+func (d *Dispatcher) sliceContext(parent context.Context, p types.Process) (context.Context, context.CancelFunc) {
+	if q, ok := d.queue.(types.TimeSlicedQueue); ok {
+		if slice := q.GetTimeSlice(p); slice > 0 {
+			return context.WithTimeout(parent, slice)
+		}
+	}
+	return context.WithCancel(parent)
+}
+
+// preempt moves a process that was interrupted back to READY and returns it
+// to the queue
+func (d *Dispatcher) preempt(p types.Process) error {
+	if err := d.manager.SetProcessState(p.GetPID(), types.READY); err != nil {
+		return fmt.Errorf("failed to preempt process: %v", err)
+	}
+
+	// A process whose affinity changed while it ran moves to another core
+	if d.machine != nil && d.machine.disallows(d, p) {
+		return d.machine.arrive(p, d)
+	}
+
+	if q, ok := d.queue.(types.TimeSlicedQueue); ok {
+		if err := q.RequeueProcess(p); err != nil {
+			return fmt.Errorf("failed to requeue process: %v", err)
+		}
+		return nil
+	}
+
+	if err := d.queue.Enqueue(p); err != nil {
+		return fmt.Errorf("failed to requeue process: %v", err)
+	}
+	return nil
+}
+
+func (d *Dispatcher) complete(pid int, result any, err error) {
+	c := Completion{
+		PID:        pid,
+		Result:     result,
+		Err:        err,
+		FinishedAt: time.Now(),
+	}
+
+	d.mu.Lock()
+	d.completions = append(d.completions, c)
+	delete(d.lastRun, pid)
+	d.mu.Unlock()
+
+	d.finish(pid)
+	if d.machine != nil {
+		d.machine.complete(c)
+	}
+}
+
+func (d *Dispatcher) finish(pid int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.submitted, pid)
+	if len(d.submitted) == 0 {
+		d.idle.Broadcast()
+	}
+}
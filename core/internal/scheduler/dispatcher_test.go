@@ -0,0 +1,742 @@
+package scheduler
+
+import (
+	"context"
+	"cpu-scheduling/core/internal/process"
+	"cpu-scheduling/core/internal/queue"
+	"cpu-scheduling/core/internal/types"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewDispatcher(t *testing.T) {
+	t.Run("should return error for nil queue", func(t *testing.T) {
+		_, err := NewDispatcher(nil, process.NewManager())
+		if err == nil {
+			t.Error("expected error for nil queue")
+		}
+	})
+
+	t.Run("should return error for nil manager", func(t *testing.T) {
+		_, err := NewDispatcher(queue.NewFCFSQueue(), nil)
+		if err == nil {
+			t.Error("expected error for nil manager")
+		}
+	})
+}
+
+func TestDispatcher_Start(t *testing.T) {
+	t.Run("should return error when already running", func(t *testing.T) {
+		d, _ := NewDispatcher(queue.NewFCFSQueue(), process.NewManager())
+
+		if err := d.Start(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer d.Stop()
+
+		if err := d.Start(); err == nil {
+			t.Error("expected error when starting twice")
+		}
+	})
+
+	t.Run("should be restartable after stop", func(t *testing.T) {
+		d, _ := NewDispatcher(queue.NewFCFSQueue(), process.NewManager())
+
+		d.Start()
+		d.Stop()
+
+		if err := d.Start(); err != nil {
+			t.Errorf("unexpected error restarting: %v", err)
+		}
+		d.Stop()
+	})
+}
+
+func TestDispatcher_Run(t *testing.T) {
+	t.Run("should run processes in queue order and terminate them", func(t *testing.T) {
+		manager := process.NewManager()
+		d, _ := NewDispatcher(queue.NewFCFSQueue(), manager)
+
+		var mu sync.Mutex
+		var order []int
+		var procs []types.Process
+		for i := 0; i < 3; i++ {
+			n := i
+			p, _ := manager.CreateProcess(&types.SimpleTask{
+				ExecuteFn: func() (any, error) {
+					mu.Lock()
+					order = append(order, n)
+					mu.Unlock()
+					return n * 10, nil
+				},
+			})
+			procs = append(procs, p)
+			if err := d.Submit(p); err != nil {
+				t.Fatalf("unexpected submit error: %v", err)
+			}
+		}
+
+		d.Start()
+		d.Wait()
+		d.Stop()
+
+		for i, n := range order {
+			if n != i {
+				t.Errorf("expected process %d to run at position %d, got %d", i, i, n)
+			}
+		}
+
+		for _, p := range procs {
+			if p.GetState() != types.TERMINATED {
+				t.Errorf("expected process %d to be TERMINATED, got %v", p.GetPID(), p.GetState())
+			}
+		}
+
+		completions := d.Completions()
+		if len(completions) != 3 {
+			t.Fatalf("expected 3 completions, got %d", len(completions))
+		}
+		if completions[2].Result != 20 {
+			t.Errorf("expected result 20, got %v", completions[2].Result)
+		}
+	})
+
+	t.Run("should record task errors and still terminate", func(t *testing.T) {
+		manager := process.NewManager()
+		d, _ := NewDispatcher(queue.NewFCFSQueue(), manager)
+
+		p, _ := manager.CreateProcess(&types.SimpleTask{
+			ExecuteFn: func() (any, error) { return nil, fmt.Errorf("boom") },
+		})
+
+		d.Start()
+		d.Submit(p)
+		d.Wait()
+		d.Stop()
+
+		completions := d.Completions()
+		if len(completions) != 1 || completions[0].Err == nil {
+			t.Errorf("expected one completion with error, got %+v", completions)
+		}
+		if p.GetState() != types.TERMINATED {
+			t.Errorf("expected state TERMINATED, got %v", p.GetState())
+		}
+		if timeline := d.Timeline(); len(timeline) != 1 || timeline[0].Outcome != types.SliceFailed {
+			t.Errorf("expected one failed slice, got %+v", timeline)
+		}
+	})
+
+	t.Run("should mark the process as running while it executes", func(t *testing.T) {
+		manager := process.NewManager()
+		d, _ := NewDispatcher(queue.NewFCFSQueue(), manager)
+
+		var observed types.ProcessState
+		var current types.Process
+		var p types.Process
+		p, _ = manager.CreateProcess(&types.SimpleTask{
+			ExecuteFn: func() (any, error) {
+				observed = p.GetState()
+				current = d.Current()
+				return nil, nil
+			},
+		})
+
+		d.Start()
+		d.Submit(p)
+		d.Wait()
+		d.Stop()
+
+		if observed != types.RUNNING {
+			t.Errorf("expected state RUNNING during execution, got %v", observed)
+		}
+		if current == nil || current.GetPID() != p.GetPID() {
+			t.Error("expected current process to be the executing one")
+		}
+		if d.Current() != nil {
+			t.Error("expected no current process after completion")
+		}
+	})
+
+	t.Run("should restore saved context before executing", func(t *testing.T) {
+		manager := process.NewManager()
+		d, _ := NewDispatcher(queue.NewFCFSQueue(), manager)
+
+		var pc uint64
+		var p types.Process
+		p, _ = manager.CreateProcess(&types.SimpleTask{
+			ExecuteFn: func() (any, error) {
+				pc = p.GetContext().GetProgramCounter()
+				return nil, nil
+			},
+		})
+
+		p.GetContext().SetProgramCounter(64)
+		p.GetContext().SaveState()
+		p.GetContext().SetProgramCounter(0)
+
+		d.Start()
+		d.Submit(p)
+		d.Wait()
+		d.Stop()
+
+		if pc != 64 {
+			t.Errorf("expected restored program counter 64, got %d", pc)
+		}
+	})
+
+	t.Run("should pick up processes enqueued directly on the queue", func(t *testing.T) {
+		manager := process.NewManager()
+		q := queue.NewFCFSQueue()
+		d, _ := NewDispatcher(q, manager)
+
+		p, _ := manager.CreateProcess(&types.SimpleTask{
+			ExecuteFn: func() (any, error) { return nil, nil },
+		})
+		q.Enqueue(p)
+
+		d.Start()
+		defer d.Stop()
+
+		deadline := time.After(time.Second)
+		for p.GetState() != types.TERMINATED {
+			select {
+			case <-deadline:
+				t.Fatalf("process was not dispatched, state %v", p.GetState())
+			default:
+				time.Sleep(time.Millisecond)
+			}
+		}
+	})
+}
+
+// sleepTask performs its work in fixed units and yields between them
+type sleepTask struct {
+	units int
+	unit  time.Duration
+
+	mu     sync.Mutex
+	done   int
+	slices int
+}
+
+func (t *sleepTask) Execute() (any, error) {
+	return t.ExecuteSlice(context.Background())
+}
+
+func (t *sleepTask) ExecuteSlice(ctx context.Context) (any, error) {
+	t.mu.Lock()
+	t.slices++
+	t.mu.Unlock()
+
+	for {
+		t.mu.Lock()
+		finished := t.done >= t.units
+		t.mu.Unlock()
+		if finished {
+			return t.units, nil
+		}
+
+		if err := types.Checkpoint(ctx); err != nil {
+			return nil, err
+		}
+
+		time.Sleep(t.unit)
+
+		t.mu.Lock()
+		t.done++
+		t.mu.Unlock()
+	}
+}
+
+func (t *sleepTask) sliceCount() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.slices
+}
+
+func TestDispatcher_Preemption(t *testing.T) {
+	t.Run("should preempt and requeue processes when their quantum expires", func(t *testing.T) {
+		manager := process.NewManager()
+		d, _ := NewDispatcher(queue.NewRoundRobinQueue(5*time.Millisecond), manager)
+
+		long := &sleepTask{units: 20, unit: time.Millisecond}
+		short := &sleepTask{units: 2, unit: time.Millisecond}
+		p1, _ := manager.CreateProcess(long)
+		p2, _ := manager.CreateProcess(short)
+
+		d.Submit(p1)
+		d.Submit(p2)
+		d.Start()
+		d.Wait()
+		d.Stop()
+
+		if long.sliceCount() < 2 {
+			t.Errorf("expected long task to be sliced, ran in %d slices", long.sliceCount())
+		}
+
+		completions := d.Completions()
+		if len(completions) != 2 {
+			t.Fatalf("expected 2 completions, got %d", len(completions))
+		}
+		if completions[0].PID != p2.GetPID() {
+			t.Errorf("expected short process to finish first, got PID %d", completions[0].PID)
+		}
+		if completions[1].Result != 20 {
+			t.Errorf("expected long task result 20, got %v", completions[1].Result)
+		}
+		if p1.GetState() != types.TERMINATED {
+			t.Errorf("expected long process to be TERMINATED, got %v", p1.GetState())
+		}
+	})
+
+	t.Run("should save the process context when it is preempted", func(t *testing.T) {
+		manager := process.NewManager()
+		d, _ := NewDispatcher(queue.NewRoundRobinQueue(2*time.Millisecond), manager)
+
+		var p types.Process
+		var seen []uint64
+		p, _ = manager.CreateProcess(&sliceFuncTask{
+			fn: func(ctx context.Context) (any, error) {
+				pctx := p.GetContext()
+				pc := pctx.GetProgramCounter()
+				seen = append(seen, pc)
+				if pc == 8 {
+					return pc, nil
+				}
+				pctx.SetProgramCounter(pc + 4)
+				<-ctx.Done()
+				return nil, types.ErrPreempted
+			},
+		})
+
+		d.Submit(p)
+		d.Start()
+		d.Wait()
+		d.Stop()
+
+		if len(seen) != 3 || seen[0] != 0 || seen[1] != 4 || seen[2] != 8 {
+			t.Errorf("expected program counter to resume across slices, got %v", seen)
+		}
+	})
+
+	t.Run("should run steppable tasks slice by slice", func(t *testing.T) {
+		manager := process.NewManager()
+		d, _ := NewDispatcher(queue.NewRoundRobinQueue(time.Millisecond), manager)
+
+		var mu sync.Mutex
+		var slices []float64
+		var p types.Process
+		p, _ = manager.CreateProcess(process.NewStepTask(1000,
+			func(ctx types.ProcessContext, unit uint64) error {
+				if unit%64 == 0 {
+					time.Sleep(200 * time.Microsecond)
+				}
+				return nil
+			},
+			func(ctx types.ProcessContext) any { return "finished" },
+		))
+
+		other, _ := manager.CreateProcess(&types.SimpleTask{
+			ExecuteFn: func() (any, error) {
+				mu.Lock()
+				slices = append(slices, p.GetProgress())
+				mu.Unlock()
+				return nil, nil
+			},
+		})
+
+		d.Submit(p)
+		d.Submit(other)
+		d.Start()
+		d.Wait()
+		d.Stop()
+
+		if len(slices) != 1 || slices[0] <= 0 || slices[0] >= 1 {
+			t.Errorf("expected other process to run while steppable task was paused, saw progress %v", slices)
+		}
+
+		completions := d.Completions()
+		if len(completions) != 2 || completions[1].Result != "finished" {
+			t.Errorf("expected steppable task to finish last, got %+v", completions)
+		}
+		if p.GetProgress() != 1 {
+			t.Errorf("expected progress 1, got %v", p.GetProgress())
+		}
+	})
+
+	t.Run("should not preempt non-preemptible tasks", func(t *testing.T) {
+		manager := process.NewManager()
+		d, _ := NewDispatcher(queue.NewRoundRobinQueue(time.Millisecond), manager)
+
+		p, _ := manager.CreateProcess(&types.SimpleTask{
+			ExecuteFn: func() (any, error) {
+				time.Sleep(5 * time.Millisecond)
+				return "done", nil
+			},
+		})
+
+		d.Submit(p)
+		d.Start()
+		d.Wait()
+		d.Stop()
+
+		completions := d.Completions()
+		if len(completions) != 1 || completions[0].Result != "done" {
+			t.Errorf("expected task to run to completion, got %+v", completions)
+		}
+	})
+}
+
+func TestDispatcher_PreemptOnArrival(t *testing.T) {
+	t.Run("should preempt the running process for a shorter arrival", func(t *testing.T) {
+		manager := process.NewManager()
+		d, _ := NewDispatcher(queue.NewSRTFQueue(queue.NewBurstPredictor(0, 0)), manager)
+
+		started := make(chan struct{})
+		var once sync.Once
+		long, _ := manager.CreateProcess(&sliceFuncTask{
+			fn: func(ctx context.Context) (any, error) {
+				once.Do(func() { close(started) })
+				select {
+				case <-ctx.Done():
+					return nil, types.ErrPreempted
+				case <-time.After(200 * time.Millisecond):
+					return "long", nil
+				}
+			},
+		}, process.WithExpectedBurst(time.Second))
+		short, _ := manager.CreateProcess(&types.SimpleTask{
+			ExecuteFn: func() (any, error) { return "short", nil },
+		}, process.WithExpectedBurst(time.Millisecond))
+
+		d.Start()
+		d.Submit(long)
+		<-started
+		d.Submit(short)
+		d.Wait()
+		d.Stop()
+
+		completions := d.Completions()
+		if len(completions) != 2 {
+			t.Fatalf("expected 2 completions, got %d", len(completions))
+		}
+		if completions[0].PID != short.GetPID() {
+			t.Errorf("expected short process to finish first, got PID %d", completions[0].PID)
+		}
+		if len(long.GetBurstHistory()) != 1 {
+			t.Errorf("expected preemption to keep a single burst, got %v", long.GetBurstHistory())
+		}
+	})
+}
+
+func TestDispatcher_PeriodicJobs(t *testing.T) {
+	t.Run("should release a job every period until the job limit", func(t *testing.T) {
+		manager := process.NewManager()
+		q := queue.NewRateMonotonicQueue()
+		d, _ := NewDispatcher(q, manager)
+
+		var mu sync.Mutex
+		var releases []time.Time
+		p, _ := manager.CreateProcess(&types.SimpleTask{
+			ExecuteFn: func() (any, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				releases = append(releases, time.Now())
+				return len(releases), nil
+			},
+		}, process.WithPeriod(20*time.Millisecond), process.WithWCET(time.Millisecond), process.WithJobs(3))
+
+		d.Start()
+		d.Submit(p)
+		d.Wait()
+		d.Stop()
+
+		completions := d.Completions()
+		if len(completions) != 1 || completions[0].Result != 3 {
+			t.Fatalf("expected a single completion after the third job, got %+v", completions)
+		}
+		if gap := releases[2].Sub(releases[0]); gap < 40*time.Millisecond {
+			t.Errorf("expected jobs to be a period apart, got %v for two periods", gap)
+		}
+		if len(p.GetBurstHistory()) != 3 {
+			t.Errorf("expected one burst per job, got %v", p.GetBurstHistory())
+		}
+		if metrics := q.GetRMMetrics(); metrics.Jobs != 3 || metrics.Misses != 0 {
+			t.Errorf("expected 3 jobs on time, got %+v", metrics.DeadlineMetrics)
+		}
+	})
+}
+
+// recordingQueue wraps a queue and records every slice reported to it
+type recordingQueue struct {
+	types.SchedulingQueue
+
+	mu       sync.Mutex
+	outcomes []types.SliceOutcome
+}
+
+func (q *recordingQueue) RecordSlice(p types.Process, ran time.Duration, outcome types.SliceOutcome) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.outcomes = append(q.outcomes, outcome)
+}
+
+func (q *recordingQueue) recorded() []types.SliceOutcome {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return append([]types.SliceOutcome(nil), q.outcomes...)
+}
+
+func TestDispatcher_BlockForIO(t *testing.T) {
+	t.Run("should park a blocked process in WAITING and resume it after its I/O", func(t *testing.T) {
+		manager := process.NewManager()
+		q := &recordingQueue{SchedulingQueue: queue.NewFCFSQueue()}
+		d, _ := NewDispatcher(q, manager)
+
+		var mu sync.Mutex
+		var states []types.ProcessState
+		var blocker types.Process
+		blocker, _ = manager.CreateProcess(process.NewStepTask(2,
+			func(ctx types.ProcessContext, unit uint64) error {
+				if unit == 0 {
+					return types.BlockForIO(20 * time.Millisecond)
+				}
+				return nil
+			},
+			func(ctx types.ProcessContext) any { return "io done" },
+		))
+		other, _ := manager.CreateProcess(&types.SimpleTask{
+			ExecuteFn: func() (any, error) {
+				mu.Lock()
+				states = append(states, blocker.GetState())
+				mu.Unlock()
+				return nil, nil
+			},
+		})
+
+		d.Submit(blocker)
+		d.Submit(other)
+		d.Start()
+		d.Wait()
+		d.Stop()
+
+		if len(states) != 1 || states[0] != types.WAITING {
+			t.Errorf("expected blocked process to be WAITING while the other ran, got %v", states)
+		}
+
+		completions := d.Completions()
+		if len(completions) != 2 || completions[1].PID != blocker.GetPID() || completions[1].Result != "io done" {
+			t.Errorf("expected blocked process to finish last, got %+v", completions)
+		}
+		if len(blocker.GetBurstHistory()) != 2 {
+			t.Errorf("expected two CPU bursts around the I/O, got %v", blocker.GetBurstHistory())
+		}
+
+		outcomes := q.recorded()
+		if len(outcomes) != 3 || outcomes[0] != types.SliceBlocked {
+			t.Errorf("expected blocked, completed, completed slices, got %v", outcomes)
+		}
+	})
+
+	t.Run("should hold waiting processes while stopped", func(t *testing.T) {
+		manager := process.NewManager()
+		q := queue.NewFCFSQueue()
+		d, _ := NewDispatcher(q, manager)
+
+		blocker, _ := manager.CreateProcess(process.NewStepTask(2,
+			func(ctx types.ProcessContext, unit uint64) error {
+				if unit == 0 {
+					return types.BlockForIO(10 * time.Millisecond)
+				}
+				return nil
+			},
+			func(ctx types.ProcessContext) any { return "io done" },
+		))
+
+		d.Submit(blocker)
+		d.Start()
+		for blocker.GetState() != types.WAITING {
+			time.Sleep(time.Millisecond)
+		}
+		d.Stop()
+
+		time.Sleep(30 * time.Millisecond)
+		if blocker.GetState() != types.WAITING || !q.IsEmpty() {
+			t.Fatalf("expected the process to stay WAITING while stopped, got state %d and %d queued", blocker.GetState(), q.Size())
+		}
+
+		d.Start()
+		d.Wait()
+		d.Stop()
+
+		if completions := d.Completions(); len(completions) != 1 || completions[0].Result != "io done" {
+			t.Errorf("expected the process to finish after the restart, got %+v", completions)
+		}
+	})
+}
+
+func TestDispatcher_MLFQ(t *testing.T) {
+	t.Run("should demote CPU bound processes below interactive ones", func(t *testing.T) {
+		manager := process.NewManager()
+		config := queue.DefaultMLFQConfig()
+		config.Levels[0].Quantum = 2 * time.Millisecond
+		config.Levels[1].Quantum = 4 * time.Millisecond
+		mlfq, _ := queue.NewMLFQQueue(config)
+		d, _ := NewDispatcher(mlfq, manager)
+
+		cpuBound, _ := manager.CreateProcess(&sleepTask{units: 30, unit: time.Millisecond})
+		interactive, _ := manager.CreateProcess(process.NewStepTask(3,
+			func(ctx types.ProcessContext, unit uint64) error {
+				return types.BlockForIO(time.Millisecond)
+			}, nil))
+
+		d.Submit(cpuBound)
+		d.Submit(interactive)
+		d.Start()
+		d.Wait()
+		d.Stop()
+
+		metrics := mlfq.GetMLFQMetrics()
+		if metrics.Levels[0].Demotions == 0 || metrics.Levels[1].Demotions == 0 {
+			t.Errorf("expected CPU bound process to be demoted to the bottom, got %+v", metrics.Levels)
+		}
+		if d.Completions()[0].PID != interactive.GetPID() {
+			t.Error("expected interactive process to finish first")
+		}
+	})
+}
+
+type sliceFuncTask struct {
+	fn func(ctx context.Context) (any, error)
+}
+
+func (t *sliceFuncTask) Execute() (any, error) {
+	return t.fn(context.Background())
+}
+
+func (t *sliceFuncTask) ExecuteSlice(ctx context.Context) (any, error) {
+	return t.fn(ctx)
+}
+
+func TestDispatcher_Submit(t *testing.T) {
+	t.Run("should return error for nil process", func(t *testing.T) {
+		d, _ := NewDispatcher(queue.NewFCFSQueue(), process.NewManager())
+		if err := d.Submit(nil); err == nil {
+			t.Error("expected error for nil process")
+		}
+	})
+
+	t.Run("should return error for process unknown to the manager", func(t *testing.T) {
+		d, _ := NewDispatcher(queue.NewFCFSQueue(), process.NewManager())
+		p := process.NewPCB(42, process.NewTask(func() (any, error) { return nil, nil }))
+
+		if err := d.Submit(p); err == nil {
+			t.Error("expected error for unmanaged process")
+		}
+	})
+}
+
+func TestDispatcher_Timeline(t *testing.T) {
+	t.Run("should record every slice with its outcome", func(t *testing.T) {
+		manager := process.NewManager()
+		d, _ := NewDispatcher(queue.NewRoundRobinQueue(5*time.Millisecond), manager)
+
+		long := &sleepTask{units: 12, unit: time.Millisecond}
+		p1, _ := manager.CreateProcess(long)
+		p2, _ := manager.CreateProcess(&sleepTask{units: 2, unit: time.Millisecond})
+
+		d.Submit(p1)
+		d.Submit(p2)
+		d.Start()
+		d.Wait()
+		d.Stop()
+
+		timeline := d.Timeline()
+		if len(timeline) != long.sliceCount()+1 {
+			t.Fatalf("expected a slice per dispatch, got %d", len(timeline))
+		}
+		first, second := timeline[0], timeline[1]
+		if first.PID != p1.GetPID() || first.Outcome != types.SliceExpired {
+			t.Errorf("expected the long process to expire first, got %+v", first)
+		}
+		if second.PID != p2.GetPID() || second.Outcome != types.SliceCompleted {
+			t.Errorf("expected the short process to complete next, got %+v", second)
+		}
+		for i, e := range timeline {
+			if e.CPU != 0 || !e.End.After(e.Start) || e.Overhead != 0 {
+				t.Errorf("unexpected slice %+v", e)
+			}
+			if i > 0 && e.Start.Before(timeline[i-1].End) {
+				t.Errorf("expected slice %d to start after the previous one ended", i)
+			}
+		}
+		if last := timeline[len(timeline)-1]; last.PID != p1.GetPID() || last.Outcome != types.SliceCompleted {
+			t.Errorf("expected the long process to complete last, got %+v", last)
+		}
+	})
+}
+
+func TestDispatcher_MigrationPenalty(t *testing.T) {
+	t.Run("should keep the penalty out of the CPU time of the process", func(t *testing.T) {
+		manager := process.NewManager()
+		d, _ := NewDispatcher(queue.NewFCFSQueue(), manager)
+		p, _ := manager.CreateProcess(&types.SimpleTask{ExecuteFn: func() (any, error) {
+			time.Sleep(5 * time.Millisecond)
+			return nil, nil
+		}})
+		d.penalties[p.GetPID()] = 20 * time.Millisecond
+
+		d.Submit(p)
+		d.Start()
+		d.Wait()
+		d.Stop()
+
+		event := d.Timeline()[0]
+		if event.Overhead != 20*time.Millisecond || event.End.Sub(event.Start) < 25*time.Millisecond {
+			t.Errorf("expected 20ms of overhead in a slice of at least 25ms, got %+v", event)
+		}
+		if cpu := p.GetCPUTime(); cpu < 5*time.Millisecond || cpu >= 20*time.Millisecond {
+			t.Errorf("expected only the task to count as CPU time, got %v", cpu)
+		}
+		if history := p.GetBurstHistory(); len(history) != 1 || history[0] >= 20*time.Millisecond {
+			t.Errorf("expected a burst without the penalty, got %v", history)
+		}
+	})
+
+	t.Run("should preempt a process while it waits out the penalty", func(t *testing.T) {
+		manager := process.NewManager()
+		d, _ := NewDispatcher(queue.NewFCFSQueue(), manager)
+		runs := 0
+		p, _ := manager.CreateProcess(&types.SimpleTask{ExecuteFn: func() (any, error) {
+			runs++
+			return nil, nil
+		}})
+		d.penalties[p.GetPID()] = time.Second
+
+		d.Submit(p)
+		start := time.Now()
+		d.Start()
+		for d.Current() == nil {
+			time.Sleep(time.Millisecond)
+		}
+		d.Preempt()
+		d.Wait()
+		d.Stop()
+
+		if elapsed := time.Since(start); elapsed >= 500*time.Millisecond {
+			t.Errorf("expected the preemption to cut the penalty short, took %v", elapsed)
+		}
+		timeline := d.Timeline()
+		if len(timeline) != 2 || timeline[0].Outcome != types.SlicePreempted || timeline[1].Overhead != 0 {
+			t.Errorf("expected a preempted slice and a run without penalty, got %+v", timeline)
+		}
+		if runs != 1 {
+			t.Errorf("expected the task to run once, got %d", runs)
+		}
+	})
+}
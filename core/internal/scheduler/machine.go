@@ -0,0 +1,1008 @@
+package scheduler
+
+import (
+	"context"
+	"cpu-scheduling/core/internal/process"
+	"cpu-scheduling/core/internal/types"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSampleInterval  = 100 * time.Millisecond
+	defaultBalanceInterval = 10 * time.Millisecond
+	defaultCacheHotTime    = 5 * time.Millisecond
+	// maxUtilizationSamples bounds the utilization history, the oldest
+	// samples are dropped first
+	maxUtilizationSamples = 1000
+)
+
+// RunQueueMode decides whether the cores of a machine share one run queue or
+// each have their own
+type RunQueueMode int
+
+const (
+	// GlobalRunQueue lets every core take the next process from one shared
+	// queue, so no core idles while work is waiting
+	GlobalRunQueue RunQueueMode = iota
+	// PerCoreRunQueues gives every core its own queue. Submitted processes go
+	// to the least loaded core and stay there
+	PerCoreRunQueues
+)
+
+// BalanceStrategy decides how processes move between per-core run queues
+type BalanceStrategy int
+
+const (
+	// NoBalancing keeps processes on the core they were submitted to
+	NoBalancing BalanceStrategy = iota
+	// PushBalancing periodically moves queued processes from the busiest core
+	// to the least busy one until their loads differ by at most one
+	PushBalancing
+	// PullBalancing lets a core that runs out of work steal a queued process
+	// from the busiest core
+	PullBalancing
+	// PushPullBalancing combines periodic balancing with work stealing
+	PushPullBalancing
+)
+
+// BalanceConfig configures load balancing between per-core run queues.
+// Push balancing runs every Interval. A migrated process costs its new core
+// MigrationCost before it runs, plus CachePenalty when it ran on its old core
+// less than CacheHotTime ago and the cores do not share a last level cache
+type BalanceConfig struct {
+	Strategy      BalanceStrategy
+	Interval      time.Duration
+	MigrationCost time.Duration
+	CachePenalty  time.Duration
+	CacheHotTime  time.Duration
+}
+
+func (b BalanceConfig) push() bool {
+	return b.Strategy == PushBalancing || b.Strategy == PushPullBalancing
+}
+
+func (b BalanceConfig) pull() bool {
+	return b.Strategy == PullBalancing || b.Strategy == PushPullBalancing
+}
+
+// MachineConfig configures a multi-core machine. NewQueue creates a run queue
+// and is called once for a global run queue or once per core otherwise. The
+// queues have to be types.SelectiveQueue, so a core can pass over processes
+// its affinity rules out without disturbing them.
+// Utilization is sampled every SampleInterval. Balance only applies to
+// per-core run queues.
+//
+// Topology is optional and gives every core a place in a NUMA machine, in
+// which case Cores may be left zero. Placement picks the core of submitted
+// and migrating processes and defaults to LeastLoadedPlacement
+type MachineConfig struct {
+	Cores          int
+	Mode           RunQueueMode
+	NewQueue       func() (types.SchedulingQueue, error)
+	SampleInterval time.Duration
+	Balance        BalanceConfig
+	Topology       *Topology
+	Placement      PlacementPolicy
+}
+
+// BalanceMetrics reports the migrations between cores. Forced migrations move
+// processes off cores their affinity no longer allows. Imbalance is the
+// difference in load, queued plus running processes, between the busiest and
+// the least busy core, averaged over the utilization samples
+type BalanceMetrics struct {
+	Migrations       int
+	Pushed           int
+	Stolen           int
+	Forced           int
+	MigrationCost    time.Duration
+	PerProcess       map[int]int
+	AverageImbalance float64
+	MaxImbalance     int
+}
+
+// LocalityMetrics reports how much CPU time processes spent on their home
+// node and elsewhere. Stall is the extra time remote memory accesses cost
+type LocalityMetrics struct {
+	LocalTime  time.Duration
+	RemoteTime time.Duration
+	Stall      time.Duration
+	LocalRatio float64
+}
+
+// CoreStats reports how a core has spent its time since the machine started
+type CoreStats struct {
+	ID          int
+	Busy        time.Duration
+	Idle        time.Duration
+	Utilization float64
+	Dispatched  int
+	// Current is the PID of the running process, or 0 when the core is idle
+	Current int
+	// Queued is the length of the run queue of the core, or of the shared
+	// queue with a global run queue
+	Queued int
+}
+
+// UtilizationSample holds the utilization of every core during the interval
+// that ended at At, and the load of every core at that time
+type UtilizationSample struct {
+	At        time.Time
+	Cores     []float64
+	Loads     []int
+	Imbalance int
+}
+
+// Core is a simulated CPU of a machine. It runs its own dispatcher, which
+// owns the process currently on the core
+type Core struct {
+	id         int
+	dispatcher *Dispatcher
+	// queue is the run queue of the dispatcher, which can skip processes
+	// the core may not run
+	queue types.SelectiveQueue
+}
+
+// ID returns the index of the core, starting at 0
+func (c *Core) ID() int {
+	return c.id
+}
+
+// Current returns the process that is running on the core, or nil when idle
+func (c *Core) Current() types.Process {
+	return c.dispatcher.Current()
+}
+
+// Queue returns the run queue the core takes processes from
+func (c *Core) Queue() types.SchedulingQueue {
+	return c.dispatcher.queue
+}
+
+// allows reports whether the affinity of the process lets it run on the core
+func (c *Core) allows(p types.Process) bool {
+	return p != nil && p.GetAffinity().Has(c.id)
+}
+
+// head returns the process the core would run next, or nil if none is queued
+func (c *Core) head() types.Process {
+	p, err := c.dispatcher.queue.Peek()
+	if err != nil {
+		return nil
+	}
+	return p
+}
+
+// load is the number of queued and running processes of the core
+func (c *Core) load() int {
+	load := c.dispatcher.queue.Size()
+	if c.Current() != nil {
+		load++
+	}
+	return load
+}
+
+// Machine is a symmetric multiprocessor with a fixed number of cores. Every
+// core runs a dispatcher, either on one global run queue or on a run queue of
+// its own, and the machine tracks how busy each core is over time
+type Machine struct {
+	cores          []*Core
+	mode           RunQueueMode
+	manager        *process.Manager
+	sampleInterval time.Duration
+	balance        BalanceConfig
+	topology       *Topology
+	placement      PlacementPolicy
+
+	mu          sync.Mutex
+	idle        *sync.Cond
+	running     bool
+	stop        chan struct{}
+	done        chan struct{}
+	startedAt   time.Time
+	stoppedAt   time.Time
+	submitted   map[int]bool
+	completions []Completion
+
+	samples    []UtilizationSample
+	lastSample time.Time
+	lastBusy   []time.Duration
+	locality   LocalityMetrics
+
+	// balancing serializes migrations so two cores cannot both move work
+	// based on the same view of the loads
+	balancing     sync.Mutex
+	migrations    map[int]int
+	pushed        int
+	stolen        int
+	forced        int
+	migrationCost time.Duration
+}
+
+// NewMachine creates a machine with the configured number of cores and run
+// queues
+func NewMachine(config MachineConfig, manager *process.Manager) (*Machine, error) {
+	if config.Topology != nil {
+		if config.Cores == 0 {
+			config.Cores = config.Topology.CPUs()
+		}
+		if config.Cores != config.Topology.CPUs() {
+			return nil, fmt.Errorf("machine has %d cores but its topology has %d CPUs", config.Cores, config.Topology.CPUs())
+		}
+	}
+	if config.Cores <= 0 {
+		return nil, fmt.Errorf("machine needs at least one core, got %d", config.Cores)
+	}
+	if config.Mode != GlobalRunQueue && config.Mode != PerCoreRunQueues {
+		return nil, fmt.Errorf("unknown run queue mode %d", config.Mode)
+	}
+	if config.NewQueue == nil {
+		return nil, fmt.Errorf("cannot create machine without a run queue factory")
+	}
+	if manager == nil {
+		return nil, fmt.Errorf("cannot create machine with nil manager")
+	}
+	if config.SampleInterval <= 0 {
+		config.SampleInterval = defaultSampleInterval
+	}
+	if config.Balance.Strategy < NoBalancing || config.Balance.Strategy > PushPullBalancing {
+		return nil, fmt.Errorf("unknown balance strategy %d", config.Balance.Strategy)
+	}
+	if config.Balance.Strategy != NoBalancing && config.Mode != PerCoreRunQueues {
+		return nil, fmt.Errorf("load balancing needs per-core run queues")
+	}
+	if config.Balance.MigrationCost < 0 || config.Balance.CachePenalty < 0 {
+		return nil, fmt.Errorf("migration cost and cache penalty cannot be negative")
+	}
+	if config.Balance.Interval <= 0 {
+		config.Balance.Interval = defaultBalanceInterval
+	}
+	if config.Balance.CacheHotTime <= 0 {
+		config.Balance.CacheHotTime = defaultCacheHotTime
+	}
+	if config.Placement == nil {
+		config.Placement = LeastLoadedPlacement{}
+	}
+
+	m := &Machine{
+		cores:          make([]*Core, config.Cores),
+		mode:           config.Mode,
+		manager:        manager,
+		sampleInterval: config.SampleInterval,
+		balance:        config.Balance,
+		topology:       config.Topology,
+		placement:      config.Placement,
+		submitted:      make(map[int]bool),
+		lastBusy:       make([]time.Duration, config.Cores),
+		migrations:     make(map[int]int),
+	}
+	m.idle = sync.NewCond(&m.mu)
+
+	var shared types.SchedulingQueue
+	for i := range m.cores {
+		q := shared
+		if q == nil {
+			var err error
+			if q, err = config.NewQueue(); err != nil {
+				return nil, fmt.Errorf("failed to create run queue for core %d: %v", i, err)
+			}
+			if config.Mode == GlobalRunQueue {
+				shared = q
+			}
+		}
+
+		selective, ok := q.(types.SelectiveQueue)
+		if !ok {
+			return nil, fmt.Errorf("run queue of core %d cannot skip processes, got %T", i, q)
+		}
+
+		d, err := NewDispatcher(q, manager)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create core %d: %v", i, err)
+		}
+		d.machine = m
+		m.cores[i] = &Core{id: i, dispatcher: d, queue: selective}
+	}
+
+	return m, nil
+}
+
+// Start launches the dispatcher of every core
+func (m *Machine) Start() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running {
+		return fmt.Errorf("machine is already running")
+	}
+
+	for i, c := range m.cores {
+		if err := c.dispatcher.Start(); err != nil {
+			for _, started := range m.cores[:i] {
+				started.dispatcher.Stop()
+			}
+			return fmt.Errorf("failed to start core %d: %v", c.id, err)
+		}
+	}
+
+	now := time.Now()
+	m.running = true
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+	if m.startedAt.IsZero() {
+		m.startedAt = now
+		m.lastSample = now
+	}
+
+	go m.background(m.stop, m.done)
+	return nil
+}
+
+// Stop stops every core, letting running processes finish their slice
+func (m *Machine) Stop() {
+	m.mu.Lock()
+	if !m.running {
+		m.mu.Unlock()
+		return
+	}
+	m.running = false
+	close(m.stop)
+	done := m.done
+	m.idle.Broadcast()
+	m.mu.Unlock()
+
+	<-done
+	for _, c := range m.cores {
+		c.dispatcher.Stop()
+	}
+
+	m.mu.Lock()
+	m.stoppedAt = time.Now()
+	m.mu.Unlock()
+}
+
+// Wait blocks until every process handed to Submit has completed, or until
+// the machine is stopped
+func (m *Machine) Wait() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for m.running && len(m.submitted) > 0 {
+		m.idle.Wait()
+	}
+}
+
+// Submit admits a process to the machine, moving it to READY if it is NEW.
+// With per-core run queues the placement policy picks one of the cores its
+// affinity allows
+func (m *Machine) Submit(p types.Process) error {
+	if p == nil {
+		return fmt.Errorf("cannot submit nil process")
+	}
+
+	if p.GetAffinity()&types.FirstCPUs(len(m.cores)) == 0 {
+		return fmt.Errorf("process %d may not run on any core of the machine", p.GetPID())
+	}
+
+	if p.GetState() == types.NEW {
+		if err := m.manager.SetProcessState(p.GetPID(), types.READY); err != nil {
+			return fmt.Errorf("failed to admit process %d: %v", p.GetPID(), err)
+		}
+	}
+
+	m.mu.Lock()
+	m.submitted[p.GetPID()] = true
+	m.mu.Unlock()
+
+	var err error
+	if m.mode == GlobalRunQueue {
+		err = m.arrive(p, nil)
+	} else {
+		err = m.place(p, nil, nil).dispatcher.enqueue(p)
+	}
+	if err != nil {
+		m.finish(p.GetPID())
+		return fmt.Errorf("failed to enqueue process %d: %v", p.GetPID(), err)
+	}
+	return nil
+}
+
+// Cores returns the cores of the machine in ID order
+func (m *Machine) Cores() []*Core {
+	cores := make([]*Core, len(m.cores))
+	copy(cores, m.cores)
+	return cores
+}
+
+// Completions returns the processes that have finished on any core, in
+// completion order
+func (m *Machine) Completions() []Completion {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make([]Completion, len(m.completions))
+	copy(result, m.completions)
+	return result
+}
+
+// Timeline returns the slices run on every core, with the core as their CPU,
+// in dispatch order
+func (m *Machine) Timeline() []DispatchEvent {
+	var result []DispatchEvent
+	for _, c := range m.cores {
+		for _, e := range c.dispatcher.Timeline() {
+			e.CPU = c.id
+			result = append(result, e)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Start.Before(result[j].Start)
+	})
+	return result
+}
+
+// Stats returns the busy and idle time of every core since the machine was
+// first started
+func (m *Machine) Stats() []CoreStats {
+	m.mu.Lock()
+	startedAt, end := m.startedAt, m.stoppedAt
+	if m.running || end.IsZero() {
+		end = time.Now()
+	}
+	m.mu.Unlock()
+
+	var elapsed time.Duration
+	if !startedAt.IsZero() {
+		elapsed = end.Sub(startedAt)
+	}
+
+	stats := make([]CoreStats, len(m.cores))
+	for i, c := range m.cores {
+		d := c.dispatcher
+		d.mu.Lock()
+		s := CoreStats{
+			ID:         c.id,
+			Busy:       d.busyTime(end),
+			Dispatched: d.dispatched,
+		}
+		if d.current != nil {
+			s.Current = d.current.GetPID()
+		}
+		d.mu.Unlock()
+
+		s.Queued = d.queue.Size()
+		if elapsed > 0 {
+			if s.Busy > elapsed {
+				s.Busy = elapsed
+			}
+			s.Idle = elapsed - s.Busy
+			s.Utilization = float64(s.Busy) / float64(elapsed)
+		}
+		stats[i] = s
+	}
+	return stats
+}
+
+// Topology returns the topology of the machine, or nil if it has none
+func (m *Machine) Topology() *Topology {
+	return m.topology
+}
+
+// Samples returns the per-core utilization history, oldest first
+func (m *Machine) Samples() []UtilizationSample {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make([]UtilizationSample, len(m.samples))
+	copy(result, m.samples)
+	return result
+}
+
+// BalanceMetrics returns the migrations so far and the imbalance between cores
+// over time
+func (m *Machine) BalanceMetrics() BalanceMetrics {
+	m.balancing.Lock()
+	metrics := BalanceMetrics{
+		Pushed:        m.pushed,
+		Stolen:        m.stolen,
+		Forced:        m.forced,
+		MigrationCost: m.migrationCost,
+		PerProcess:    make(map[int]int, len(m.migrations)),
+	}
+	for pid, n := range m.migrations {
+		metrics.PerProcess[pid] = n
+		metrics.Migrations += n
+	}
+	m.balancing.Unlock()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	total := 0
+	for _, s := range m.samples {
+		total += s.Imbalance
+		if s.Imbalance > metrics.MaxImbalance {
+			metrics.MaxImbalance = s.Imbalance
+		}
+	}
+	if len(m.samples) > 0 {
+		metrics.AverageImbalance = float64(total) / float64(len(m.samples))
+	}
+	return metrics
+}
+
+// LocalityMetrics returns how much of the CPU time of processes ran on their
+// home node. It stays empty on a machine without a topology
+func (m *Machine) LocalityMetrics() LocalityMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	metrics := m.locality
+	if total := metrics.LocalTime + metrics.RemoteTime; total > 0 {
+		metrics.LocalRatio = float64(metrics.LocalTime) / float64(total)
+	}
+	return metrics
+}
+
+// arrive routes a process that became ready. With a global run queue it goes
+// on the shared queue and every core is woken. Otherwise it stays on the core
+// it last ran on, unless its affinity no longer allows that core
+func (m *Machine) arrive(p types.Process, from *Dispatcher) error {
+	if m.mode == PerCoreRunQueues {
+		if from == nil {
+			return fmt.Errorf("process %d has no core to return to", p.GetPID())
+		}
+		core := m.coreOf(from)
+		if core.allows(p) {
+			return from.enqueue(p)
+		}
+
+		m.balancing.Lock()
+		defer m.balancing.Unlock()
+
+		return m.relocate(p, core)
+	}
+
+	shared := m.cores[0].dispatcher.queue
+	if err := shared.Enqueue(p); err != nil {
+		return err
+	}
+
+	idle := false
+	for _, c := range m.cores {
+		c.dispatcher.notify()
+		if c.allows(p) && c.Current() == nil {
+			idle = true
+		}
+	}
+
+	// An idle core picks the process up, so only preempt when all the cores
+	// it may run on are busy
+	if q, ok := shared.(types.PreemptiveQueue); ok && !idle {
+		for _, c := range m.cores {
+			if running := c.Current(); running != nil && c.allows(p) && q.ShouldPreempt(running, p) {
+				c.dispatcher.Preempt()
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// next returns the next process the core of the dispatcher may run. A process
+// that may not run on the core is moved to a core it may run on or, with a
+// global run queue, left on the queue for another core
+func (m *Machine) next(d *Dispatcher) (types.Process, error) {
+	core := m.coreOf(d)
+
+	if m.mode == PerCoreRunQueues {
+		for m.evict(core) {
+		}
+	}
+
+	// Processes the core may not run keep their place for the other cores
+	p, err := core.queue.DequeueMatching(core.allows)
+	if err != nil {
+		if m.mode == GlobalRunQueue && !core.queue.IsEmpty() {
+			m.notify(core)
+		}
+		return nil, fmt.Errorf("no queued process may run on core %d", core.id)
+	}
+	return p, nil
+}
+
+// SetAffinity restricts a process to the cores in the mask, which must
+// include at least one core of the machine. A running process that is no
+// longer allowed on its core is preempted and migrates to a core it may run
+// on, and a queued one moves when its core reaches it
+func (m *Machine) SetAffinity(p types.Process, mask types.CPUMask) error {
+	if p == nil {
+		return fmt.Errorf("cannot set affinity of nil process")
+	}
+	if mask&types.FirstCPUs(len(m.cores)) == 0 {
+		return fmt.Errorf("affinity of process %d must include a core between 0 and %d", p.GetPID(), len(m.cores)-1)
+	}
+	if err := p.SetAffinity(mask); err != nil {
+		return err
+	}
+
+	for _, c := range m.cores {
+		if running := c.Current(); running != nil && running.GetPID() == p.GetPID() && !c.allows(p) {
+			c.dispatcher.Preempt()
+		}
+	}
+	m.notify(nil)
+	return nil
+}
+
+// place asks the placement policy for a core the process may run on, leaving
+// out the excluded core. Prev is the core the process last ran on, if any. It
+// returns nil if the process may not run on any core
+func (m *Machine) place(p types.Process, prev, exclude *Core) *Core {
+	var candidates []CoreLoad
+	for _, c := range m.cores {
+		if c != exclude && c.allows(p) {
+			candidates = append(candidates, CoreLoad{CPU: c.id, Load: c.load()})
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	prevID := -1
+	if prev != nil {
+		prevID = prev.id
+	}
+	cpu := m.placement.Place(p, prevID, candidates, m.topology)
+	for _, c := range candidates {
+		if c.CPU == cpu {
+			return m.cores[cpu]
+		}
+	}
+	// A policy that picks a core the process may not use is overruled
+	return m.cores[leastLoaded(candidates, types.AllCPUs)]
+}
+
+// steal lets the idle core of the dispatcher pull a queued process from
+// another core whose next process may run on it. Like the scheduling domains
+// of Linux it looks at the cores sharing the most resources first and takes
+// from the busiest core of the first domain with work. It reports whether a
+// process was moved
+func (m *Machine) steal(thief *Dispatcher) bool {
+	if !m.balance.pull() {
+		return false
+	}
+
+	m.balancing.Lock()
+	defer m.balancing.Unlock()
+
+	to := m.coreOf(thief)
+	var victim *Core
+	for _, domain := range m.domains(to) {
+		for _, c := range m.cores {
+			if c == to || !domain.Has(c.id) || !to.allows(c.head()) {
+				continue
+			}
+			if victim == nil || c.load() > victim.load() {
+				victim = c
+			}
+		}
+		if victim != nil {
+			break
+		}
+	}
+	if victim == nil || !m.migrate(victim, to) {
+		return false
+	}
+
+	m.stolen++
+	return true
+}
+
+// push moves queued processes from the busiest core to a core the placement
+// policy picks among those they may run on, until the loads of such pairs
+// differ by at most one process
+func (m *Machine) push() {
+	m.balancing.Lock()
+	defer m.balancing.Unlock()
+
+	// Every move narrows the gap, so this ends after a bounded number of
+	// moves even while processes keep arriving
+	for moves := 0; moves < len(m.cores)*len(m.cores); moves++ {
+		busiest := m.cores[0]
+		for _, c := range m.cores[1:] {
+			if c.load() > busiest.load() {
+				busiest = c
+			}
+		}
+
+		head := busiest.head()
+		if head == nil {
+			return
+		}
+		idlest := m.place(head, busiest, busiest)
+		if idlest == nil || busiest.load()-idlest.load() <= 1 || !m.migrate(busiest, idlest) {
+			return
+		}
+		m.pushed++
+	}
+}
+
+// evict moves the next queued process of a core to another core when its
+// affinity changed while it waited, without counting it as dispatched on the
+// core it leaves. It reports whether a process was moved
+func (m *Machine) evict(c *Core) bool {
+	m.balancing.Lock()
+	defer m.balancing.Unlock()
+
+	head := c.head()
+	if head == nil || c.allows(head) || c.queue.Remove(head) != nil {
+		return false
+	}
+	if err := m.relocate(head, c); err != nil {
+		c.dispatcher.complete(head.GetPID(), nil, fmt.Errorf("failed to migrate process: %v", err))
+	}
+	return true
+}
+
+// relocate moves a process off a core its affinity no longer allows, to a
+// core the placement policy picks. It must be called with balancing held
+func (m *Machine) relocate(p types.Process, from *Core) error {
+	to := m.place(p, from, nil)
+	if to == nil {
+		return fmt.Errorf("process %d may not run on any core", p.GetPID())
+	}
+	if err := m.move(p, from, to); err != nil {
+		return err
+	}
+	m.forced++
+	return nil
+}
+
+// migrate takes the next queued process of one core off its queue, without
+// counting it as dispatched there, and moves it to another. A process that
+// may not run on the other core stays where it is. It must be called with
+// balancing held
+func (m *Machine) migrate(from, to *Core) bool {
+	p := from.head()
+	if p == nil || !to.allows(p) || from.queue.Remove(p) != nil {
+		return false
+	}
+
+	if err := m.move(p, from, to); err != nil {
+		to.dispatcher.complete(p.GetPID(), nil, fmt.Errorf("failed to migrate process: %v", err))
+		return false
+	}
+	return true
+}
+
+// move hands a process that has left the queue of one core to another,
+// charging the migration cost to the new core. It must be called with
+// balancing held
+func (m *Machine) move(p types.Process, from, to *Core) error {
+	if q, ok := from.dispatcher.queue.(types.FeedbackQueue); ok {
+		q.RecordSlice(p, 0, types.SliceMigrated)
+	}
+
+	cost := m.balance.MigrationCost
+	from.dispatcher.mu.Lock()
+	lastRun, ran := from.dispatcher.lastRun[p.GetPID()]
+	delete(from.dispatcher.lastRun, p.GetPID())
+	from.dispatcher.mu.Unlock()
+	if ran && time.Since(lastRun) < m.balance.CacheHotTime && !m.sharesCache(from, to) {
+		cost += m.balance.CachePenalty
+	}
+
+	if cost > 0 {
+		to.dispatcher.mu.Lock()
+		to.dispatcher.penalties[p.GetPID()] += cost
+		to.dispatcher.mu.Unlock()
+	}
+	if err := to.dispatcher.enqueue(p); err != nil {
+		return err
+	}
+
+	m.migrations[p.GetPID()]++
+	m.migrationCost += cost
+	return nil
+}
+
+// disallows reports whether the process has to leave the core of the
+// dispatcher because of its affinity. With a global run queue it simply goes
+// back on the shared queue
+func (m *Machine) disallows(d *Dispatcher, p types.Process) bool {
+	return m.mode == PerCoreRunQueues && !m.coreOf(d).allows(p)
+}
+
+// slowdown returns how many times longer a process takes on the core of the
+// dispatcher than on its home node. A process without a home node gets the
+// node of the core it first runs on
+func (m *Machine) slowdown(d *Dispatcher, p types.Process) float64 {
+	if m.topology == nil {
+		return 1
+	}
+
+	cpu := m.coreOf(d).id
+	home := p.GetHomeNode()
+	if home == types.NoHomeNode {
+		home = m.topology.CPU(cpu).Node
+		if err := p.SetHomeNode(home); err != nil {
+			return 1
+		}
+	}
+	return m.topology.Slowdown(cpu, home)
+}
+
+// stall holds the core for the remote memory accesses of work done at the
+// given slowdown, until the slice ends at the latest, and accounts for the
+// locality of the slice
+func (m *Machine) stall(slice context.Context, worked time.Duration, slowdown float64) {
+	if m.topology == nil {
+		return
+	}
+
+	var stalled time.Duration
+	if extra := time.Duration(float64(worked) * (slowdown - 1)); extra > 0 {
+		begin := time.Now()
+		timer := time.NewTimer(extra)
+		select {
+		case <-timer.C:
+		case <-slice.Done():
+			timer.Stop()
+		}
+		stalled = time.Since(begin)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if slowdown > 1 {
+		m.locality.RemoteTime += worked + stalled
+	} else {
+		m.locality.LocalTime += worked
+	}
+	m.locality.Stall += stalled
+}
+
+// workContext shortens a slice for a process that gets through its work
+// slowdown times slower than at home, leaving the rest of the slice for the
+// stall
+func workContext(slice context.Context, slowdown float64) (context.Context, context.CancelFunc) {
+	deadline, ok := slice.Deadline()
+	if !ok || slowdown <= 1 {
+		return context.WithCancel(slice)
+	}
+	return context.WithDeadline(slice, time.Now().Add(time.Duration(float64(time.Until(deadline))/slowdown)))
+}
+
+// domains returns the CPUs of the scheduling domains of the core, innermost
+// first. Without a topology there is a single domain of all cores
+func (m *Machine) domains(c *Core) []types.CPUMask {
+	if m.topology == nil {
+		return []types.CPUMask{types.FirstCPUs(len(m.cores))}
+	}
+
+	var masks []types.CPUMask
+	for _, d := range m.topology.Domains(c.id) {
+		masks = append(masks, d.CPUs)
+	}
+	return masks
+}
+
+// sharesCache reports whether two cores share a last level cache, which
+// without a topology they never do
+func (m *Machine) sharesCache(a, b *Core) bool {
+	return m.topology != nil && m.topology.SharesCache(a.id, b.id)
+}
+
+func (m *Machine) coreOf(d *Dispatcher) *Core {
+	for _, c := range m.cores {
+		if c.dispatcher == d {
+			return c
+		}
+	}
+	return nil
+}
+
+// notify wakes every core except the given one
+func (m *Machine) notify(except *Core) {
+	for _, c := range m.cores {
+		if c != except {
+			c.dispatcher.notify()
+		}
+	}
+}
+
+func (m *Machine) complete(c Completion) {
+	m.mu.Lock()
+	m.completions = append(m.completions, c)
+	m.mu.Unlock()
+
+	m.finish(c.PID)
+}
+
+func (m *Machine) finish(pid int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.submitted, pid)
+	if len(m.submitted) == 0 {
+		m.idle.Broadcast()
+	}
+}
+
+// background records the utilization of every core once per sample interval
+// and runs push balancing once per balance interval
+func (m *Machine) background(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	sampler := time.NewTicker(m.sampleInterval)
+	defer sampler.Stop()
+
+	var balance <-chan time.Time
+	if m.balance.push() {
+		balancer := time.NewTicker(m.balance.Interval)
+		defer balancer.Stop()
+		balance = balancer.C
+	}
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-sampler.C:
+			m.record(now)
+		case <-balance:
+			m.push()
+		}
+	}
+}
+
+func (m *Machine) record(now time.Time) {
+	busy := make([]time.Duration, len(m.cores))
+	loads := make([]int, len(m.cores))
+	minLoad, maxLoad := 0, 0
+	for i, c := range m.cores {
+		c.dispatcher.mu.Lock()
+		busy[i] = c.dispatcher.busyTime(now)
+		c.dispatcher.mu.Unlock()
+
+		loads[i] = c.load()
+		if i == 0 || loads[i] < minLoad {
+			minLoad = loads[i]
+		}
+		if loads[i] > maxLoad {
+			maxLoad = loads[i]
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	interval := now.Sub(m.lastSample)
+	if interval <= 0 {
+		return
+	}
+
+	sample := UtilizationSample{
+		At:        now,
+		Cores:     make([]float64, len(m.cores)),
+		Loads:     loads,
+		Imbalance: maxLoad - minLoad,
+	}
+	for i := range m.cores {
+		u := float64(busy[i]-m.lastBusy[i]) / float64(interval)
+		if u > 1 {
+			u = 1
+		}
+		sample.Cores[i] = u
+	}
+
+	m.samples = append(m.samples, sample)
+	if len(m.samples) > maxUtilizationSamples {
+		m.samples = m.samples[len(m.samples)-maxUtilizationSamples:]
+	}
+	m.lastSample = now
+	m.lastBusy = busy
+}
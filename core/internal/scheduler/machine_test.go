@@ -0,0 +1,701 @@
+package scheduler
+
+import (
+	"context"
+	"cpu-scheduling/core/internal/process"
+	"cpu-scheduling/core/internal/queue"
+	"cpu-scheduling/core/internal/types"
+	"sync"
+	"testing"
+	"time"
+)
+
+func fcfsQueues() (types.SchedulingQueue, error) {
+	return queue.NewFCFSQueue(), nil
+}
+
+// plainQueue hides every method of a queue beyond types.SchedulingQueue
+type plainQueue struct {
+	types.SchedulingQueue
+}
+
+// submitSleepers submits n processes that each hold a core for the given time
+// and records which processes ran at the same time
+func submitSleepers(t *testing.T, m *Machine, manager *process.Manager, n int, d time.Duration) *int {
+	t.Helper()
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+	for i := 0; i < n; i++ {
+		p, _ := manager.CreateProcess(&types.SimpleTask{
+			ExecuteFn: func() (any, error) {
+				mu.Lock()
+				running++
+				if running > peak {
+					peak = running
+				}
+				mu.Unlock()
+
+				time.Sleep(d)
+
+				mu.Lock()
+				running--
+				mu.Unlock()
+				return nil, nil
+			},
+		})
+		if err := m.Submit(p); err != nil {
+			t.Fatalf("unexpected submit error: %v", err)
+		}
+	}
+	return &peak
+}
+
+func TestNewMachine(t *testing.T) {
+	t.Run("should return error for an invalid config", func(t *testing.T) {
+		manager := process.NewManager()
+		configs := map[string]MachineConfig{
+			"no cores":      {Cores: 0, NewQueue: fcfsQueues},
+			"unknown mode":  {Cores: 1, Mode: RunQueueMode(7), NewQueue: fcfsQueues},
+			"no queue func": {Cores: 1},
+			"plain queue": {Cores: 1, NewQueue: func() (types.SchedulingQueue, error) {
+				return plainQueue{queue.NewFCFSQueue()}, nil
+			}},
+		}
+		for name, config := range configs {
+			if _, err := NewMachine(config, manager); err == nil {
+				t.Errorf("%s: expected error", name)
+			}
+		}
+
+		if _, err := NewMachine(MachineConfig{Cores: 1, NewQueue: fcfsQueues}, nil); err == nil {
+			t.Error("expected error for nil manager")
+		}
+	})
+
+	t.Run("should share one queue in global mode", func(t *testing.T) {
+		m, _ := NewMachine(MachineConfig{Cores: 3, Mode: GlobalRunQueue, NewQueue: fcfsQueues}, process.NewManager())
+
+		cores := m.Cores()
+		if len(cores) != 3 || cores[0].Queue() != cores[2].Queue() {
+			t.Error("expected three cores on the same queue")
+		}
+	})
+
+	t.Run("should give every core its own queue in per-core mode", func(t *testing.T) {
+		m, _ := NewMachine(MachineConfig{Cores: 2, Mode: PerCoreRunQueues, NewQueue: fcfsQueues}, process.NewManager())
+
+		cores := m.Cores()
+		if cores[0].Queue() == cores[1].Queue() {
+			t.Error("expected separate queues")
+		}
+	})
+}
+
+func TestMachine_Run(t *testing.T) {
+	for _, mode := range []RunQueueMode{GlobalRunQueue, PerCoreRunQueues} {
+		t.Run("should run processes on all cores in parallel", func(t *testing.T) {
+			manager := process.NewManager()
+			m, _ := NewMachine(MachineConfig{Cores: 4, Mode: mode, NewQueue: fcfsQueues}, manager)
+
+			peak := submitSleepers(t, m, manager, 8, 30*time.Millisecond)
+			start := time.Now()
+			m.Start()
+			m.Wait()
+			elapsed := time.Since(start)
+			m.Stop()
+
+			if len(m.Completions()) != 8 {
+				t.Fatalf("expected 8 completions, got %d", len(m.Completions()))
+			}
+			if *peak != 4 {
+				t.Errorf("mode %d: expected 4 processes running at once, got %d", mode, *peak)
+			}
+			if elapsed > 150*time.Millisecond {
+				t.Errorf("mode %d: expected two rounds of 30ms, took %v", mode, elapsed)
+			}
+			for _, s := range m.Stats() {
+				if s.Dispatched != 2 {
+					t.Errorf("mode %d: expected core %d to run 2 processes, got %d", mode, s.ID, s.Dispatched)
+				}
+			}
+		})
+	}
+
+	t.Run("should return blocked processes to the core they ran on", func(t *testing.T) {
+		manager := process.NewManager()
+		m, _ := NewMachine(MachineConfig{Cores: 2, Mode: PerCoreRunQueues, NewQueue: fcfsQueues}, manager)
+
+		var mu sync.Mutex
+		cores := map[int][]int{}
+		for i := 0; i < 2; i++ {
+			blocked := false
+			var p types.Process
+			p, _ = manager.CreateProcess(&types.SimpleTask{
+				ExecuteFn: func() (any, error) {
+					for _, c := range m.Cores() {
+						if c.Current() == p {
+							mu.Lock()
+							cores[p.GetPID()] = append(cores[p.GetPID()], c.ID())
+							mu.Unlock()
+						}
+					}
+					if !blocked {
+						blocked = true
+						return nil, &types.IOWaitError{Duration: 5 * time.Millisecond}
+					}
+					return nil, nil
+				},
+			})
+			m.Submit(p)
+		}
+
+		m.Start()
+		m.Wait()
+		m.Stop()
+
+		for pid, ran := range cores {
+			if len(ran) != 2 || ran[0] != ran[1] {
+				t.Errorf("expected process %d to run twice on one core, got cores %v", pid, ran)
+			}
+		}
+	})
+}
+
+func TestMachine_Stats(t *testing.T) {
+	t.Run("should report per-core utilization over time", func(t *testing.T) {
+		manager := process.NewManager()
+		m, _ := NewMachine(MachineConfig{
+			Cores:          2,
+			Mode:           PerCoreRunQueues,
+			NewQueue:       fcfsQueues,
+			SampleInterval: 10 * time.Millisecond,
+		}, manager)
+
+		// Only the first core gets work
+		submitSleepers(t, m, manager, 1, 60*time.Millisecond)
+		m.Start()
+		m.Wait()
+		time.Sleep(60 * time.Millisecond)
+		m.Stop()
+
+		stats := m.Stats()
+		if stats[0].Busy < 60*time.Millisecond || stats[0].Utilization <= 0 || stats[0].Utilization >= 1 {
+			t.Errorf("expected the first core to be partly busy, got %+v", stats[0])
+		}
+		if stats[1].Busy != 0 || stats[1].Utilization != 0 || stats[1].Idle == 0 {
+			t.Errorf("expected the second core to be idle, got %+v", stats[1])
+		}
+		if stats[0].Busy+stats[0].Idle != stats[1].Idle {
+			t.Errorf("expected busy and idle time to add up to the elapsed time, got %+v", stats)
+		}
+
+		samples := m.Samples()
+		if len(samples) < 5 {
+			t.Fatalf("expected a sample every 10ms, got %d", len(samples))
+		}
+		if samples[0].Cores[0] < 0.5 || samples[0].Cores[1] != 0 {
+			t.Errorf("expected the first sample to show only core 0 busy, got %v", samples[0].Cores)
+		}
+		if last := samples[len(samples)-1]; last.Cores[0] != 0 {
+			t.Errorf("expected core 0 to be idle at the end, got %v", last.Cores)
+		}
+	})
+}
+
+// submitSkewed submits one long process and five short ones to a two core
+// machine. The long one and two short ones land on core 0, which falls behind
+func submitSkewed(t *testing.T, m *Machine, manager *process.Manager) {
+	t.Helper()
+
+	for i, d := range []time.Duration{100, 10, 10, 10, 10, 10} {
+		d := d * time.Millisecond
+		p, _ := manager.CreateProcess(&types.SimpleTask{
+			ExecuteFn: func() (any, error) {
+				time.Sleep(d)
+				return nil, nil
+			},
+		})
+		if err := m.Submit(p); err != nil {
+			t.Fatalf("unexpected submit error for process %d: %v", i, err)
+		}
+	}
+}
+
+func TestMachine_Balance(t *testing.T) {
+	t.Run("should return error for balancing a global run queue", func(t *testing.T) {
+		_, err := NewMachine(MachineConfig{
+			Cores:    2,
+			Mode:     GlobalRunQueue,
+			NewQueue: fcfsQueues,
+			Balance:  BalanceConfig{Strategy: PullBalancing},
+		}, process.NewManager())
+		if err == nil {
+			t.Error("expected error for balancing without per-core queues")
+		}
+	})
+
+	t.Run("should leave an imbalance without balancing", func(t *testing.T) {
+		manager := process.NewManager()
+		m, _ := NewMachine(MachineConfig{
+			Cores:          2,
+			Mode:           PerCoreRunQueues,
+			NewQueue:       fcfsQueues,
+			SampleInterval: 5 * time.Millisecond,
+		}, manager)
+
+		submitSkewed(t, m, manager)
+		m.Start()
+		m.Wait()
+		m.Stop()
+
+		metrics := m.BalanceMetrics()
+		if metrics.Migrations != 0 {
+			t.Errorf("expected no migrations, got %d", metrics.Migrations)
+		}
+		if metrics.MaxImbalance < 2 || metrics.AverageImbalance <= 0 {
+			t.Errorf("expected core 0 to fall behind, got %+v", metrics)
+		}
+	})
+
+	strategies := map[string]BalanceStrategy{"push": PushBalancing, "pull": PullBalancing}
+	for name, strategy := range strategies {
+		t.Run("should move waiting work to the idle core with "+name+" balancing", func(t *testing.T) {
+			manager := process.NewManager()
+			m, _ := NewMachine(MachineConfig{
+				Cores:    2,
+				Mode:     PerCoreRunQueues,
+				NewQueue: fcfsQueues,
+				Balance: BalanceConfig{
+					Strategy:      strategy,
+					Interval:      5 * time.Millisecond,
+					MigrationCost: time.Millisecond,
+				},
+			}, manager)
+
+			submitSkewed(t, m, manager)
+			start := time.Now()
+			m.Start()
+			m.Wait()
+			elapsed := time.Since(start)
+			m.Stop()
+
+			metrics := m.BalanceMetrics()
+			if metrics.Migrations == 0 || metrics.Pushed+metrics.Stolen != metrics.Migrations {
+				t.Fatalf("expected migrations by %s balancing, got %+v", name, metrics)
+			}
+			if metrics.MigrationCost != time.Duration(metrics.Migrations)*time.Millisecond {
+				t.Errorf("expected 1ms per migration, got %v for %d", metrics.MigrationCost, metrics.Migrations)
+			}
+			for pid, n := range metrics.PerProcess {
+				if n != 1 {
+					t.Errorf("expected process %d to migrate once, got %d", pid, n)
+				}
+			}
+			if elapsed >= 120*time.Millisecond {
+				t.Errorf("expected the short processes to finish alongside the long one, took %v", elapsed)
+			}
+		})
+	}
+
+	t.Run("should take migrating processes off their queue without dispatching them", func(t *testing.T) {
+		manager := process.NewManager()
+		m, _ := NewMachine(MachineConfig{Cores: 2, Mode: PerCoreRunQueues, NewQueue: fcfsQueues}, manager)
+		cores := m.Cores()
+
+		free, _ := manager.CreateProcess(&types.SimpleTask{})
+		pinned, _ := manager.CreateProcess(&types.SimpleTask{}, process.WithAffinity(0))
+		cores[0].Queue().Enqueue(free)
+		cores[0].Queue().Enqueue(pinned)
+
+		m.balancing.Lock()
+		moved := m.migrate(cores[0], cores[1])
+		refused := m.migrate(cores[0], cores[1])
+		m.balancing.Unlock()
+
+		if !moved || refused {
+			t.Errorf("expected only the free process to move, got %v and %v", moved, refused)
+		}
+		if head, _ := cores[0].Queue().Peek(); cores[0].Queue().Size() != 1 || head.GetPID() != pinned.GetPID() {
+			t.Errorf("expected the pinned process to stay queued on core 0, got %v", head)
+		}
+		if metrics := cores[0].Queue().GetMetrics(); metrics != (types.SchedulingMetrics{}) {
+			t.Errorf("expected no dispatches on core 0, got %+v", metrics)
+		}
+	})
+
+	t.Run("should charge the cache penalty for cache hot processes", func(t *testing.T) {
+		manager := process.NewManager()
+		m, _ := NewMachine(MachineConfig{
+			Cores:    2,
+			Mode:     PerCoreRunQueues,
+			NewQueue: fcfsQueues,
+			Balance: BalanceConfig{
+				Strategy:      PullBalancing,
+				MigrationCost: time.Millisecond,
+				CachePenalty:  4 * time.Millisecond,
+				CacheHotTime:  time.Second,
+			},
+		}, manager)
+		cores := m.Cores()
+
+		hot, _ := manager.CreateProcess(&types.SimpleTask{ExecuteFn: func() (any, error) { return nil, nil }})
+		cold, _ := manager.CreateProcess(&types.SimpleTask{ExecuteFn: func() (any, error) { return nil, nil }})
+		cores[0].dispatcher.lastRun[hot.GetPID()] = time.Now()
+		cores[0].Queue().Enqueue(hot)
+		cores[0].Queue().Enqueue(cold)
+
+		m.balancing.Lock()
+		m.migrate(cores[0], cores[1])
+		m.migrate(cores[0], cores[1])
+		m.balancing.Unlock()
+
+		penalties := cores[1].dispatcher.penalties
+		if penalties[hot.GetPID()] != 5*time.Millisecond || penalties[cold.GetPID()] != time.Millisecond {
+			t.Errorf("expected 5ms for the hot and 1ms for the cold process, got %v", penalties)
+		}
+	})
+}
+
+// coreOf returns the ID of the core the process is running on, or -1
+func coreOf(m *Machine, p types.Process) int {
+	for _, c := range m.Cores() {
+		if running := c.Current(); running != nil && running.GetPID() == p.GetPID() {
+			return c.ID()
+		}
+	}
+	return -1
+}
+
+func TestMachine_Affinity(t *testing.T) {
+	t.Run("should reject processes that may not run on any core", func(t *testing.T) {
+		manager := process.NewManager()
+		m, _ := NewMachine(MachineConfig{Cores: 2, Mode: PerCoreRunQueues, NewQueue: fcfsQueues}, manager)
+
+		p, _ := manager.CreateProcess(&types.SimpleTask{}, process.WithAffinity(5))
+		if err := m.Submit(p); err == nil {
+			t.Error("expected error for affinity outside the machine")
+		}
+		if err := m.SetAffinity(p, 1<<3); err == nil {
+			t.Error("expected error for changing affinity to cores outside the machine")
+		}
+		if p.GetState() != types.NEW {
+			t.Errorf("expected the rejected process to stay NEW, got %v", p.GetState())
+		}
+
+		p.SetAffinity(1 << 1)
+		if err := m.Submit(p); err != nil {
+			t.Errorf("expected the process to be admitted once it may run, got %v", err)
+		}
+	})
+
+	for _, mode := range []RunQueueMode{GlobalRunQueue, PerCoreRunQueues} {
+		t.Run("should only run pinned processes on their cores", func(t *testing.T) {
+			manager := process.NewManager()
+			config := MachineConfig{Cores: 2, Mode: mode, NewQueue: fcfsQueues}
+			if mode == PerCoreRunQueues {
+				config.Balance = BalanceConfig{Strategy: PushPullBalancing, Interval: time.Millisecond}
+			}
+			m, _ := NewMachine(config, manager)
+
+			var mu sync.Mutex
+			ran := map[int]bool{}
+			for i := 0; i < 4; i++ {
+				var p types.Process
+				p, _ = manager.CreateProcess(&types.SimpleTask{
+					ExecuteFn: func() (any, error) {
+						mu.Lock()
+						ran[coreOf(m, p)] = true
+						mu.Unlock()
+						time.Sleep(5 * time.Millisecond)
+						return nil, nil
+					},
+				}, process.WithAffinity(1))
+				if err := m.Submit(p); err != nil {
+					t.Fatalf("unexpected submit error: %v", err)
+				}
+			}
+
+			m.Start()
+			m.Wait()
+			m.Stop()
+
+			if len(m.Completions()) != 4 {
+				t.Fatalf("mode %d: expected 4 completions, got %d", mode, len(m.Completions()))
+			}
+			if ran[0] || !ran[1] {
+				t.Errorf("mode %d: expected processes to run on core 1 only, got %v", mode, ran)
+			}
+			if metrics := m.BalanceMetrics(); metrics.Migrations != 0 {
+				t.Errorf("mode %d: expected no migrations of pinned processes, got %d", mode, metrics.Migrations)
+			}
+		})
+	}
+
+	t.Run("should keep the order of a global run queue when passing over pinned processes", func(t *testing.T) {
+		manager := process.NewManager()
+		m, _ := NewMachine(MachineConfig{Cores: 2, Mode: GlobalRunQueue, NewQueue: fcfsQueues}, manager)
+
+		pinned, _ := manager.CreateProcess(&types.SimpleTask{}, process.WithAffinity(1))
+		m.Submit(pinned)
+		for i := 0; i < 2; i++ {
+			p, _ := manager.CreateProcess(&types.SimpleTask{})
+			m.Submit(p)
+		}
+
+		cores := m.Cores()
+		var order []int
+		for _, c := range []*Core{cores[0], cores[1], cores[0]} {
+			p, err := m.next(c.dispatcher)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			order = append(order, p.GetPID())
+		}
+		if order[0] != 2 || order[1] != pinned.GetPID() || order[2] != 3 {
+			t.Errorf("expected processes 2, 1 and 3, got %v", order)
+		}
+	})
+
+	t.Run("should move a queued process whose core is no longer allowed without dispatching it", func(t *testing.T) {
+		manager := process.NewManager()
+		m, _ := NewMachine(MachineConfig{Cores: 2, Mode: PerCoreRunQueues, NewQueue: fcfsQueues}, manager)
+
+		p, _ := manager.CreateProcess(&types.SimpleTask{}, process.WithAffinity(0))
+		m.Submit(p)
+		m.SetAffinity(p, 1<<1)
+
+		cores := m.Cores()
+		if _, err := m.next(cores[0].dispatcher); err == nil {
+			t.Error("expected no process for core 0")
+		}
+		if cores[1].Queue().Size() != 1 {
+			t.Errorf("expected the process on the queue of core 1, got %d processes", cores[1].Queue().Size())
+		}
+		if metrics := cores[0].Queue().GetMetrics(); metrics != (types.SchedulingMetrics{}) {
+			t.Errorf("expected no dispatches on core 0, got %+v", metrics)
+		}
+		if metrics := m.BalanceMetrics(); metrics.Forced != 1 {
+			t.Errorf("expected one forced migration, got %+v", metrics)
+		}
+	})
+
+	t.Run("should migrate a running process when its core is no longer allowed", func(t *testing.T) {
+		manager := process.NewManager()
+		m, _ := NewMachine(MachineConfig{Cores: 2, Mode: PerCoreRunQueues, NewQueue: fcfsQueues}, manager)
+
+		var cores []int
+		var p types.Process
+		p, _ = manager.CreateProcess(&sliceFuncTask{
+			fn: func(ctx context.Context) (any, error) {
+				cores = append(cores, coreOf(m, p))
+				if len(cores) > 1 {
+					return nil, nil
+				}
+				if err := m.SetAffinity(p, 1<<1); err != nil {
+					return nil, err
+				}
+				<-ctx.Done()
+				return nil, types.ErrPreempted
+			},
+		}, process.WithAffinity(0))
+
+		m.Submit(p)
+		m.Start()
+		m.Wait()
+		m.Stop()
+
+		if len(cores) != 2 || cores[0] != 0 || cores[1] != 1 {
+			t.Errorf("expected the process to move from core 0 to core 1, got %v", cores)
+		}
+		if metrics := m.BalanceMetrics(); metrics.Forced != 1 || metrics.PerProcess[p.GetPID()] != 1 {
+			t.Errorf("expected one forced migration, got %+v", metrics)
+		}
+	})
+}
+
+func TestMachine_Topology(t *testing.T) {
+	t.Run("should take its cores from the topology", func(t *testing.T) {
+		topology, _ := NewTopology(TopologyConfig{Sockets: 2, CoresPerNode: 2})
+
+		m, err := NewMachine(MachineConfig{NewQueue: fcfsQueues, Topology: topology}, process.NewManager())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(m.Cores()) != 4 || m.Topology() != topology {
+			t.Errorf("expected 4 cores from the topology, got %d", len(m.Cores()))
+		}
+
+		if _, err := NewMachine(MachineConfig{Cores: 2, NewQueue: fcfsQueues, Topology: topology}, process.NewManager()); err == nil {
+			t.Error("expected error for a core count that does not match the topology")
+		}
+	})
+
+	t.Run("should slow down processes running off their home node", func(t *testing.T) {
+		topology, _ := NewTopology(TopologyConfig{Sockets: 2, CoresPerNode: 1, RemotePenalty: 1})
+		manager := process.NewManager()
+		m, _ := NewMachine(MachineConfig{Mode: PerCoreRunQueues, NewQueue: fcfsQueues, Topology: topology}, manager)
+
+		sleep := func() (any, error) {
+			time.Sleep(20 * time.Millisecond)
+			return nil, nil
+		}
+		remote, _ := manager.CreateProcess(&types.SimpleTask{ExecuteFn: sleep}, process.WithAffinity(0), process.WithHomeNode(1))
+		local, _ := manager.CreateProcess(&types.SimpleTask{ExecuteFn: sleep}, process.WithAffinity(1))
+		m.Submit(remote)
+		m.Submit(local)
+
+		m.Start()
+		m.Wait()
+		m.Stop()
+
+		if local.GetHomeNode() != 1 {
+			t.Errorf("expected the local process to be homed on node 1 on first touch, got %d", local.GetHomeNode())
+		}
+
+		stats := m.Stats()
+		if stats[0].Busy < 40*time.Millisecond || stats[1].Busy >= 40*time.Millisecond {
+			t.Errorf("expected the remote process to take twice as long, got %v and %v", stats[0].Busy, stats[1].Busy)
+		}
+
+		metrics := m.LocalityMetrics()
+		if metrics.Stall < 20*time.Millisecond || metrics.RemoteTime < 40*time.Millisecond || metrics.LocalTime < 20*time.Millisecond {
+			t.Errorf("expected 20ms local, 40ms remote and 20ms stall, got %+v", metrics)
+		}
+		if metrics.LocalRatio <= 0 || metrics.LocalRatio >= 0.5 {
+			t.Errorf("expected a local ratio of about a third, got %v", metrics.LocalRatio)
+		}
+	})
+
+	t.Run("should stall remote processes within their quantum", func(t *testing.T) {
+		topology, _ := NewTopology(TopologyConfig{Sockets: 2, CoresPerNode: 1, RemotePenalty: 1})
+		manager := process.NewManager()
+		m, _ := NewMachine(MachineConfig{
+			Mode:     PerCoreRunQueues,
+			NewQueue: func() (types.SchedulingQueue, error) { return queue.NewRoundRobinQueue(10 * time.Millisecond), nil },
+			Topology: topology,
+		}, manager)
+
+		task := &sleepTask{units: 20, unit: time.Millisecond}
+		p, _ := manager.CreateProcess(task, process.WithAffinity(0), process.WithHomeNode(1))
+		m.Submit(p)
+
+		m.Start()
+		m.Wait()
+		m.Stop()
+
+		timeline := m.Timeline()
+		if len(timeline) < 4 {
+			t.Fatalf("expected the remote process to need twice the slices, got %d", len(timeline))
+		}
+		var held time.Duration
+		for _, e := range timeline {
+			held += e.End.Sub(e.Start)
+		}
+		if average := held / time.Duration(len(timeline)); average > 14*time.Millisecond {
+			t.Errorf("expected the slices to end with their 10ms quantum, got %v on average", average)
+		}
+		if metrics := m.LocalityMetrics(); metrics.Stall < 10*time.Millisecond || metrics.RemoteTime < 30*time.Millisecond {
+			t.Errorf("expected about 20ms of stall in 40ms of remote time, got %+v", metrics)
+		}
+	})
+
+	t.Run("should steal from the same node first and skip the cache penalty there", func(t *testing.T) {
+		topology, _ := NewTopology(TopologyConfig{Sockets: 2, CoresPerNode: 2})
+		manager := process.NewManager()
+		m, _ := NewMachine(MachineConfig{
+			Mode:     PerCoreRunQueues,
+			NewQueue: fcfsQueues,
+			Topology: topology,
+			Balance: BalanceConfig{
+				Strategy:     PullBalancing,
+				CachePenalty: 4 * time.Millisecond,
+				CacheHotTime: time.Second,
+			},
+		}, manager)
+		cores := m.Cores()
+
+		enqueue := func(c *Core, n int) []types.Process {
+			var queued []types.Process
+			for i := 0; i < n; i++ {
+				p, _ := manager.CreateProcess(&types.SimpleTask{ExecuteFn: func() (any, error) { return nil, nil }})
+				c.dispatcher.lastRun[p.GetPID()] = time.Now()
+				c.Queue().Enqueue(p)
+				queued = append(queued, p)
+			}
+			return queued
+		}
+		near := enqueue(cores[0], 1)
+		enqueue(cores[2], 3)
+
+		if !m.steal(cores[1].dispatcher) {
+			t.Fatal("expected core 1 to steal")
+		}
+		if cores[1].Queue().Size() != 1 || cores[2].Queue().Size() != 3 {
+			t.Errorf("expected core 1 to take the process of core 0 on its node")
+		}
+		if penalty := cores[1].dispatcher.penalties[near[0].GetPID()]; penalty != 0 {
+			t.Errorf("expected no cache penalty within a node, got %v", penalty)
+		}
+
+		cores[1].Queue().Dequeue()
+		if !m.steal(cores[0].dispatcher) {
+			t.Fatal("expected core 0 to steal from the other node")
+		}
+		if cores[0].Queue().Size() != 1 || cores[2].Queue().Size() != 2 {
+			t.Errorf("expected core 0 to take a process of core 2")
+		}
+	})
+
+	t.Run("should keep processes on their home node until it is too busy", func(t *testing.T) {
+		topology, _ := NewTopology(TopologyConfig{Sockets: 2, CoresPerNode: 2})
+		manager := process.NewManager()
+		m, _ := NewMachine(MachineConfig{
+			Mode:      PerCoreRunQueues,
+			NewQueue:  fcfsQueues,
+			Topology:  topology,
+			Placement: DomainPlacement{Slack: 1},
+		}, manager)
+
+		for i := 0; i < 6; i++ {
+			p, _ := manager.CreateProcess(&types.SimpleTask{ExecuteFn: func() (any, error) { return nil, nil }}, process.WithHomeNode(1))
+			if err := m.Submit(p); err != nil {
+				t.Fatalf("unexpected submit error: %v", err)
+			}
+		}
+
+		cores := m.Cores()
+		for i, expected := range []int{1, 1, 2, 2} {
+			if size := cores[i].Queue().Size(); size != expected {
+				t.Errorf("core %d: expected %d processes, got %d", i, expected, size)
+			}
+		}
+	})
+}
+
+func TestMachine_Timeline(t *testing.T) {
+	t.Run("should merge the slices of every core in dispatch order", func(t *testing.T) {
+		manager := process.NewManager()
+		m, _ := NewMachine(MachineConfig{Cores: 2, Mode: PerCoreRunQueues, NewQueue: fcfsQueues}, manager)
+
+		submitSleepers(t, m, manager, 4, 10*time.Millisecond)
+		m.Start()
+		m.Wait()
+		m.Stop()
+
+		timeline := m.Timeline()
+		if len(timeline) != 4 {
+			t.Fatalf("expected 4 slices, got %d", len(timeline))
+		}
+		perCPU := make(map[int]int)
+		for i, e := range timeline {
+			perCPU[e.CPU]++
+			if i > 0 && e.Start.Before(timeline[i-1].Start) {
+				t.Errorf("expected slices in dispatch order, got %+v", timeline)
+			}
+		}
+		if perCPU[0] != 2 || perCPU[1] != 2 {
+			t.Errorf("expected two slices on each core, got %v", perCPU)
+		}
+	})
+}
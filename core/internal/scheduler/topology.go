@@ -0,0 +1,266 @@
+package scheduler
+
+import (
+	"cpu-scheduling/core/internal/types"
+	"fmt"
+)
+
+// TopologyConfig describes a symmetric machine of Sockets sockets, each
+// split into NodesPerSocket NUMA nodes of CoresPerNode physical cores with
+// ThreadsPerCore SMT threads each. SMT siblings share the L1 and L2 caches,
+// and the cores of a node share its last level cache and memory.
+//
+// A process running outside its home node is slowed down by RemotePenalty,
+// e.g. 0.3 makes it take 30% longer, or by CrossSocketPenalty when the node
+// is on another socket. CrossSocketPenalty defaults to RemotePenalty
+type TopologyConfig struct {
+	Sockets            int
+	NodesPerSocket     int
+	CoresPerNode       int
+	ThreadsPerCore     int
+	RemotePenalty      float64
+	CrossSocketPenalty float64
+}
+
+// CPUInfo locates a logical CPU in the topology. Node and Core are numbered
+// across the whole machine
+type CPUInfo struct {
+	ID     int
+	Socket int
+	Node   int
+	Core   int
+	Thread int
+}
+
+// DomainLevel is the level of a scheduling domain, from the CPUs sharing the
+// most resources to the whole machine
+type DomainLevel int
+
+const (
+	// SMTDomain holds the hardware threads of one physical core
+	SMTDomain DomainLevel = iota
+	// CacheDomain holds the cores sharing a last level cache and NUMA node
+	CacheDomain
+	// SocketDomain holds the nodes of one socket
+	SocketDomain
+	// SystemDomain holds every CPU of the machine
+	SystemDomain
+)
+
+// Domain is a scheduling domain, a set of CPUs that share resources at some
+// level of the topology, like the sched domains of Linux
+type Domain struct {
+	Level DomainLevel
+	CPUs  types.CPUMask
+}
+
+// Topology maps logical CPUs to cores, NUMA nodes and sockets
+type Topology struct {
+	config TopologyConfig
+	cpus   []CPUInfo
+}
+
+// NewTopology creates a topology, defaulting to one node per socket and one
+// thread per core
+func NewTopology(config TopologyConfig) (*Topology, error) {
+	if config.NodesPerSocket == 0 {
+		config.NodesPerSocket = 1
+	}
+	if config.ThreadsPerCore == 0 {
+		config.ThreadsPerCore = 1
+	}
+	if config.Sockets <= 0 || config.NodesPerSocket <= 0 || config.CoresPerNode <= 0 || config.ThreadsPerCore <= 0 {
+		return nil, fmt.Errorf("topology needs at least one socket, node, core and thread, got %+v", config)
+	}
+	if config.RemotePenalty < 0 || config.CrossSocketPenalty < 0 {
+		return nil, fmt.Errorf("remote penalties cannot be negative")
+	}
+	if config.CrossSocketPenalty == 0 {
+		config.CrossSocketPenalty = config.RemotePenalty
+	}
+
+	n := config.Sockets * config.NodesPerSocket * config.CoresPerNode * config.ThreadsPerCore
+	if n > types.MaxCPUs {
+		return nil, fmt.Errorf("topology has %d CPUs, at most %d are supported", n, types.MaxCPUs)
+	}
+
+	t := &Topology{config: config, cpus: make([]CPUInfo, 0, n)}
+	for socket := 0; socket < config.Sockets; socket++ {
+		for node := 0; node < config.NodesPerSocket; node++ {
+			for core := 0; core < config.CoresPerNode; core++ {
+				for thread := 0; thread < config.ThreadsPerCore; thread++ {
+					globalNode := socket*config.NodesPerSocket + node
+					t.cpus = append(t.cpus, CPUInfo{
+						ID:     len(t.cpus),
+						Socket: socket,
+						Node:   globalNode,
+						Core:   globalNode*config.CoresPerNode + core,
+						Thread: thread,
+					})
+				}
+			}
+		}
+	}
+	return t, nil
+}
+
+// CPUs returns the number of logical CPUs
+func (t *Topology) CPUs() int {
+	return len(t.cpus)
+}
+
+// Nodes returns the number of NUMA nodes
+func (t *Topology) Nodes() int {
+	return t.config.Sockets * t.config.NodesPerSocket
+}
+
+// CPU returns where the logical CPU sits in the topology
+func (t *Topology) CPU(cpu int) CPUInfo {
+	return t.cpus[cpu]
+}
+
+// NodeCPUs returns the CPUs of the NUMA node
+func (t *Topology) NodeCPUs(node int) types.CPUMask {
+	return t.mask(func(c CPUInfo) bool { return c.Node == node })
+}
+
+// Siblings returns the SMT siblings of the CPU, including the CPU itself
+func (t *Topology) Siblings(cpu int) types.CPUMask {
+	core := t.cpus[cpu].Core
+	return t.mask(func(c CPUInfo) bool { return c.Core == core })
+}
+
+// SharesCache reports whether two CPUs share a last level cache
+func (t *Topology) SharesCache(a, b int) bool {
+	return t.cpus[a].Node == t.cpus[b].Node
+}
+
+// Domains returns the scheduling domains of the CPU from the innermost to the
+// whole machine. Levels that hold only the CPU itself, or the same CPUs as the
+// level below, are left out
+func (t *Topology) Domains(cpu int) []Domain {
+	info := t.cpus[cpu]
+	levels := []Domain{
+		{SMTDomain, t.Siblings(cpu)},
+		{CacheDomain, t.NodeCPUs(info.Node)},
+		{SocketDomain, t.mask(func(c CPUInfo) bool { return c.Socket == info.Socket })},
+		{SystemDomain, types.FirstCPUs(len(t.cpus))},
+	}
+
+	var domains []Domain
+	for _, d := range levels {
+		if d.CPUs.Count() == 1 && d.Level != SystemDomain {
+			continue
+		}
+		if len(domains) > 0 && domains[len(domains)-1].CPUs == d.CPUs {
+			domains[len(domains)-1].Level = d.Level
+			continue
+		}
+		domains = append(domains, d)
+	}
+	return domains
+}
+
+// Slowdown returns the factor the execution of a process with the given home
+// node is stretched by on the CPU. Processes without a home node run at full
+// speed everywhere
+func (t *Topology) Slowdown(cpu, homeNode int) float64 {
+	if homeNode == types.NoHomeNode || homeNode >= t.Nodes() {
+		return 1
+	}
+
+	info := t.cpus[cpu]
+	switch {
+	case info.Node == homeNode:
+		return 1
+	case info.Socket == homeNode/t.config.NodesPerSocket:
+		return 1 + t.config.RemotePenalty
+	default:
+		return 1 + t.config.CrossSocketPenalty
+	}
+}
+
+func (t *Topology) mask(match func(c CPUInfo) bool) types.CPUMask {
+	var mask types.CPUMask
+	for _, c := range t.cpus {
+		if match(c) {
+			mask |= 1 << c.ID
+		}
+	}
+	return mask
+}
+
+// CoreLoad is a core a process can be placed on together with its number of
+// queued and running processes
+type CoreLoad struct {
+	CPU  int
+	Load int
+}
+
+// PlacementPolicy picks the core a process is queued on when it is submitted
+// or has to leave its core. Candidates are the cores its affinity allows, in
+// ID order, and prev is the core it last ran on or -1. Topology is nil on a
+// machine without one
+type PlacementPolicy interface {
+	Place(p types.Process, prev int, candidates []CoreLoad, topology *Topology) int
+}
+
+// LeastLoadedPlacement places processes on the core with the fewest queued and
+// running processes, preferring lower IDs on ties
+type LeastLoadedPlacement struct{}
+
+func (LeastLoadedPlacement) Place(p types.Process, prev int, candidates []CoreLoad, topology *Topology) int {
+	return leastLoaded(candidates, types.AllCPUs)
+}
+
+// DomainPlacement keeps processes close to their data. It starts from the
+// core the process last ran on, or from its home node, and walks the
+// scheduling domains outwards. It picks the least loaded core of the first
+// domain whose least loaded core has at most Slack more processes than the
+// least loaded core overall
+type DomainPlacement struct {
+	Slack int
+}
+
+func (d DomainPlacement) Place(p types.Process, prev int, candidates []CoreLoad, topology *Topology) int {
+	best := leastLoaded(candidates, types.AllCPUs)
+	if topology == nil || best < 0 {
+		return best
+	}
+
+	anchor := prev
+	if home := p.GetHomeNode(); anchor < 0 && home != types.NoHomeNode && home < topology.Nodes() {
+		anchor = topology.NodeCPUs(home).CPUs()[0]
+	}
+	if anchor < 0 || anchor >= topology.CPUs() {
+		return best
+	}
+
+	minLoad := loadOf(candidates, best)
+	for _, domain := range topology.Domains(anchor) {
+		if cpu := leastLoaded(candidates, domain.CPUs); cpu >= 0 && loadOf(candidates, cpu) <= minLoad+d.Slack {
+			return cpu
+		}
+	}
+	return best
+}
+
+// leastLoaded returns the candidate in the mask with the lowest load, or -1
+func leastLoaded(candidates []CoreLoad, mask types.CPUMask) int {
+	best, bestLoad := -1, 0
+	for _, c := range candidates {
+		if mask.Has(c.CPU) && (best < 0 || c.Load < bestLoad) {
+			best, bestLoad = c.CPU, c.Load
+		}
+	}
+	return best
+}
+
+func loadOf(candidates []CoreLoad, cpu int) int {
+	for _, c := range candidates {
+		if c.CPU == cpu {
+			return c.Load
+		}
+	}
+	return 0
+}
@@ -0,0 +1,178 @@
+package scheduler
+
+import (
+	"cpu-scheduling/core/internal/process"
+	"cpu-scheduling/core/internal/types"
+	"testing"
+)
+
+// twoSocketTopology has two sockets of two nodes, each with two cores of two
+// threads, so 16 CPUs and 4 nodes
+func twoSocketTopology(t *testing.T) *Topology {
+	t.Helper()
+
+	topology, err := NewTopology(TopologyConfig{
+		Sockets:            2,
+		NodesPerSocket:     2,
+		CoresPerNode:       2,
+		ThreadsPerCore:     2,
+		RemotePenalty:      0.2,
+		CrossSocketPenalty: 0.5,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return topology
+}
+
+func TestNewTopology(t *testing.T) {
+	t.Run("should number CPUs by socket, node, core and thread", func(t *testing.T) {
+		topology := twoSocketTopology(t)
+
+		if topology.CPUs() != 16 || topology.Nodes() != 4 {
+			t.Fatalf("expected 16 CPUs on 4 nodes, got %d on %d", topology.CPUs(), topology.Nodes())
+		}
+		expected := CPUInfo{ID: 13, Socket: 1, Node: 3, Core: 6, Thread: 1}
+		if info := topology.CPU(13); info != expected {
+			t.Errorf("expected %+v, got %+v", expected, info)
+		}
+	})
+
+	t.Run("should default to one node per socket and one thread per core", func(t *testing.T) {
+		topology, err := NewTopology(TopologyConfig{Sockets: 2, CoresPerNode: 3})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if topology.CPUs() != 6 || topology.Nodes() != 2 {
+			t.Errorf("expected 6 CPUs on 2 nodes, got %d on %d", topology.CPUs(), topology.Nodes())
+		}
+	})
+
+	t.Run("should return error for an invalid config", func(t *testing.T) {
+		configs := map[string]TopologyConfig{
+			"no sockets":       {CoresPerNode: 1},
+			"no cores":         {Sockets: 1},
+			"negative penalty": {Sockets: 1, CoresPerNode: 1, RemotePenalty: -1},
+			"too many CPUs":    {Sockets: 4, CoresPerNode: 16, ThreadsPerCore: 2},
+		}
+		for name, config := range configs {
+			if _, err := NewTopology(config); err == nil {
+				t.Errorf("%s: expected error", name)
+			}
+		}
+	})
+}
+
+func TestTopology_Domains(t *testing.T) {
+	t.Run("should list domains from SMT siblings to the whole machine", func(t *testing.T) {
+		topology := twoSocketTopology(t)
+
+		domains := topology.Domains(5)
+		expected := []Domain{
+			{SMTDomain, 0b110000},
+			{CacheDomain, 0b11110000},
+			{SocketDomain, 0xff},
+			{SystemDomain, 0xffff},
+		}
+		if len(domains) != len(expected) {
+			t.Fatalf("expected %d domains, got %+v", len(expected), domains)
+		}
+		for i := range expected {
+			if domains[i] != expected[i] {
+				t.Errorf("domain %d: expected %+v, got %+v", i, expected[i], domains[i])
+			}
+		}
+	})
+
+	t.Run("should leave out levels that add no CPUs", func(t *testing.T) {
+		topology, _ := NewTopology(TopologyConfig{Sockets: 1, CoresPerNode: 4})
+
+		domains := topology.Domains(0)
+		if len(domains) != 1 || domains[0].Level != SystemDomain || domains[0].CPUs != 0b1111 {
+			t.Errorf("expected a single system domain, got %+v", domains)
+		}
+	})
+
+	t.Run("should share caches within a node only", func(t *testing.T) {
+		topology := twoSocketTopology(t)
+
+		if !topology.SharesCache(4, 7) || topology.SharesCache(3, 4) {
+			t.Error("expected CPUs 4 to 7 to share a cache and CPU 3 not to")
+		}
+		if topology.Siblings(6) != 0b11000000 {
+			t.Errorf("expected CPUs 6 and 7 as siblings, got %v", topology.Siblings(6).CPUs())
+		}
+	})
+}
+
+func TestTopology_Slowdown(t *testing.T) {
+	topology := twoSocketTopology(t)
+
+	cases := []struct {
+		name     string
+		cpu      int
+		home     int
+		expected float64
+	}{
+		{"local node", 0, 0, 1},
+		{"no home node", 12, types.NoHomeNode, 1},
+		{"same socket", 4, 0, 1.2},
+		{"other socket", 8, 0, 1.5},
+	}
+	for _, c := range cases {
+		if slowdown := topology.Slowdown(c.cpu, c.home); slowdown != c.expected {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, slowdown)
+		}
+	}
+}
+
+func TestPlacement(t *testing.T) {
+	topology := twoSocketTopology(t)
+	loads := func(l ...int) []CoreLoad {
+		candidates := make([]CoreLoad, len(l))
+		for i, load := range l {
+			candidates[i] = CoreLoad{CPU: i, Load: load}
+		}
+		return candidates
+	}
+	homed := func(node int) types.Process {
+		pcb := process.NewPCB(1, process.NewTask(func() (any, error) { return nil, nil }))
+		pcb.SetHomeNode(node)
+		return pcb
+	}
+
+	t.Run("should pick the least loaded core", func(t *testing.T) {
+		cpu := LeastLoadedPlacement{}.Place(homed(types.NoHomeNode), 0, loads(2, 1, 0, 0), topology)
+		if cpu != 2 {
+			t.Errorf("expected core 2, got %d", cpu)
+		}
+	})
+
+	t.Run("should stay in the innermost domain within the slack", func(t *testing.T) {
+		candidates := loads(3, 2, 2, 2, 0, 0, 0, 0)
+		cpu := DomainPlacement{Slack: 2}.Place(homed(types.NoHomeNode), 0, candidates, topology)
+		if cpu != 1 {
+			t.Errorf("expected the SMT sibling 1, got %d", cpu)
+		}
+
+		cpu = DomainPlacement{Slack: 1}.Place(homed(types.NoHomeNode), 0, candidates, topology)
+		if cpu != 4 {
+			t.Errorf("expected core 4 once the node is too busy, got %d", cpu)
+		}
+	})
+
+	t.Run("should start from the home node of a new process", func(t *testing.T) {
+		candidates := loads(0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 1)
+		cpu := DomainPlacement{}.Place(homed(2), -1, candidates, topology)
+		if cpu != 10 {
+			t.Errorf("expected core 10 on node 2, got %d", cpu)
+		}
+	})
+
+	t.Run("should fall back to the least loaded core without a topology", func(t *testing.T) {
+		cpu := DomainPlacement{Slack: 5}.Place(homed(1), 0, loads(3, 1), nil)
+		if cpu != 1 {
+			t.Errorf("expected core 1, got %d", cpu)
+		}
+	})
+}
@@ -0,0 +1,160 @@
+package simulation
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// EventKind is what happens at an event of the simulation
+type EventKind int
+
+const (
+	// Arrival is a process entering the system
+	Arrival EventKind = iota
+	// BurstCompletion is a process finishing its current CPU burst
+	BurstCompletion
+	// QuantumExpiry is the time slice of the running process running out
+	QuantumExpiry
+	// IOCompletion is a blocked process finishing its I/O
+	IOCompletion
+	// SwitchCompletion is the context switch to a dispatched process ending,
+	// when the process starts running
+	SwitchCompletion
+	// JobRelease is a periodic process releasing its next job
+	JobRelease
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case Arrival:
+		return "arrival"
+	case BurstCompletion:
+		return "burst completion"
+	case QuantumExpiry:
+		return "quantum expiry"
+	case IOCompletion:
+		return "I/O completion"
+	case SwitchCompletion:
+		return "switch completion"
+	case JobRelease:
+		return "job release"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is something that happens to a job at a point in virtual time. Job
+// is the index of the job in the workload
+type Event struct {
+	At   time.Time
+	Kind EventKind
+	Job  int
+
+	seq       uint64
+	cancelled bool
+}
+
+// Clock is a virtual types.Clock that only moves when the next event is
+// taken off its event queue. Events at the same time are taken in the order
+// they were scheduled, which keeps simulations reproducible
+type Clock struct {
+	mu      sync.Mutex
+	now     time.Time
+	events  eventHeap
+	nextSeq uint64
+}
+
+// NewClock creates a virtual clock that starts at the given time
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *Clock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// Schedule adds an event at the given time, which cannot lie in the past
+func (c *Clock) Schedule(at time.Time, kind EventKind, job int) *Event {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if at.Before(c.now) {
+		at = c.now
+	}
+	e := &Event{At: at, Kind: kind, Job: job, seq: c.nextSeq}
+	c.nextSeq++
+	heap.Push(&c.events, e)
+	return e
+}
+
+// Cancel keeps a scheduled event from happening
+func (c *Clock) Cancel(e *Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e.cancelled = true
+}
+
+// Next takes the earliest event off the queue and advances the clock to it.
+// It returns false once no events are left
+func (c *Clock) Next() (Event, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for c.events.Len() > 0 {
+		e := heap.Pop(&c.events).(*Event)
+		if e.cancelled {
+			continue
+		}
+		c.now = e.At
+		return *e, true
+	}
+	return Event{}, false
+}
+
+// Pending returns the number of events that are still to happen
+func (c *Clock) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pending := 0
+	for _, e := range c.events {
+		if !e.cancelled {
+			pending++
+		}
+	}
+	return pending
+}
+
+// eventHeap orders events by time, then by the order they were scheduled in
+type eventHeap []*Event
+
+func (h eventHeap) Len() int { return len(h) }
+
+func (h eventHeap) Less(i, j int) bool {
+	if !h[i].At.Equal(h[j].At) {
+		return h[i].At.Before(h[j].At)
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h eventHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *eventHeap) Push(x any) { *h = append(*h, x.(*Event)) }
+
+func (h *eventHeap) Pop() any {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return e
+}
@@ -0,0 +1,66 @@
+package simulation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClock(t *testing.T) {
+	t.Run("should advance to events in time order", func(t *testing.T) {
+		clock := NewClock(Epoch)
+		clock.Schedule(Epoch.Add(30*time.Millisecond), IOCompletion, 2)
+		clock.Schedule(Epoch.Add(10*time.Millisecond), Arrival, 0)
+		clock.Schedule(Epoch.Add(20*time.Millisecond), BurstCompletion, 1)
+
+		for i, expected := range []time.Duration{10, 20, 30} {
+			e, ok := clock.Next()
+			if !ok {
+				t.Fatalf("expected event %d", i)
+			}
+			if e.Job != i || clock.Since(Epoch) != expected*time.Millisecond {
+				t.Errorf("expected job %d at %dms, got job %d at %v", i, expected, e.Job, clock.Since(Epoch))
+			}
+		}
+		if _, ok := clock.Next(); ok {
+			t.Error("expected no events left")
+		}
+	})
+
+	t.Run("should keep the scheduling order for events at the same time", func(t *testing.T) {
+		clock := NewClock(Epoch)
+		for job := 0; job < 5; job++ {
+			clock.Schedule(Epoch, Arrival, job)
+		}
+
+		for job := 0; job < 5; job++ {
+			if e, _ := clock.Next(); e.Job != job {
+				t.Errorf("expected job %d, got %d", job, e.Job)
+			}
+		}
+	})
+
+	t.Run("should skip cancelled events", func(t *testing.T) {
+		clock := NewClock(Epoch)
+		expiry := clock.Schedule(Epoch.Add(time.Second), QuantumExpiry, 0)
+		clock.Schedule(Epoch.Add(2*time.Second), BurstCompletion, 1)
+		clock.Cancel(expiry)
+
+		if clock.Pending() != 1 {
+			t.Errorf("expected 1 pending event, got %d", clock.Pending())
+		}
+		if e, _ := clock.Next(); e.Job != 1 {
+			t.Errorf("expected the cancelled event to be skipped, got job %d", e.Job)
+		}
+	})
+
+	t.Run("should not schedule events in the past", func(t *testing.T) {
+		clock := NewClock(Epoch)
+		clock.Schedule(Epoch.Add(time.Second), Arrival, 0)
+		clock.Next()
+
+		e := clock.Schedule(Epoch, Arrival, 1)
+		if !e.At.Equal(Epoch.Add(time.Second)) {
+			t.Errorf("expected the event at the current time, got %v", e.At)
+		}
+	})
+}
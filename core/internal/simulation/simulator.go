@@ -0,0 +1,493 @@
+package simulation
+
+import (
+	"cpu-scheduling/core/internal/process"
+	"cpu-scheduling/core/internal/types"
+	"fmt"
+	"time"
+)
+
+// Epoch is the virtual time simulations start at unless configured otherwise
+var Epoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Burst is a CPU burst of a job followed by the I/O the job then waits for.
+// The I/O of the last burst is ignored, as the job exits instead
+type Burst struct {
+	CPU time.Duration
+	IO  time.Duration
+}
+
+// Job is a process of a simulated workload. It arrives Arrival after the
+// start of the simulation and runs its bursts in order. Options configure its
+// process, which is created when the job arrives. A process made periodic by
+// process.WithPeriod runs the bursts again for every job it releases, and
+// needs process.WithJobs so that the simulation ends
+type Job struct {
+	Name    string
+	Arrival time.Duration
+	Bursts  []Burst
+	Options []process.ProcessOption
+}
+
+// Config configures a simulation of a single CPU scheduled by Queue. The
+// simulation starts at Start, which defaults to Epoch. ContextSwitch is the
+// time it takes to switch the CPU to a different process, which the incoming
+// process is charged for before it runs
+type Config struct {
+	Queue         types.SchedulingQueue
+	Jobs          []Job
+	Start         time.Time
+	ContextSwitch time.Duration
+}
+
+// JobResult reports how a job fared. Times are relative to the start of the
+// simulation, and Err is set for a job the queue did not accept
+type JobResult struct {
+	Job        int
+	Name       string
+	PID        int
+	Arrival    time.Duration
+	FirstRun   time.Duration
+	Finish     time.Duration
+	CPUTime    time.Duration
+	WaitTime   time.Duration
+	Response   time.Duration
+	Turnaround time.Duration
+	Err        error
+}
+
+// Slice is a stretch of time a job held the CPU and how it ended. The first
+// Switch of it went to the context switch to the job
+type Slice struct {
+	Job     int
+	PID     int
+	Start   time.Duration
+	End     time.Duration
+	Switch  time.Duration
+	Outcome types.SliceOutcome
+}
+
+// Result is the outcome of a simulation. Jobs are in workload order and the
+// averages only count jobs without an error. BusyTime only counts the time
+// jobs ran, not SwitchTime, and Throughput is in jobs per second of virtual
+// time
+type Result struct {
+	Jobs              []JobResult
+	Timeline          []Slice
+	Makespan          time.Duration
+	BusyTime          time.Duration
+	SwitchTime        time.Duration
+	Utilization       float64
+	ContextSwitches   int
+	AverageWait       time.Duration
+	AverageTurnaround time.Duration
+	AverageResponse   time.Duration
+	Throughput        float64
+	Queue             types.SchedulingMetrics
+}
+
+// jobState tracks a job while the simulation runs
+type jobState struct {
+	index     int
+	job       Job
+	process   types.Process
+	burst     int
+	remaining time.Duration
+	started   bool
+	done      bool
+	result    JobResult
+}
+
+// Simulator runs a workload on a single CPU in virtual time. Processes and the
+// queue tell time by the virtual clock, which jumps from one event to the
+// next, so a simulation takes no wall clock time and always has the same
+// outcome for the same workload and queue
+type Simulator struct {
+	queue      types.SchedulingQueue
+	clock      *Clock
+	manager    *process.Manager
+	start      time.Time
+	switchCost time.Duration
+
+	jobs  []*jobState
+	byPID map[int]*jobState
+
+	running    *jobState
+	sliceStart time.Time
+	overhead   time.Duration
+	run        time.Duration
+	end        EventKind
+	pending    *Event
+	lastPID    int
+	ran        bool
+
+	timeline   []Slice
+	busy       time.Duration
+	switchTime time.Duration
+	switches   int
+}
+
+// New creates a simulator for the workload. The queue is moved to the virtual
+// clock if it keeps timestamps
+func New(config Config) (*Simulator, error) {
+	if config.Queue == nil {
+		return nil, fmt.Errorf("cannot simulate without a queue")
+	}
+	if config.Start.IsZero() {
+		config.Start = Epoch
+	}
+	if config.ContextSwitch < 0 {
+		return nil, fmt.Errorf("context switch time cannot be negative")
+	}
+
+	for i, job := range config.Jobs {
+		if job.Arrival < 0 {
+			return nil, fmt.Errorf("job %d: arrival cannot be negative", i)
+		}
+		if len(job.Bursts) == 0 {
+			return nil, fmt.Errorf("job %d: needs at least one burst", i)
+		}
+		for j, burst := range job.Bursts {
+			if burst.CPU <= 0 || burst.IO < 0 {
+				return nil, fmt.Errorf("job %d: burst %d needs positive CPU time and non-negative I/O, got %+v", i, j, burst)
+			}
+		}
+
+		pcb := process.NewPCB(0, nil)
+		for _, opt := range job.Options {
+			if err := opt(pcb); err != nil {
+				return nil, fmt.Errorf("job %d: %v", i, err)
+			}
+		}
+		if pcb.GetPeriod() > 0 && pcb.GetJobLimit() == 0 {
+			return nil, fmt.Errorf("job %d: a periodic job needs a job limit to be simulated", i)
+		}
+	}
+
+	clock := NewClock(config.Start)
+	if q, ok := config.Queue.(types.ClockedQueue); ok {
+		q.SetClock(clock)
+	}
+
+	s := &Simulator{
+		queue:      config.Queue,
+		clock:      clock,
+		manager:    process.NewManagerWithClock(clock),
+		start:      config.Start,
+		switchCost: config.ContextSwitch,
+		byPID:      make(map[int]*jobState),
+	}
+	for i, job := range config.Jobs {
+		s.jobs = append(s.jobs, &jobState{
+			index:     i,
+			job:       job,
+			remaining: job.Bursts[0].CPU,
+			result:    JobResult{Job: i, Name: job.Name, Arrival: job.Arrival},
+		})
+	}
+	return s, nil
+}
+
+// Clock returns the virtual clock of the simulation
+func (s *Simulator) Clock() *Clock {
+	return s.clock
+}
+
+// Run simulates the workload until every job has finished. A simulator can
+// only run once
+func (s *Simulator) Run() (*Result, error) {
+	if s.ran {
+		return nil, fmt.Errorf("simulation has already run")
+	}
+	s.ran = true
+
+	for _, state := range s.jobs {
+		s.clock.Schedule(s.start.Add(state.job.Arrival), Arrival, state.index)
+	}
+
+	for {
+		if s.running == nil {
+			if err := s.dispatch(); err != nil {
+				return nil, err
+			}
+		}
+
+		e, ok := s.clock.Next()
+		if !ok {
+			break
+		}
+		if err := s.handle(e); err != nil {
+			return nil, fmt.Errorf("%s of job %d at %v: %v", e.Kind, e.Job, e.At.Sub(s.start), err)
+		}
+	}
+
+	for _, state := range s.jobs {
+		if !state.done {
+			return nil, fmt.Errorf("job %d never finished, the queue lost its process", state.index)
+		}
+	}
+	return s.result(), nil
+}
+
+func (s *Simulator) handle(e Event) error {
+	state := s.jobs[e.Job]
+
+	switch e.Kind {
+	case Arrival:
+		p, err := s.manager.CreateProcess(&types.SimpleTask{ExecuteFn: func() (any, error) { return nil, nil }}, state.job.Options...)
+		if err != nil {
+			return err
+		}
+		state.process = p
+		state.result.PID = p.GetPID()
+		s.byPID[p.GetPID()] = state
+
+		if err := s.manager.SetProcessState(p.GetPID(), types.READY); err != nil {
+			return err
+		}
+		return s.arrive(state)
+
+	case IOCompletion:
+		if err := s.manager.SetProcessState(state.process.GetPID(), types.READY); err != nil {
+			return err
+		}
+		return s.arrive(state)
+
+	case SwitchCompletion:
+		s.pending = nil
+		return s.begin(state)
+
+	case JobRelease:
+		p := state.process
+		if err := p.ReleaseJob(p.GetReleaseTime().Add(p.GetPeriod())); err != nil {
+			return err
+		}
+		state.burst = 0
+		state.remaining = state.job.Bursts[0].CPU
+		if err := s.manager.SetProcessState(p.GetPID(), types.READY); err != nil {
+			return err
+		}
+		return s.arrive(state)
+
+	case QuantumExpiry:
+		s.pending = nil
+		s.stop(types.SliceExpired)
+		return s.requeue(state)
+
+	case BurstCompletion:
+		s.pending = nil
+		pid := state.process.GetPID()
+
+		if state.burst < len(state.job.Bursts)-1 {
+			s.stop(types.SliceBlocked)
+			if err := s.manager.SetProcessState(pid, types.WAITING); err != nil {
+				return err
+			}
+
+			io := state.job.Bursts[state.burst].IO
+			state.burst++
+			state.remaining = state.job.Bursts[state.burst].CPU
+			s.clock.Schedule(e.At.Add(io), IOCompletion, state.index)
+			return nil
+		}
+
+		// A periodic process sleeps until its next job is released, which is
+		// right away if the job overran its period
+		p := state.process
+		if p.GetPeriod() > 0 && p.GetJob() < p.GetJobLimit() {
+			s.stop(types.SliceJobCompleted)
+			if err := s.manager.SetProcessState(pid, types.WAITING); err != nil {
+				return err
+			}
+			s.clock.Schedule(p.GetReleaseTime().Add(p.GetPeriod()), JobRelease, state.index)
+			return nil
+		}
+
+		s.stop(types.SliceCompleted)
+		if err := s.manager.SetProcessState(pid, types.TERMINATED); err != nil {
+			return err
+		}
+		s.finish(state, nil)
+	}
+	return nil
+}
+
+// dispatch puts the next queued process on the idle CPU. The slice starts
+// with a context switch if another process ran last, during which the process
+// is still READY, so the switch counts as waiting rather than CPU time
+func (s *Simulator) dispatch() error {
+	if s.queue.IsEmpty() {
+		return nil
+	}
+
+	p, err := s.queue.Dequeue()
+	if err != nil {
+		return fmt.Errorf("failed to dequeue at %v: %v", s.clock.Since(s.start), err)
+	}
+	state, ok := s.byPID[p.GetPID()]
+	if !ok {
+		return fmt.Errorf("queue returned unknown process %d", p.GetPID())
+	}
+
+	now := s.clock.Now()
+	run, kind := state.remaining, BurstCompletion
+	if q, ok := s.queue.(types.TimeSlicedQueue); ok {
+		if slice := q.GetTimeSlice(p); slice > 0 && slice < run {
+			run, kind = slice, QuantumExpiry
+		}
+	}
+
+	var overhead time.Duration
+	if s.lastPID != 0 && s.lastPID != p.GetPID() {
+		s.switches++
+		overhead = s.switchCost
+	}
+	s.lastPID = p.GetPID()
+
+	if !state.started {
+		state.started = true
+		state.result.FirstRun = now.Add(overhead).Sub(s.start)
+	}
+
+	s.running = state
+	s.sliceStart = now
+	s.overhead = overhead
+	s.run, s.end = run, kind
+	if overhead > 0 {
+		s.pending = s.clock.Schedule(now.Add(overhead), SwitchCompletion, state.index)
+		return nil
+	}
+	return s.begin(state)
+}
+
+// begin marks the dispatched job RUNNING once its context switch is over and
+// schedules the end of its slice
+func (s *Simulator) begin(state *jobState) error {
+	if err := s.manager.SetProcessState(state.process.GetPID(), types.RUNNING); err != nil {
+		return fmt.Errorf("failed to dispatch job %d: %v", state.index, err)
+	}
+	s.pending = s.clock.Schedule(s.clock.Now().Add(s.run), s.end, state.index)
+	return nil
+}
+
+// arrive puts a ready job on the queue, preempting the running job if the
+// queue asks for it
+func (s *Simulator) arrive(state *jobState) error {
+	if err := s.queue.Enqueue(state.process); err != nil {
+		s.finish(state, err)
+		return nil
+	}
+
+	q, ok := s.queue.(types.PreemptiveQueue)
+	if !ok || s.running == nil || !q.ShouldPreempt(s.running.process, state.process) {
+		return nil
+	}
+
+	preempted := s.running
+	s.clock.Cancel(s.pending)
+	s.pending = nil
+	s.stop(types.SlicePreempted)
+	return s.requeue(preempted)
+}
+
+// stop takes the running job off the CPU and reports the slice to the queue.
+// A job preempted during its context switch has not run at all
+func (s *Simulator) stop(outcome types.SliceOutcome) {
+	state := s.running
+	now := s.clock.Now()
+	held := now.Sub(s.sliceStart)
+	overhead := min(held, s.overhead)
+	ran := held - overhead
+
+	state.remaining -= ran
+	s.busy += ran
+	s.switchTime += overhead
+	s.running = nil
+	if held > 0 {
+		s.timeline = append(s.timeline, Slice{
+			Job:     state.index,
+			PID:     state.process.GetPID(),
+			Start:   s.sliceStart.Sub(s.start),
+			End:     now.Sub(s.start),
+			Switch:  overhead,
+			Outcome: outcome,
+		})
+	}
+
+	if q, ok := s.queue.(types.FeedbackQueue); ok {
+		q.RecordSlice(state.process, ran, outcome)
+	}
+}
+
+// requeue returns a job that was taken off the CPU to READY and the queue. A
+// job preempted during its context switch never left READY
+func (s *Simulator) requeue(state *jobState) error {
+	if state.process.GetState() != types.READY {
+		if err := s.manager.SetProcessState(state.process.GetPID(), types.READY); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if q, ok := s.queue.(types.TimeSlicedQueue); ok {
+		err = q.RequeueProcess(state.process)
+	} else {
+		err = s.queue.Enqueue(state.process)
+	}
+	if err != nil {
+		s.finish(state, err)
+	}
+	return nil
+}
+
+func (s *Simulator) finish(state *jobState, err error) {
+	state.done = true
+	r := &state.result
+	r.Err = err
+	r.Finish = s.clock.Since(s.start)
+	r.CPUTime = state.process.GetCPUTime()
+	r.WaitTime = state.process.GetWaitTime()
+	r.Turnaround = r.Finish - r.Arrival
+	if state.started {
+		r.Response = r.FirstRun - r.Arrival
+	}
+}
+
+func (s *Simulator) result() *Result {
+	result := &Result{
+		Jobs:            make([]JobResult, len(s.jobs)),
+		Timeline:        s.timeline,
+		BusyTime:        s.busy,
+		SwitchTime:      s.switchTime,
+		ContextSwitches: s.switches,
+		Queue:           s.queue.GetMetrics(),
+	}
+
+	var wait, turnaround, response time.Duration
+	completed := 0
+	for i, state := range s.jobs {
+		r := state.result
+		result.Jobs[i] = r
+		if r.Finish > result.Makespan {
+			result.Makespan = r.Finish
+		}
+		if r.Err != nil {
+			continue
+		}
+		completed++
+		wait += r.WaitTime
+		turnaround += r.Turnaround
+		response += r.Response
+	}
+
+	if completed > 0 {
+		result.AverageWait = wait / time.Duration(completed)
+		result.AverageTurnaround = turnaround / time.Duration(completed)
+		result.AverageResponse = response / time.Duration(completed)
+	}
+	if result.Makespan > 0 {
+		result.Utilization = float64(result.BusyTime) / float64(result.Makespan)
+		result.Throughput = float64(completed) / result.Makespan.Seconds()
+	}
+	return result
+}
@@ -0,0 +1,274 @@
+package simulation
+
+import (
+	"cpu-scheduling/core/internal/process"
+	"cpu-scheduling/core/internal/queue"
+	"cpu-scheduling/core/internal/types"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func ms(n int) time.Duration {
+	return time.Duration(n) * time.Millisecond
+}
+
+// cpuJob is a job with a single CPU burst
+func cpuJob(arrival, burst int, opts ...process.ProcessOption) Job {
+	return Job{Arrival: ms(arrival), Bursts: []Burst{{CPU: ms(burst)}}, Options: opts}
+}
+
+func run(t *testing.T, q types.SchedulingQueue, jobs ...Job) *Result {
+	t.Helper()
+
+	s, err := New(Config{Queue: q, Jobs: jobs})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := s.Run()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func waits(result *Result) []time.Duration {
+	w := make([]time.Duration, len(result.Jobs))
+	for i, r := range result.Jobs {
+		w[i] = r.WaitTime
+	}
+	return w
+}
+
+func TestNew(t *testing.T) {
+	t.Run("should return error for an invalid config", func(t *testing.T) {
+		configs := map[string]Config{
+			"no queue":         {Jobs: []Job{cpuJob(0, 1)}},
+			"negative arrival": {Queue: queue.NewFCFSQueue(), Jobs: []Job{cpuJob(-1, 1)}},
+			"no bursts":        {Queue: queue.NewFCFSQueue(), Jobs: []Job{{}}},
+			"empty burst":      {Queue: queue.NewFCFSQueue(), Jobs: []Job{cpuJob(0, 0)}},
+			"negative I/O": {Queue: queue.NewFCFSQueue(), Jobs: []Job{
+				{Bursts: []Burst{{CPU: ms(1), IO: -ms(1)}, {CPU: ms(1)}}},
+			}},
+			"negative switch":  {Queue: queue.NewFCFSQueue(), Jobs: []Job{cpuJob(0, 1)}, ContextSwitch: -ms(1)},
+			"unbounded period": {Queue: queue.NewEDFQueue(), Jobs: []Job{cpuJob(0, 1, process.WithPeriod(ms(10)))}},
+			"invalid option":   {Queue: queue.NewFCFSQueue(), Jobs: []Job{cpuJob(0, 1, process.WithPeriod(-1))}},
+		}
+		for name, config := range configs {
+			if _, err := New(config); err == nil {
+				t.Errorf("%s: expected error", name)
+			}
+		}
+	})
+}
+
+func TestSimulator_Run(t *testing.T) {
+	t.Run("should run FCFS in arrival order", func(t *testing.T) {
+		result := run(t, queue.NewFCFSQueue(), cpuJob(0, 24), cpuJob(0, 3), cpuJob(0, 3))
+
+		if expected := []time.Duration{0, ms(24), ms(27)}; !reflect.DeepEqual(waits(result), expected) {
+			t.Errorf("expected waits %v, got %v", expected, waits(result))
+		}
+		if result.AverageWait != ms(17) || result.Makespan != ms(30) || result.Utilization != 1 {
+			t.Errorf("expected 17ms average wait over 30ms fully used, got %+v", result)
+		}
+		if result.ContextSwitches != 2 {
+			t.Errorf("expected 2 context switches, got %d", result.ContextSwitches)
+		}
+	})
+
+	t.Run("should slice Round Robin by the quantum", func(t *testing.T) {
+		result := run(t, queue.NewRoundRobinQueue(ms(4)), cpuJob(0, 24), cpuJob(0, 3), cpuJob(0, 3))
+
+		if expected := []time.Duration{ms(6), ms(4), ms(7)}; !reflect.DeepEqual(waits(result), expected) {
+			t.Errorf("expected waits %v, got %v", expected, waits(result))
+		}
+
+		var starts []time.Duration
+		for _, s := range result.Timeline {
+			starts = append(starts, s.Start)
+		}
+		if expected := []time.Duration{0, ms(4), ms(7), ms(10), ms(14), ms(18), ms(22), ms(26)}; !reflect.DeepEqual(starts, expected) {
+			t.Errorf("expected slices starting at %v, got %v", expected, starts)
+		}
+		if result.Timeline[0].Outcome != types.SliceExpired || result.Timeline[1].Outcome != types.SliceCompleted {
+			t.Errorf("expected an expired then a completed slice, got %+v", result.Timeline[:2])
+		}
+	})
+
+	t.Run("should preempt on arrival with SRTF", func(t *testing.T) {
+		known := func(burst int) process.ProcessOption { return process.WithExpectedBurst(ms(burst)) }
+		result := run(t, queue.NewSRTFQueue(queue.NewBurstPredictor(0.5, ms(10))),
+			cpuJob(0, 8, known(8)), cpuJob(1, 4, known(4)), cpuJob(2, 9, known(9)), cpuJob(3, 5, known(5)))
+
+		if expected := []time.Duration{ms(9), 0, ms(15), ms(2)}; !reflect.DeepEqual(waits(result), expected) {
+			t.Errorf("expected waits %v, got %v", expected, waits(result))
+		}
+		if result.AverageWait != 6500*time.Microsecond {
+			t.Errorf("expected 6.5ms average wait, got %v", result.AverageWait)
+		}
+		if result.Timeline[0].Outcome != types.SlicePreempted || result.Timeline[0].End != ms(1) {
+			t.Errorf("expected the first job to be preempted at 1ms, got %+v", result.Timeline[0])
+		}
+	})
+
+	t.Run("should overlap I/O with other jobs", func(t *testing.T) {
+		io := Job{Bursts: []Burst{{CPU: ms(2), IO: ms(10)}, {CPU: ms(2)}}}
+		result := run(t, queue.NewFCFSQueue(), io, cpuJob(1, 5))
+
+		first := result.Jobs[0]
+		if first.Finish != ms(14) || first.CPUTime != ms(4) || first.WaitTime != 0 {
+			t.Errorf("expected the I/O job to finish at 14ms after 4ms CPU, got %+v", first)
+		}
+		if result.Jobs[1].Finish != ms(7) {
+			t.Errorf("expected the CPU job to run during the I/O, got %+v", result.Jobs[1])
+		}
+		if result.BusyTime != ms(9) || result.Makespan != ms(14) {
+			t.Errorf("expected 9ms busy in 14ms, got %v in %v", result.BusyTime, result.Makespan)
+		}
+		if result.Timeline[0].Outcome != types.SliceBlocked {
+			t.Errorf("expected the first slice to block, got %v", result.Timeline[0].Outcome)
+		}
+	})
+
+	t.Run("should idle until the next arrival", func(t *testing.T) {
+		result := run(t, queue.NewFCFSQueue(), cpuJob(0, 1), cpuJob(100, 1))
+
+		if r := result.Jobs[1]; r.FirstRun != ms(100) || r.Response != 0 || r.Turnaround != ms(1) {
+			t.Errorf("expected the late job to run on arrival, got %+v", r)
+		}
+		if result.Makespan != ms(101) || result.BusyTime != ms(2) {
+			t.Errorf("expected 2ms busy over 101ms, got %v over %v", result.BusyTime, result.Makespan)
+		}
+	})
+
+	t.Run("should charge context switches to the incoming job", func(t *testing.T) {
+		s, _ := New(Config{
+			Queue:         queue.NewFCFSQueue(),
+			Jobs:          []Job{cpuJob(0, 24), cpuJob(0, 3), cpuJob(0, 3)},
+			ContextSwitch: ms(1),
+		})
+		result, err := s.Run()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := []Slice{
+			{Job: 0, PID: 1, Start: 0, End: ms(24), Outcome: types.SliceCompleted},
+			{Job: 1, PID: 2, Start: ms(24), End: ms(28), Switch: ms(1), Outcome: types.SliceCompleted},
+			{Job: 2, PID: 3, Start: ms(28), End: ms(32), Switch: ms(1), Outcome: types.SliceCompleted},
+		}
+		if !reflect.DeepEqual(result.Timeline, expected) {
+			t.Errorf("expected timeline %+v, got %+v", expected, result.Timeline)
+		}
+		if result.Makespan != ms(32) || result.BusyTime != ms(30) || result.SwitchTime != ms(2) {
+			t.Errorf("expected 30ms busy and 2ms switching over 32ms, got %+v", result)
+		}
+		if r := result.Jobs[1]; r.FirstRun != ms(25) || r.Response != ms(25) {
+			t.Errorf("expected the second job to first run after its switch, got %+v", r)
+		}
+		if r := result.Jobs[1]; r.CPUTime != ms(3) || r.WaitTime != ms(25) {
+			t.Errorf("expected the switch to count as waiting rather than CPU time, got %+v", r)
+		}
+	})
+
+	t.Run("should leave a job preempted during its switch READY", func(t *testing.T) {
+		known := func(burst int) process.ProcessOption { return process.WithExpectedBurst(ms(burst)) }
+		s, _ := New(Config{
+			Queue:         queue.NewSRTFQueue(queue.NewBurstPredictor(0.5, ms(10))),
+			Jobs:          []Job{cpuJob(0, 5, known(5)), cpuJob(0, 20, known(20)), cpuJob(6, 1, known(1))},
+			ContextSwitch: ms(2),
+		})
+		result, err := s.Run()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		preempted := Slice{Job: 1, PID: 2, Start: ms(5), End: ms(6), Switch: ms(1), Outcome: types.SlicePreempted}
+		if result.Timeline[1] != preempted {
+			t.Errorf("expected the second job to be preempted during its switch, got %+v", result.Timeline[1])
+		}
+		if r := result.Jobs[1]; r.CPUTime != ms(20) || r.Finish != ms(31) {
+			t.Errorf("expected the second job to run its 20ms after both switches, got %+v", r)
+		}
+		if result.BusyTime != ms(26) || result.SwitchTime != ms(5) {
+			t.Errorf("expected 26ms busy and 5ms switching, got %+v", result)
+		}
+	})
+
+	t.Run("should release the jobs of periodic processes", func(t *testing.T) {
+		edf := queue.NewEDFQueue()
+		result := run(t, edf, cpuJob(0, 4, process.WithPeriod(ms(10)), process.WithJobs(3)))
+
+		var outcomes []types.SliceOutcome
+		for _, slice := range result.Timeline {
+			outcomes = append(outcomes, slice.Outcome)
+		}
+		expected := []types.SliceOutcome{types.SliceJobCompleted, types.SliceJobCompleted, types.SliceCompleted}
+		if !reflect.DeepEqual(outcomes, expected) || result.Timeline[2].Start != ms(20) {
+			t.Errorf("expected a job released every 10ms, got %+v", result.Timeline)
+		}
+		if r := result.Jobs[0]; r.Finish != ms(24) || r.CPUTime != ms(12) {
+			t.Errorf("expected three 4ms jobs ending at 24ms, got %+v", r)
+		}
+		if metrics := edf.GetEDFMetrics(); metrics.Jobs != 3 || metrics.Misses != 0 {
+			t.Errorf("expected 3 jobs meeting their deadlines, got %+v", metrics.DeadlineMetrics)
+		}
+	})
+
+	t.Run("should release the next job right away after an overrun", func(t *testing.T) {
+		edf := queue.NewEDFQueue()
+		result := run(t, edf, cpuJob(0, 6, process.WithPeriod(ms(5)), process.WithJobs(2)))
+
+		if r := result.Jobs[0]; r.Finish != ms(12) {
+			t.Errorf("expected the second job to run right after the first, got %+v", r)
+		}
+		if metrics := edf.GetEDFMetrics(); metrics.Jobs != 2 || metrics.Misses != 2 {
+			t.Errorf("expected both jobs to miss their deadlines, got %+v", metrics.DeadlineMetrics)
+		}
+	})
+
+	t.Run("should reproduce the same result on every run", func(t *testing.T) {
+		jobs := []Job{
+			{Arrival: 0, Bursts: []Burst{{CPU: ms(7), IO: ms(3)}, {CPU: ms(4)}}},
+			cpuJob(2, 9),
+			{Arrival: ms(5), Bursts: []Burst{{CPU: ms(1), IO: ms(1)}, {CPU: ms(1), IO: ms(1)}, {CPU: ms(1)}}},
+		}
+		first := run(t, queue.NewCFSQueue(queue.DefaultCFSConfig()), jobs...)
+		second := run(t, queue.NewCFSQueue(queue.DefaultCFSConfig()), jobs...)
+
+		if !reflect.DeepEqual(first.Timeline, second.Timeline) || !reflect.DeepEqual(first.Jobs, second.Jobs) {
+			t.Error("expected identical runs")
+		}
+	})
+
+	t.Run("should report jobs the queue rejects", func(t *testing.T) {
+		q := queue.NewEDFQueueWithAdmission()
+		periodic := func(period int) []process.ProcessOption {
+			return []process.ProcessOption{process.WithPeriod(ms(period)), process.WithWCET(ms(period * 3 / 4)), process.WithJobs(1)}
+		}
+		result := run(t, q,
+			Job{Bursts: []Burst{{CPU: ms(3)}}, Options: periodic(4)},
+			Job{Bursts: []Burst{{CPU: ms(3)}}, Options: periodic(4)})
+
+		if result.Jobs[0].Err != nil || result.Jobs[1].Err == nil {
+			t.Errorf("expected the second job to be rejected, got %+v", result.Jobs)
+		}
+	})
+
+	t.Run("should only run once", func(t *testing.T) {
+		s, _ := New(Config{Queue: queue.NewFCFSQueue(), Jobs: []Job{cpuJob(0, 1)}})
+		s.Run()
+		if _, err := s.Run(); err == nil {
+			t.Error("expected error for a second run")
+		}
+	})
+
+	t.Run("should run the queue on the virtual clock", func(t *testing.T) {
+		result := run(t, queue.NewFCFSQueue(), cpuJob(0, 60000), cpuJob(0, 60000))
+
+		if result.Queue.AverageWaitTime != 30*time.Second || result.Queue.ThroughputPerMin != 1 {
+			t.Errorf("expected queue metrics in virtual time, got %+v", result.Queue)
+		}
+	})
+}
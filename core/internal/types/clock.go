@@ -0,0 +1,22 @@
+package types
+
+import "time"
+
+// Clock tells processes and queues the time. RealClock follows the wall
+// clock, while a simulation advances a virtual clock from event to event so
+// that runs are instant and reproducible
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+}
+
+// RealClock is the wall clock
+type RealClock struct{}
+
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+func (RealClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
@@ -0,0 +1,64 @@
+package types
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// CPUMask is the set of CPUs a process may run on, one bit per CPU like the
+// mask of sched_setaffinity
+type CPUMask uint64
+
+// MaxCPUs is the number of CPUs a CPUMask can address
+const MaxCPUs = 64
+
+// AllCPUs lets a process run on any CPU
+const AllCPUs = ^CPUMask(0)
+
+// NoHomeNode marks a process whose memory has not been placed on a NUMA node
+// yet. It gets the node of the first CPU it runs on
+const NoHomeNode = -1
+
+// NewCPUMask returns the mask of the given CPUs
+func NewCPUMask(cpus ...int) (CPUMask, error) {
+	var mask CPUMask
+	for _, cpu := range cpus {
+		if cpu < 0 || cpu >= MaxCPUs {
+			return 0, fmt.Errorf("CPU must be between 0 and %d, got %d", MaxCPUs-1, cpu)
+		}
+		mask |= 1 << cpu
+	}
+	return mask, nil
+}
+
+// FirstCPUs returns the mask of CPUs 0 to n-1
+func FirstCPUs(n int) CPUMask {
+	if n >= MaxCPUs {
+		return AllCPUs
+	}
+	if n <= 0 {
+		return 0
+	}
+	return CPUMask(1)<<n - 1
+}
+
+// Has reports whether the CPU is in the mask
+func (m CPUMask) Has(cpu int) bool {
+	return cpu >= 0 && cpu < MaxCPUs && m&(1<<cpu) != 0
+}
+
+// Count returns the number of CPUs in the mask
+func (m CPUMask) Count() int {
+	return bits.OnesCount64(uint64(m))
+}
+
+// CPUs returns the CPUs in the mask in ascending order
+func (m CPUMask) CPUs() []int {
+	cpus := make([]int, 0, m.Count())
+	for cpu := 0; cpu < MaxCPUs; cpu++ {
+		if m.Has(cpu) {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus
+}
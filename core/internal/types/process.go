@@ -1,6 +1,9 @@
 package types
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -14,6 +17,75 @@ const (
 	TERMINATED
 )
 
+// ProcessClass is the fixed scheduling class of a process, from the most to
+// the least important
+type ProcessClass int
+
+const (
+	SYSTEM ProcessClass = iota
+	INTERACTIVE
+	BATCH
+	BACKGROUND
+)
+
+var processClassNames = map[string]ProcessClass{
+	"system":      SYSTEM,
+	"interactive": INTERACTIVE,
+	"batch":       BATCH,
+	"background":  BACKGROUND,
+}
+
+// UnmarshalText parses a class by its lower case name, e.g. "batch"
+func (c *ProcessClass) UnmarshalText(text []byte) error {
+	class, ok := processClassNames[strings.ToLower(string(text))]
+	if !ok {
+		return fmt.Errorf("unknown process class %q", text)
+	}
+	*c = class
+	return nil
+}
+
+// MarshalText returns the lower case name of the class
+func (c ProcessClass) MarshalText() ([]byte, error) {
+	for name, class := range processClassNames {
+		if class == c {
+			return []byte(name), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown process class %d", c)
+}
+
+// Priorities follow the Unix convention: lower values are more urgent
+const (
+	HighestPriority = 0
+	LowestPriority  = 39
+	DefaultPriority = 20
+)
+
+// Owner identifies the user a process runs as and the group it belongs to.
+// Groups form a hierarchy addressed by slash separated paths, with "/" as the
+// root
+type Owner struct {
+	User  string
+	Group string
+}
+
+// Processes are owned by DefaultUser in RootGroup unless given an owner
+const (
+	DefaultUser = "root"
+	RootGroup   = "/"
+)
+
+// DefaultTickets is the number of lottery tickets a process holds unless it is
+// given a different amount
+const DefaultTickets = 100
+
+// Nice values weight a process's share of the CPU, lower values get more
+const (
+	MinNice = -20
+	MaxNice = 19
+)
+
 type Process interface {
 	GetPID() int
 	GetState() ProcessState
@@ -21,7 +93,43 @@ type Process interface {
 	GetCreationTime() time.Time
 	GetContext() ProcessContext
 	ExecuteTask() (any, error)
+	ExecuteSlice(ctx context.Context) (any, error)
+	GetProgress() float64
 	// Time tracking
 	GetTimeInState() time.Duration
 	GetTotalTime() time.Duration
+	GetWaitTime() time.Duration
+	// CPU burst tracking
+	GetExpectedBurst() time.Duration
+	GetCurrentBurst() time.Duration
+	GetRemainingBurst() time.Duration
+	GetCPUTime() time.Duration
+	GetBurstHistory() []time.Duration
+	// Priority
+	GetPriority() int
+	GetEffectivePriority() int
+	SetEffectivePriority(priority int) error
+	// Scheduling class
+	GetClass() ProcessClass
+	GetOwner() Owner
+	GetNice() int
+	GetRequestedSlice() time.Duration
+	// CPU affinity and NUMA placement
+	GetAffinity() CPUMask
+	SetAffinity(mask CPUMask) error
+	GetHomeNode() int
+	SetHomeNode(node int) error
+	// Proportional share
+	GetTickets() int
+	GetCurrency() string
+	// Real-time parameters
+	GetPeriod() time.Duration
+	GetRelativeDeadline() time.Duration
+	GetAbsoluteDeadline() time.Time
+	GetReleaseTime() time.Time
+	GetWCET() time.Duration
+	// Periodic jobs
+	GetJob() int
+	GetJobLimit() int
+	ReleaseJob(release time.Time) error
 }
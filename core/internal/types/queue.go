@@ -16,6 +16,80 @@ type SchedulingQueue interface {
 	GetMetrics() SchedulingMetrics
 }
 
+// TimeSlicedQueue is implemented by preemptive queues that bound how long a
+// process may hold the CPU before it is put back on the queue
+type TimeSlicedQueue interface {
+	SchedulingQueue
+
+	GetTimeSlice(p Process) time.Duration
+	RequeueProcess(p Process) error
+}
+
+// PreemptiveQueue is implemented by queues that may take the CPU away from the
+// running process as soon as another process arrives
+type PreemptiveQueue interface {
+	SchedulingQueue
+
+	// ShouldPreempt reports whether the arrived process should replace the
+	// running one on the CPU
+	ShouldPreempt(running, arrived Process) bool
+}
+
+// SliceOutcome describes why a process left the CPU
+type SliceOutcome int
+
+const (
+	// SliceCompleted means the task finished
+	SliceCompleted SliceOutcome = iota
+	// SliceExpired means the process used up its time slice
+	SliceExpired
+	// SlicePreempted means the slice was cut short, e.g. by an arrival
+	SlicePreempted
+	// SliceBlocked means the process blocked for I/O
+	SliceBlocked
+	// SliceJobCompleted means a job of a periodic process finished and the
+	// process sleeps until its next job is released
+	SliceJobCompleted
+	// SliceMigrated means the process was taken off the queue before it ran,
+	// to run on another CPU, and the queue should forget it
+	SliceMigrated
+	// SliceFailed means the task returned an error and the process exited
+	// without finishing its work
+	SliceFailed
+)
+
+// FeedbackQueue is implemented by queues that base future decisions on how a
+// process used the CPU. RecordSlice is called every time a process leaves the
+// CPU, before it is returned to the queue
+type FeedbackQueue interface {
+	SchedulingQueue
+
+	RecordSlice(p Process, ran time.Duration, outcome SliceOutcome)
+}
+
+// ClockedQueue is implemented by queues that keep timestamps. SetClock has to
+// be called before the queue is used, as it restarts the metrics of the queue
+type ClockedQueue interface {
+	SchedulingQueue
+
+	SetClock(clock Clock)
+}
+
+// SelectiveQueue is implemented by queues that can hand out a process other
+// than the one at the head, so a CPU can skip processes it may not run
+// without disturbing their place in the queue
+type SelectiveQueue interface {
+	SchedulingQueue
+
+	// DequeueMatching dequeues the first process, in the order Dequeue would
+	// hand them out, that match accepts. The others keep their place
+	DequeueMatching(match func(Process) bool) (Process, error)
+	// Remove takes a queued process off the queue without dispatching it, so
+	// it counts towards no metrics. It is followed by a SliceMigrated
+	// RecordSlice for queues that keep per-process state
+	Remove(p Process) error
+}
+
 type SchedulingMetrics struct {
 	AverageWaitTime   time.Duration
 	AverageTurnaround time.Duration
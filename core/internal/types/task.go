@@ -1,9 +1,68 @@
 package types
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrPreempted is returned by a task that stopped at a checkpoint because its
+// time slice ran out. The task must resume where it left off on the next call
+var ErrPreempted = errors.New("task preempted")
+
+// IOWaitError is returned by a task that has to block for I/O before it can
+// continue. Its process waits for Duration and is then made ready again
+type IOWaitError struct {
+	Duration time.Duration
+}
+
+func (e *IOWaitError) Error() string {
+	return fmt.Sprintf("task blocked for I/O for %v", e.Duration)
+}
+
+// BlockForIO returns the error a task uses to block its process for I/O
+func BlockForIO(d time.Duration) error {
+	return &IOWaitError{Duration: d}
+}
+
 type Task interface {
 	Execute() (any, error)
 }
 
+// PreemptibleTask is a Task that cooperates with time slicing. ExecuteSlice
+// runs until the task completes or ctx is done, in which case it returns
+// ErrPreempted at its next checkpoint
+type PreemptibleTask interface {
+	Task
+	ExecuteSlice(ctx context.Context) (any, error)
+}
+
+// SteppableTask is a resumable task that performs its work in bounded steps.
+// It keeps its resume point in the program counter and registers of the
+// ProcessContext it is stepped with, so it can be paused and resumed along
+// with the process that owns it
+type SteppableTask interface {
+	Task
+
+	// Step performs at most budget units of work from the resume point in ctx
+	// and reports whether the task has finished
+	Step(ctx ProcessContext, budget int) (done bool, err error)
+	// Progress reports the completed fraction of the work, from 0 to 1
+	Progress(ctx ProcessContext) float64
+	// Result returns the outcome of the task once Step has reported done
+	Result() any
+}
+
+// Checkpoint returns ErrPreempted once the slice represented by ctx is over.
+// Long running tasks call it periodically to yield the CPU
+func Checkpoint(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return ErrPreempted
+	}
+	return nil
+}
+
 // SimpleTask is a basic implementation of Task interface
 type SimpleTask struct {
 	ExecuteFn func() (any, error)
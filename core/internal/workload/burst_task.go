@@ -0,0 +1,62 @@
+package workload
+
+import (
+	"context"
+	"cpu-scheduling/core/internal/simulation"
+	"cpu-scheduling/core/internal/types"
+	"sync"
+	"time"
+)
+
+// BurstTask is a types.PreemptibleTask that stands in for the program of a
+// workload process on a real dispatcher. It spends each CPU burst sleeping,
+// stopping early when its slice ends, and then blocks for the I/O that
+// follows the burst
+type BurstTask struct {
+	mu     sync.Mutex
+	bursts []simulation.Burst
+	burst  int
+	// used is the CPU time spent in the current burst so far
+	used time.Duration
+}
+
+func NewBurstTask(bursts []simulation.Burst) *BurstTask {
+	return &BurstTask{bursts: append([]simulation.Burst(nil), bursts...)}
+}
+
+func (t *BurstTask) Execute() (any, error) {
+	return t.ExecuteSlice(context.Background())
+}
+
+// ExecuteSlice runs the current CPU burst until it is done or ctx is. A
+// finished burst returns a types.IOWaitError for its I/O unless it was the
+// last one
+func (t *BurstTask) ExecuteSlice(ctx context.Context) (any, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.burst >= len(t.bursts) {
+		return nil, nil
+	}
+	burst := t.bursts[t.burst]
+
+	start := time.Now()
+	timer := time.NewTimer(burst.CPU - t.used)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		t.used += time.Since(start)
+		if t.used < burst.CPU {
+			return nil, types.ErrPreempted
+		}
+	}
+
+	t.used = 0
+	t.burst++
+	if t.burst < len(t.bursts) {
+		return nil, types.BlockForIO(burst.IO)
+	}
+	return nil, nil
+}
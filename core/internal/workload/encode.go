@@ -0,0 +1,116 @@
+package workload
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileWorkload mirrors the schema Parse reads, so a saved workload loads back
+// unchanged
+type fileWorkload struct {
+	Processes []fileProcess `yaml:"processes" json:"processes"`
+}
+
+type fileProcess struct {
+	Name     string              `yaml:"name,omitempty" json:"name,omitempty"`
+	Arrival  string              `yaml:"arrival,omitempty" json:"arrival,omitempty"`
+	Bursts   []map[string]string `yaml:"bursts,flow" json:"bursts"`
+	Priority *int                `yaml:"priority,omitempty" json:"priority,omitempty"`
+	Nice     *int                `yaml:"nice,omitempty" json:"nice,omitempty"`
+	Deadline string              `yaml:"deadline,omitempty" json:"deadline,omitempty"`
+	User     string              `yaml:"user,omitempty" json:"user,omitempty"`
+	Group    string              `yaml:"group,omitempty" json:"group,omitempty"`
+	Class    string              `yaml:"class,omitempty" json:"class,omitempty"`
+	Tickets  int                 `yaml:"tickets,omitempty" json:"tickets,omitempty"`
+}
+
+// Save writes the workload to a file, as JSON if the path ends in .json and
+// as YAML otherwise
+func (w *Workload) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create workload file: %v", err)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = w.WriteJSON(f)
+	} else {
+		err = w.WriteYAML(f)
+	}
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write workload file: %v", closeErr)
+	}
+	return err
+}
+
+// WriteYAML writes the workload in the format Parse reads
+func (w *Workload) WriteYAML(out io.Writer) error {
+	file, err := w.file()
+	if err != nil {
+		return err
+	}
+
+	enc := yaml.NewEncoder(out)
+	enc.SetIndent(2)
+	if err := enc.Encode(file); err != nil {
+		return fmt.Errorf("failed to encode workload: %v", err)
+	}
+	return enc.Close()
+}
+
+// WriteJSON writes the workload in the format Parse reads
+func (w *Workload) WriteJSON(out io.Writer) error {
+	file, err := w.file()
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(file); err != nil {
+		return fmt.Errorf("failed to encode workload: %v", err)
+	}
+	return nil
+}
+
+func (w *Workload) file() (fileWorkload, error) {
+	file := fileWorkload{Processes: make([]fileProcess, len(w.Processes))}
+	for i, s := range w.Processes {
+		fp := fileProcess{
+			Name:     s.Name,
+			Priority: s.Priority,
+			Nice:     s.Nice,
+			User:     s.User,
+			Group:    s.Group,
+			Tickets:  s.Tickets,
+		}
+		if s.Arrival > 0 {
+			fp.Arrival = s.Arrival.String()
+		}
+		if s.Deadline > 0 {
+			fp.Deadline = s.Deadline.String()
+		}
+		if s.Class != nil {
+			name, err := s.Class.MarshalText()
+			if err != nil {
+				return fileWorkload{}, fmt.Errorf("process %d: %v", i+1, err)
+			}
+			fp.Class = string(name)
+		}
+
+		for j, burst := range s.Bursts {
+			fp.Bursts = append(fp.Bursts, map[string]string{"cpu": burst.CPU.String()})
+			if j < len(s.Bursts)-1 {
+				fp.Bursts = append(fp.Bursts, map[string]string{"io": burst.IO.String()})
+			}
+		}
+		file.Processes[i] = fp
+	}
+	return file, nil
+}
@@ -0,0 +1,75 @@
+package workload
+
+import (
+	"bytes"
+	"cpu-scheduling/core/internal/types"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// withoutLines clears the declaration lines, which depend on the layout of
+// the file a workload was read from
+func withoutLines(w *Workload) *Workload {
+	for i := range w.Processes {
+		w.Processes[i].Line = 0
+	}
+	return w
+}
+
+func TestWorkload_Save(t *testing.T) {
+	t.Run("should load back what it saved as YAML and JSON", func(t *testing.T) {
+		original, err := Parse("mixed.yaml", []byte(mixedYAML))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for _, name := range []string{"saved.yaml", "saved.json"} {
+			path := filepath.Join(t.TempDir(), name)
+			if err := original.Save(path); err != nil {
+				t.Fatalf("unexpected error saving %s: %v", name, err)
+			}
+			loaded, err := Load(path)
+			if err != nil {
+				t.Fatalf("unexpected error loading %s: %v", name, err)
+			}
+			if !reflect.DeepEqual(withoutLines(loaded), withoutLines(original)) {
+				t.Errorf("%s: expected %+v, got %+v", name, original, loaded)
+			}
+		}
+	})
+
+	t.Run("should return error for an unwritable path", func(t *testing.T) {
+		w := &Workload{}
+		if err := w.Save(filepath.Join(t.TempDir(), "missing", "saved.yaml")); err == nil {
+			t.Error("expected error for a missing directory")
+		}
+	})
+}
+
+func TestWorkload_WriteYAML(t *testing.T) {
+	t.Run("should write bursts inline and leave out unset fields", func(t *testing.T) {
+		w, _ := Parse("mixed.yaml", []byte(mixedYAML))
+
+		var buf bytes.Buffer
+		if err := w.WriteYAML(&buf); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		out := buf.String()
+		if !strings.Contains(out, "bursts: [{cpu: 1ms}, {io: 10ms}, {cpu: 2ms}]") {
+			t.Errorf("expected inline bursts, got\n%s", out)
+		}
+		if strings.Contains(out, "arrival: 0s") || strings.Contains(out, "nice: 0") {
+			t.Errorf("expected unset fields to be left out, got\n%s", out)
+		}
+	})
+
+	t.Run("should return error for an unknown class", func(t *testing.T) {
+		class := types.ProcessClass(99)
+		w := &Workload{Processes: []Spec{{Class: &class}}}
+		if err := w.WriteYAML(&bytes.Buffer{}); err == nil {
+			t.Error("expected error for an unknown class")
+		}
+	})
+}
@@ -0,0 +1,268 @@
+package workload
+
+import (
+	"cpu-scheduling/core/internal/process"
+	"cpu-scheduling/core/internal/simulation"
+	"cpu-scheduling/core/internal/types"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Error is a problem at a position in a workload file
+type Error struct {
+	File   string
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Msg)
+}
+
+// Parse reads a workload from YAML or JSON, which YAML includes. The name is
+// used in error messages. Every problem found is reported as an *Error, joined
+// into one error
+func Parse(name string, data []byte) (*Workload, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, &Error{File: name, Line: 1, Column: 1, Msg: "workload is empty"}
+	}
+
+	p := &parser{file: name}
+	w := p.workload(doc.Content[0])
+	if len(p.errs) > 0 {
+		return nil, errors.Join(p.errs...)
+	}
+	return w, nil
+}
+
+// parser walks the YAML tree of a workload and collects every error with its
+// position
+type parser struct {
+	file string
+	errs []error
+}
+
+func (p *parser) errorf(n *yaml.Node, format string, args ...any) {
+	p.errs = append(p.errs, &Error{File: p.file, Line: n.Line, Column: n.Column, Msg: fmt.Sprintf(format, args...)})
+}
+
+// fields calls visit for every key of a mapping node, reporting keys that are
+// not in the schema and keys that appear twice
+func (p *parser) fields(n *yaml.Node, what string, visit func(key string, value *yaml.Node) bool) {
+	if n.Kind != yaml.MappingNode {
+		p.errorf(n, "%s must be a mapping", what)
+		return
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		if seen[key.Value] {
+			p.errorf(key, "duplicate key %q in %s", key.Value, what)
+			continue
+		}
+		seen[key.Value] = true
+
+		if !visit(key.Value, value) {
+			p.errorf(key, "unknown key %q in %s", key.Value, what)
+		}
+	}
+}
+
+func (p *parser) workload(n *yaml.Node) *Workload {
+	w := &Workload{}
+	var processes *yaml.Node
+	p.fields(n, "workload", func(key string, value *yaml.Node) bool {
+		if key != "processes" {
+			return false
+		}
+		processes = value
+		return true
+	})
+
+	switch {
+	case processes == nil:
+		if n.Kind == yaml.MappingNode {
+			p.errorf(n, "workload needs a processes list")
+		}
+	case processes.Kind != yaml.SequenceNode:
+		p.errorf(processes, "processes must be a list")
+	case len(processes.Content) == 0:
+		p.errorf(processes, "processes cannot be empty")
+	default:
+		for i, item := range processes.Content {
+			w.Processes = append(w.Processes, p.spec(i, item))
+		}
+	}
+	return w
+}
+
+func (p *parser) spec(index int, n *yaml.Node) Spec {
+	s := Spec{Line: n.Line}
+	what := fmt.Sprintf("process %d", index+1)
+
+	// check applies an option to a scratch process so that values out of
+	// range are reported at their own line
+	scratch := process.NewPCB(0, &types.SimpleTask{ExecuteFn: func() (any, error) { return nil, nil }})
+	check := func(n *yaml.Node, opt process.ProcessOption) {
+		if err := opt(scratch); err != nil {
+			p.errorf(n, "%v", err)
+		}
+	}
+
+	var bursts, owner *yaml.Node
+	p.fields(n, what, func(key string, value *yaml.Node) bool {
+		switch key {
+		case "name":
+			s.Name = p.str(value)
+		case "arrival":
+			var ok bool
+			if s.Arrival, ok = p.duration(value); ok && s.Arrival < 0 {
+				p.errorf(value, "arrival cannot be negative")
+			}
+		case "bursts":
+			bursts = value
+			s.Bursts = p.bursts(value)
+		case "priority":
+			priority := p.integer(value)
+			s.Priority = &priority
+			check(value, process.WithPriority(priority))
+		case "nice":
+			nice := p.integer(value)
+			s.Nice = &nice
+			check(value, process.WithNice(nice))
+		case "deadline":
+			var ok bool
+			if s.Deadline, ok = p.duration(value); ok && s.Deadline <= 0 {
+				p.errorf(value, "deadline must be positive")
+			}
+		case "user":
+			s.User, owner = p.str(value), value
+		case "group":
+			s.Group, owner = p.str(value), value
+		case "class":
+			var class types.ProcessClass
+			if err := class.UnmarshalText([]byte(p.str(value))); err != nil {
+				p.errorf(value, "%v", err)
+			}
+			s.Class = &class
+		case "tickets":
+			s.Tickets = p.integer(value)
+			check(value, process.WithTickets(s.Tickets))
+		default:
+			return false
+		}
+		return true
+	})
+
+	if bursts == nil && n.Kind == yaml.MappingNode {
+		p.errorf(n, "%s needs bursts", what)
+	}
+	if owner != nil {
+		for _, opt := range s.Options() {
+			check(owner, opt)
+		}
+	}
+	return s
+}
+
+// bursts reads the alternating CPU and I/O bursts of a process, pairing every
+// CPU burst with the I/O after it
+func (p *parser) bursts(n *yaml.Node) []simulation.Burst {
+	if n.Kind != yaml.SequenceNode || len(n.Content) == 0 {
+		p.errorf(n, "bursts must be a non-empty list")
+		return nil
+	}
+
+	var bursts []simulation.Burst
+	var kind string
+	for i, item := range n.Content {
+		wantCPU := i%2 == 0
+		var length time.Duration
+		valid := true
+		kind = ""
+		p.fields(item, "burst", func(key string, value *yaml.Node) bool {
+			if key != "cpu" && key != "io" {
+				return false
+			}
+			if kind != "" {
+				p.errorf(value, "burst has both cpu and io")
+			}
+			kind = key
+			length, valid = p.duration(value)
+			return true
+		})
+
+		switch {
+		case kind == "":
+			if item.Kind == yaml.MappingNode {
+				p.errorf(item, "burst needs cpu or io")
+			}
+			continue
+		case wantCPU && kind != "cpu":
+			p.errorf(item, "burst %d must be cpu, bursts alternate starting with cpu", i+1)
+		case !wantCPU && kind != "io":
+			p.errorf(item, "burst %d must be io, bursts alternate starting with cpu", i+1)
+		case valid && kind == "cpu" && length <= 0:
+			p.errorf(item, "cpu burst must be positive")
+		case valid && kind == "io" && length < 0:
+			p.errorf(item, "io burst cannot be negative")
+		}
+
+		if wantCPU {
+			bursts = append(bursts, simulation.Burst{CPU: length})
+		} else if len(bursts) > 0 {
+			bursts[len(bursts)-1].IO = length
+		}
+	}
+
+	if kind == "io" {
+		p.errorf(n.Content[len(n.Content)-1], "bursts must end with cpu")
+	}
+	return bursts
+}
+
+func (p *parser) str(n *yaml.Node) string {
+	if n.Kind != yaml.ScalarNode || n.Tag == "!!null" {
+		p.errorf(n, "expected a string")
+		return ""
+	}
+	return n.Value
+}
+
+func (p *parser) integer(n *yaml.Node) int {
+	if n.Kind != yaml.ScalarNode || n.Tag != "!!int" {
+		p.errorf(n, "expected an integer, got %q", n.Value)
+		return 0
+	}
+	v, err := strconv.Atoi(n.Value)
+	if err != nil {
+		p.errorf(n, "expected an integer, got %q", n.Value)
+	}
+	return v
+}
+
+// duration reads a Go duration like "1.5ms". Bare numbers other than 0 are
+// rejected, as their unit would be a guess. It reports whether the value was
+// a duration
+func (p *parser) duration(n *yaml.Node) (time.Duration, bool) {
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!int" && n.Value == "0" {
+		return 0, true
+	}
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!str" {
+		if d, err := time.ParseDuration(n.Value); err == nil {
+			return d, true
+		}
+	}
+	p.errorf(n, "expected a duration like 10ms, got %q", n.Value)
+	return 0, false
+}
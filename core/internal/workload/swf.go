@@ -0,0 +1,243 @@
+package workload
+
+import (
+	"bufio"
+	"compress/gzip"
+	"cpu-scheduling/core/internal/simulation"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fields of a job line in the Standard Workload Format used by the Parallel
+// Workloads Archive, counted from 0. A value of -1 means unknown
+const (
+	swfJob = iota
+	swfSubmit
+	swfWait
+	swfRunTime
+	swfAllocatedProcessors
+	swfAverageCPUTime
+	swfUsedMemory
+	swfRequestedProcessors
+	swfRequestedTime
+	swfRequestedMemory
+	swfStatus
+	swfUser
+	swfGroup
+	swfExecutable
+	swfQueue
+	swfPartition
+	swfPrecedingJob
+	swfThinkTime
+	swfFields
+)
+
+// SWFOptions selects the jobs of an SWF trace and how they become processes.
+// Only jobs submitted in [From, Until) after the start of the trace are kept,
+// with no upper bound when Until is zero, and only those of the listed users
+// and queues when the lists are not empty. Arrivals are counted from From.
+// TimeUnit is what a second of the trace becomes and defaults to a second, so
+// that long traces can be replayed faster. A job on several processors becomes
+// one process doing all of its work, or one process per processor when
+// PerProcessor is set. MaxProcessors caps how many processes a job becomes,
+// DefaultSWFMaxProcessors when zero: a job on more processors is split into
+// that many processes sharing its work, so that huge jobs cannot flood the
+// workload
+type SWFOptions struct {
+	From          time.Duration
+	Until         time.Duration
+	Users         []int
+	Queues        []int
+	TimeUnit      time.Duration
+	PerProcessor  bool
+	MaxProcessors int
+}
+
+// DefaultSWFMaxProcessors is how many processes a job becomes at most when
+// PerProcessor is set and MaxProcessors is not
+const DefaultSWFMaxProcessors = 64
+
+// LoadSWF reads a workload from an SWF trace file, which may be gzipped as
+// the archive publishes them
+func LoadSWF(path string, options SWFOptions) (*Workload, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trace: %v", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress trace: %v", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	return ParseSWF(path, r, options)
+}
+
+// ParseSWF reads the jobs of an SWF trace as processes named after their job
+// number. Each process has a single CPU burst of the run time of its job,
+// belongs to user<id> in group /queue<id>, and its Line is where the job is
+// in the trace. Jobs that never ran have no run time and are left out. The
+// name is used in error messages, and every malformed line is reported as an
+// *Error, joined into one error
+func ParseSWF(name string, r io.Reader, options SWFOptions) (*Workload, error) {
+	if options.TimeUnit == 0 {
+		options.TimeUnit = time.Second
+	}
+	if options.TimeUnit < 0 || options.From < 0 || options.Until < 0 {
+		return nil, fmt.Errorf("time unit and window cannot be negative")
+	}
+	if options.MaxProcessors == 0 {
+		options.MaxProcessors = DefaultSWFMaxProcessors
+	}
+	if options.MaxProcessors < 0 {
+		return nil, fmt.Errorf("max processors cannot be negative, got %d", options.MaxProcessors)
+	}
+
+	w := &Workload{}
+	var errs []error
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if trimmed := strings.TrimSpace(text); trimmed == "" || strings.HasPrefix(trimmed, ";") {
+			continue
+		}
+
+		specs, err := swfJobSpecs(text, options)
+		if err != nil {
+			err.File, err.Line = name, line
+			errs = append(errs, err)
+			continue
+		}
+		for _, s := range specs {
+			s.Line = line
+			w.Processes = append(w.Processes, s)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read trace %s: %v", name, err)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if len(w.Processes) == 0 {
+		return nil, fmt.Errorf("%s: no job of the trace matches the options", name)
+	}
+	return w, nil
+}
+
+// swfJobSpecs turns a job line into the specs of its processes, none if the
+// job is filtered out. The returned error has no file and line yet
+func swfJobSpecs(text string, options SWFOptions) ([]Spec, *Error) {
+	fields, columns := swfSplit(text)
+	if len(fields) != swfFields {
+		return nil, &Error{Column: 1, Msg: fmt.Sprintf("expected %d fields, got %d", swfFields, len(fields))}
+	}
+
+	values := make([]float64, swfFields)
+	for i, field := range fields {
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, &Error{Column: columns[i], Msg: fmt.Sprintf("expected a number, got %q", field)}
+		}
+		values[i] = v
+	}
+
+	submit := seconds(values[swfSubmit], time.Second)
+	user, queue := int(values[swfUser]), int(values[swfQueue])
+	switch {
+	case values[swfRunTime] <= 0:
+		return nil, nil
+	case submit < options.From || (options.Until > 0 && submit >= options.Until):
+		return nil, nil
+	case len(options.Users) > 0 && !slices.Contains(options.Users, user):
+		return nil, nil
+	case len(options.Queues) > 0 && !slices.Contains(options.Queues, queue):
+		return nil, nil
+	}
+
+	processors := int(values[swfRequestedProcessors])
+	if processors <= 0 {
+		processors = int(values[swfAllocatedProcessors])
+	}
+	processors = max(processors, 1)
+
+	s := Spec{
+		Name:    fmt.Sprintf("job-%d", int(values[swfJob])),
+		Arrival: seconds(values[swfSubmit], options.TimeUnit) - seconds(options.From.Seconds(), options.TimeUnit),
+	}
+	if user >= 0 {
+		s.User = fmt.Sprintf("user%d", user)
+	}
+	if queue >= 0 {
+		s.Group = fmt.Sprintf("/queue%d", queue)
+	}
+
+	// The work of the job has to fit in a time.Duration, which archive jobs
+	// running for days on many thousands of processors do not at a second
+	if values[swfRunTime]*float64(options.TimeUnit) >= math.MaxInt64 ||
+		seconds(values[swfRunTime], options.TimeUnit) > math.MaxInt64/time.Duration(processors) {
+		return nil, &Error{Column: columns[swfRunTime], Msg: fmt.Sprintf("run time of %v on %d processors is too long, use a smaller time unit", fields[swfRunTime], processors)}
+	}
+	runTime := max(seconds(values[swfRunTime], options.TimeUnit), 1)
+	if !options.PerProcessor {
+		s.Bursts = []simulation.Burst{{CPU: runTime * time.Duration(processors)}}
+		return []Spec{s}, nil
+	}
+
+	// Beyond MaxProcessors the work of the job is shared out evenly, the
+	// first processes taking the nanoseconds that do not divide
+	n := min(processors, options.MaxProcessors)
+	work := runTime * time.Duration(processors)
+	share, rest := work/time.Duration(n), work%time.Duration(n)
+	specs := make([]Spec, n)
+	for i := range specs {
+		specs[i] = s
+		specs[i].Name = fmt.Sprintf("%s.%d", s.Name, i)
+		cpu := share
+		if time.Duration(i) < rest {
+			cpu++
+		}
+		specs[i].Bursts = []simulation.Burst{{CPU: cpu}}
+	}
+	return specs, nil
+}
+
+// swfSplit splits a line into its whitespace separated fields and the column
+// each one starts at
+func swfSplit(text string) ([]string, []int) {
+	var fields []string
+	var columns []int
+	start := -1
+	for i := 0; i <= len(text); i++ {
+		space := i == len(text) || text[i] == ' ' || text[i] == '\t'
+		switch {
+		case !space && start < 0:
+			start = i
+		case space && start >= 0:
+			fields = append(fields, text[start:i])
+			columns = append(columns, start+1)
+			start = -1
+		}
+	}
+	return fields, columns
+}
+
+// seconds converts a number of trace seconds to a duration in the given unit,
+// rounded to the nanosecond
+func seconds(s float64, unit time.Duration) time.Duration {
+	return time.Duration(math.Round(s * float64(unit)))
+}
@@ -0,0 +1,239 @@
+package workload
+
+import (
+	"compress/gzip"
+	"cpu-scheduling/core/internal/process"
+	"cpu-scheduling/core/internal/queue"
+	"cpu-scheduling/core/internal/simulation"
+	"cpu-scheduling/core/internal/types"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const trace = `; Version: 2.2
+; Computer: test cluster
+; MaxProcs: 4
+;
+1   0  5  24  1  -1 -1  1  30 -1 1 3 1 -1 0 -1 -1 -1
+2   1  4   3  2  -1 -1 -1  10 -1 1 7 1 -1 1 -1 -1 -1
+3   2  6   3  1  -1 -1  1  10 -1 1 3 1 -1 1 -1 -1 -1
+4   3 -1  -1  1  -1 -1  1  10 -1 5 7 1 -1 1 -1 -1 -1
+5  90  0   2  1  -1 -1  1  10 -1 1 9 1 -1 -1 -1 -1 -1
+`
+
+func TestParseSWF(t *testing.T) {
+	t.Run("should map jobs to processes", func(t *testing.T) {
+		w, err := ParseSWF("trace.swf", strings.NewReader(trace), SWFOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(w.Processes) != 4 {
+			t.Fatalf("expected the job that never ran to be left out, got %d processes", len(w.Processes))
+		}
+
+		first := w.Processes[0]
+		if first.Name != "job-1" || first.Line != 5 || first.Arrival != 0 || first.Bursts[0].CPU != 24*time.Second {
+			t.Errorf("unexpected first job %+v", first)
+		}
+		if first.User != "user3" || first.Group != "/queue0" {
+			t.Errorf("expected user3 in /queue0, got %s in %s", first.User, first.Group)
+		}
+
+		second := w.Processes[1]
+		if second.Arrival != time.Second || second.Bursts[0].CPU != 6*time.Second {
+			t.Errorf("expected the allocated processors to double the work, got %+v", second)
+		}
+
+		last := w.Processes[3]
+		if last.Group != "" {
+			t.Errorf("expected no group for an unknown queue, got %q", last.Group)
+		}
+
+		p, err := last.Create(process.NewManager())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if owner := p.GetOwner(); owner.User != "user9" || owner.Group != types.RootGroup {
+			t.Errorf("unexpected owner %+v", owner)
+		}
+	})
+
+	t.Run("should filter by time window, user and queue", func(t *testing.T) {
+		w, err := ParseSWF("trace.swf", strings.NewReader(trace), SWFOptions{From: time.Second, Until: time.Minute})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(w.Processes) != 2 || w.Processes[0].Name != "job-2" || w.Processes[1].Name != "job-3" {
+			t.Fatalf("expected jobs 2 and 3, got %+v", w.Processes)
+		}
+		if w.Processes[0].Arrival != 0 || w.Processes[1].Arrival != time.Second {
+			t.Errorf("expected arrivals counted from the window, got %v and %v", w.Processes[0].Arrival, w.Processes[1].Arrival)
+		}
+
+		w, _ = ParseSWF("trace.swf", strings.NewReader(trace), SWFOptions{Users: []int{3}, Queues: []int{1}})
+		if len(w.Processes) != 1 || w.Processes[0].Name != "job-3" {
+			t.Errorf("expected only job 3, got %+v", w.Processes)
+		}
+
+		if _, err := ParseSWF("trace.swf", strings.NewReader(trace), SWFOptions{Users: []int{42}}); err == nil {
+			t.Error("expected error when no job matches")
+		}
+	})
+
+	t.Run("should scale time and split jobs per processor", func(t *testing.T) {
+		w, _ := ParseSWF("trace.swf", strings.NewReader(trace), SWFOptions{TimeUnit: time.Millisecond, PerProcessor: true})
+		if len(w.Processes) != 5 {
+			t.Fatalf("expected job 2 to become two processes, got %d processes", len(w.Processes))
+		}
+		for _, s := range w.Processes[1:3] {
+			if !strings.HasPrefix(s.Name, "job-2.") || s.Arrival != time.Millisecond || s.Bursts[0].CPU != 3*time.Millisecond {
+				t.Errorf("unexpected process %+v of job 2", s)
+			}
+		}
+		if last := w.Processes[4]; last.Arrival != 90*time.Millisecond {
+			t.Errorf("expected job 5 to arrive at 90ms, got %v", last.Arrival)
+		}
+	})
+
+	t.Run("should share the work of a job beyond the processor cap", func(t *testing.T) {
+		huge := "1 0 0 10 100000 -1 -1 100000 10 -1 1 1 1 -1 1 -1 -1 -1\n"
+		w, err := ParseSWF("huge.swf", strings.NewReader(huge), SWFOptions{PerProcessor: true, MaxProcessors: 3})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(w.Processes) != 3 {
+			t.Fatalf("expected the job to become 3 processes, got %d", len(w.Processes))
+		}
+		var total time.Duration
+		for _, s := range w.Processes {
+			total += s.Bursts[0].CPU
+		}
+		if total != 10*100000*time.Second {
+			t.Errorf("expected the processes to share all of the work, got %v", total)
+		}
+
+		w, _ = ParseSWF("huge.swf", strings.NewReader(huge), SWFOptions{PerProcessor: true})
+		if len(w.Processes) != DefaultSWFMaxProcessors {
+			t.Errorf("expected the default cap of %d processes, got %d", DefaultSWFMaxProcessors, len(w.Processes))
+		}
+	})
+
+	t.Run("should report malformed lines with their position", func(t *testing.T) {
+		_, err := ParseSWF("bad.swf", strings.NewReader("; header\n1 0 5\n2 1 4 x 2 -1 -1 -1 10 -1 1 7 1 -1 1 -1 -1 -1\n"), SWFOptions{})
+
+		var errs []string
+		for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+			var swfErr *Error
+			if !errors.As(e, &swfErr) {
+				t.Fatalf("expected *Error, got %T", e)
+			}
+			errs = append(errs, swfErr.Error())
+		}
+		expected := []string{
+			"bad.swf:2:1: expected 18 fields, got 3",
+			`bad.swf:3:7: expected a number, got "x"`,
+		}
+		if strings.Join(errs, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("expected errors\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(errs, "\n"))
+		}
+	})
+
+	t.Run("should report jobs whose work overflows", func(t *testing.T) {
+		huge := "1 0 0 86400 163840 -1 -1 163840 10 -1 1 1 1 -1 1 -1 -1 -1\n"
+		_, err := ParseSWF("huge.swf", strings.NewReader(huge), SWFOptions{})
+
+		var swfErr *Error
+		if !errors.As(err, &swfErr) || swfErr.Line != 1 || swfErr.Column != 7 {
+			t.Fatalf("expected an *Error at the run time, got %v", err)
+		}
+
+		w, err := ParseSWF("huge.swf", strings.NewReader(huge), SWFOptions{TimeUnit: time.Millisecond})
+		if err != nil {
+			t.Fatalf("unexpected error with a smaller time unit: %v", err)
+		}
+		if cpu := w.Processes[0].Bursts[0].CPU; cpu != 86400*163840*time.Millisecond {
+			t.Errorf("expected all of the work in one burst, got %v", cpu)
+		}
+	})
+
+	t.Run("should return error for a negative time unit", func(t *testing.T) {
+		if _, err := ParseSWF("trace.swf", strings.NewReader(trace), SWFOptions{TimeUnit: -time.Second}); err == nil {
+			t.Error("expected error for a negative time unit")
+		}
+	})
+
+	t.Run("should return error for a negative processor cap", func(t *testing.T) {
+		if _, err := ParseSWF("trace.swf", strings.NewReader(trace), SWFOptions{PerProcessor: true, MaxProcessors: -1}); err == nil {
+			t.Error("expected error for a negative processor cap")
+		}
+	})
+}
+
+func TestLoadSWF(t *testing.T) {
+	t.Run("should read plain and gzipped traces", func(t *testing.T) {
+		dir := t.TempDir()
+		plain := filepath.Join(dir, "trace.swf")
+		if err := os.WriteFile(plain, []byte(trace), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		compressed := filepath.Join(dir, "trace.swf.gz")
+		f, err := os.Create(compressed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gz := gzip.NewWriter(f)
+		gz.Write([]byte(trace))
+		gz.Close()
+		f.Close()
+
+		for _, path := range []string{plain, compressed} {
+			w, err := LoadSWF(path, SWFOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error for %s: %v", path, err)
+			}
+			if len(w.Processes) != 4 {
+				t.Errorf("expected 4 processes from %s, got %d", path, len(w.Processes))
+			}
+		}
+	})
+
+	t.Run("should return error for a missing file", func(t *testing.T) {
+		if _, err := LoadSWF(filepath.Join(t.TempDir(), "missing.swf"), SWFOptions{}); err == nil {
+			t.Error("expected error for a missing file")
+		}
+	})
+}
+
+func TestSWF_Simulation(t *testing.T) {
+	t.Run("should replay a trace through different policies", func(t *testing.T) {
+		w, _ := ParseSWF("trace.swf", strings.NewReader(trace), SWFOptions{Until: time.Minute, TimeUnit: time.Millisecond})
+
+		run := func(q types.SchedulingQueue) *simulation.Result {
+			s, err := simulation.New(simulation.Config{Queue: q, Jobs: w.Jobs()})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			result, err := s.Run()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			return result
+		}
+
+		// Jobs of 24ms, 6ms and 3ms arriving at 0, 1ms and 2ms, as in the
+		// textbook example
+		fcfs := run(queue.NewFCFSQueue())
+		rr := run(queue.NewRoundRobinQueue(4 * time.Millisecond))
+		if fcfs.Makespan != 33*time.Millisecond || rr.Makespan != 33*time.Millisecond {
+			t.Errorf("expected both policies to finish at 33ms, got %v and %v", fcfs.Makespan, rr.Makespan)
+		}
+		if rr.AverageTurnaround >= fcfs.AverageTurnaround {
+			t.Errorf("expected round robin to help the short jobs, got %v against %v", rr.AverageTurnaround, fcfs.AverageTurnaround)
+		}
+	})
+}
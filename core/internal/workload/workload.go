@@ -0,0 +1,142 @@
+package workload
+
+import (
+	"cpu-scheduling/core/internal/process"
+	"cpu-scheduling/core/internal/simulation"
+	"cpu-scheduling/core/internal/types"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// Workload is a set of processes described as data, loaded from a YAML or
+// JSON file like
+//
+//	processes:
+//	  - name: editor
+//	    arrival: 5ms
+//	    bursts: [{cpu: 2ms}, {io: 30ms}, {cpu: 1ms}]
+//	    priority: 10
+//	    nice: -5
+//	    deadline: 50ms
+//	    user: alice
+//	    group: /staff
+//	    class: interactive
+//	    tickets: 200
+//
+// Only bursts is required. They alternate between CPU and I/O, starting and
+// ending with CPU
+type Workload struct {
+	Processes []Spec
+}
+
+// Spec describes one process of a workload. Fields left out of the file keep
+// the defaults of process.NewPCB, and Line is where the process is declared
+type Spec struct {
+	Name     string
+	Line     int
+	Arrival  time.Duration
+	Bursts   []simulation.Burst
+	Priority *int
+	Nice     *int
+	Deadline time.Duration
+	User     string
+	Group    string
+	Class    *types.ProcessClass
+	Tickets  int
+}
+
+// Load reads a workload from a YAML or JSON file
+func Load(path string) (*Workload, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workload: %v", err)
+	}
+	return Parse(path, data)
+}
+
+// Options returns the process options that configure a process as described
+func (s Spec) Options() []process.ProcessOption {
+	var opts []process.ProcessOption
+	if s.Priority != nil {
+		opts = append(opts, process.WithPriority(*s.Priority))
+	}
+	if s.Nice != nil {
+		opts = append(opts, process.WithNice(*s.Nice))
+	}
+	if s.Deadline > 0 {
+		opts = append(opts, process.WithDeadline(s.Deadline))
+	}
+	if s.User != "" || s.Group != "" {
+		user, group := s.User, s.Group
+		if user == "" {
+			user = types.DefaultUser
+		}
+		if group == "" {
+			group = types.RootGroup
+		}
+		opts = append(opts, process.WithOwner(user, group))
+	}
+	if s.Class != nil {
+		opts = append(opts, process.WithClass(*s.Class))
+	}
+	if s.Tickets > 0 {
+		opts = append(opts, process.WithTickets(s.Tickets))
+	}
+	return opts
+}
+
+// Create creates the process in the manager with a BurstTask that runs its
+// bursts in real time
+func (s Spec) Create(manager *process.Manager) (types.Process, error) {
+	return manager.CreateProcess(NewBurstTask(s.Bursts), s.Options()...)
+}
+
+// Jobs returns the workload as jobs for the simulator, which creates each
+// process when it arrives
+func (w *Workload) Jobs() []simulation.Job {
+	jobs := make([]simulation.Job, len(w.Processes))
+	for i, s := range w.Processes {
+		jobs[i] = simulation.Job{
+			Name:    s.Name,
+			Arrival: s.Arrival,
+			Bursts:  append([]simulation.Burst(nil), s.Bursts...),
+			Options: s.Options(),
+		}
+	}
+	return jobs
+}
+
+// Replay creates the processes of the workload in the manager at their
+// arrival times, counted from the call, and hands each one to submit, e.g.
+// the Submit method of a dispatcher. The processes spend their CPU bursts in
+// real time. Replay returns the processes in workload order once the last
+// one has arrived. On error it stops and returns the processes submitted so
+// far, as they keep running, leaving the others nil
+func (w *Workload) Replay(manager *process.Manager, submit func(types.Process) error) ([]types.Process, error) {
+	order := make([]int, len(w.Processes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return w.Processes[order[a]].Arrival < w.Processes[order[b]].Arrival
+	})
+
+	start := time.Now()
+	processes := make([]types.Process, len(w.Processes))
+	for _, i := range order {
+		s := w.Processes[i]
+		time.Sleep(time.Until(start.Add(s.Arrival)))
+
+		p, err := s.Create(manager)
+		if err != nil {
+			return processes, fmt.Errorf("failed to create process %d: %v", i, err)
+		}
+		if err := submit(p); err != nil {
+			return processes, fmt.Errorf("failed to submit process %d: %v", i, err)
+		}
+		processes[i] = p
+	}
+	return processes, nil
+}
@@ -0,0 +1,263 @@
+package workload
+
+import (
+	"cpu-scheduling/core/internal/process"
+	"cpu-scheduling/core/internal/queue"
+	"cpu-scheduling/core/internal/scheduler"
+	"cpu-scheduling/core/internal/simulation"
+	"cpu-scheduling/core/internal/types"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const mixedYAML = `
+processes:
+  - name: editor
+    arrival: 2ms
+    bursts: [{cpu: 1ms}, {io: 10ms}, {cpu: 2ms}]
+    priority: 10
+    nice: -5
+    user: alice
+    class: interactive
+  - name: compiler
+    bursts:
+      - cpu: 20ms
+    deadline: 100ms
+    group: /build
+    tickets: 300
+`
+
+const mixedJSON = `{
+  "processes": [
+    {"name": "editor", "arrival": "2ms", "bursts": [{"cpu": "1ms"}, {"io": "10ms"}, {"cpu": "2ms"}],
+     "priority": 10, "nice": -5, "user": "alice", "class": "interactive"},
+    {"name": "compiler", "bursts": [{"cpu": "20ms"}], "deadline": "100ms", "group": "/build", "tickets": 300}
+  ]
+}`
+
+func TestParse(t *testing.T) {
+	t.Run("should read YAML and JSON alike", func(t *testing.T) {
+		fromYAML, err := Parse("mixed.yaml", []byte(mixedYAML))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		fromJSON, err := Parse("mixed.json", []byte(mixedJSON))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		editor := fromYAML.Processes[0]
+		if editor.Name != "editor" || editor.Arrival != 2*time.Millisecond || editor.Line != 3 {
+			t.Errorf("unexpected editor spec %+v", editor)
+		}
+		expected := []simulation.Burst{{CPU: time.Millisecond, IO: 10 * time.Millisecond}, {CPU: 2 * time.Millisecond}}
+		if !reflect.DeepEqual(editor.Bursts, expected) {
+			t.Errorf("expected bursts %v, got %v", expected, editor.Bursts)
+		}
+		if *editor.Priority != 10 || *editor.Nice != -5 || editor.User != "alice" || *editor.Class != types.INTERACTIVE {
+			t.Errorf("unexpected editor options %+v", editor)
+		}
+
+		for i := range fromYAML.Processes {
+			a, b := fromYAML.Processes[i], fromJSON.Processes[i]
+			a.Line, b.Line = 0, 0
+			if !reflect.DeepEqual(a, b) {
+				t.Errorf("process %d: YAML %+v differs from JSON %+v", i, a, b)
+			}
+		}
+	})
+
+	t.Run("should configure processes from the spec", func(t *testing.T) {
+		w, _ := Parse("mixed.yaml", []byte(mixedYAML))
+		manager := process.NewManager()
+
+		editor, err := manager.CreateProcess(NewBurstTask(nil), w.Processes[0].Options()...)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if editor.GetPriority() != 10 || editor.GetNice() != -5 || editor.GetClass() != types.INTERACTIVE {
+			t.Errorf("expected priority 10, nice -5 and interactive, got %d, %d and %v",
+				editor.GetPriority(), editor.GetNice(), editor.GetClass())
+		}
+		if owner := editor.GetOwner(); owner.User != "alice" || owner.Group != types.RootGroup {
+			t.Errorf("expected alice in the root group, got %+v", owner)
+		}
+
+		compiler, _ := manager.CreateProcess(NewBurstTask(nil), w.Processes[1].Options()...)
+		if compiler.GetRelativeDeadline() != 100*time.Millisecond || compiler.GetTickets() != 300 {
+			t.Errorf("expected a 100ms deadline and 300 tickets, got %v and %d",
+				compiler.GetRelativeDeadline(), compiler.GetTickets())
+		}
+		if owner := compiler.GetOwner(); owner.User != types.DefaultUser || owner.Group != "/build" {
+			t.Errorf("expected the default user in /build, got %+v", owner)
+		}
+	})
+
+	t.Run("should report every schema error with its line", func(t *testing.T) {
+		data := `processes:
+  - name: a
+    bursts: [{cpu: 1ms}, {cpu: 2ms}]
+  - bursts: [{cpu: 5}]
+    priority: 1000
+  - name: c
+    bursts: [{cpu: 1ms}, {io: 1ms}]
+    colour: blue
+  - arrival: -1ms
+`
+		_, err := Parse("bad.yaml", []byte(data))
+		if err == nil {
+			t.Fatal("expected errors")
+		}
+
+		expected := []string{
+			"bad.yaml:3:26: burst 2 must be io",
+			"bad.yaml:4:20: expected a duration like 10ms",
+			"bad.yaml:5:15: priority must be between",
+			"bad.yaml:7:26: bursts must end with cpu",
+			"bad.yaml:8:5: unknown key \"colour\"",
+			"bad.yaml:9:14: arrival cannot be negative",
+			"bad.yaml:9:5: process 4 needs bursts",
+		}
+		lines := strings.Split(err.Error(), "\n")
+		if len(lines) != len(expected) {
+			t.Fatalf("expected %d errors, got %d:\n%v", len(expected), len(lines), err)
+		}
+		for i, prefix := range expected {
+			if !strings.HasPrefix(lines[i], prefix) {
+				t.Errorf("expected error starting with %q, got %q", prefix, lines[i])
+			}
+		}
+
+		var positioned *Error
+		if !errors.As(err, &positioned) || positioned.Line != 3 {
+			t.Errorf("expected positioned errors, got %v", err)
+		}
+	})
+
+	t.Run("should return error for malformed or empty documents", func(t *testing.T) {
+		for name, data := range map[string]string{
+			"syntax":     "processes: [",
+			"empty":      "",
+			"no list":    "processes: 3",
+			"empty list": "processes: []",
+			"not a map":  "- cpu: 1ms",
+		} {
+			if _, err := Parse(name, []byte(data)); err == nil {
+				t.Errorf("%s: expected error", name)
+			}
+		}
+	})
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("should read a workload file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "mixed.json")
+		os.WriteFile(path, []byte(mixedJSON), 0o644)
+
+		w, err := Load(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(w.Processes) != 2 {
+			t.Errorf("expected 2 processes, got %d", len(w.Processes))
+		}
+	})
+
+	t.Run("should return error for a missing file", func(t *testing.T) {
+		if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+			t.Error("expected error for missing file")
+		}
+	})
+}
+
+func TestWorkload_Jobs(t *testing.T) {
+	t.Run("should schedule the arrivals in the simulator", func(t *testing.T) {
+		w, _ := Parse("mixed.yaml", []byte(mixedYAML))
+
+		s, err := simulation.New(simulation.Config{Queue: queue.NewFCFSQueue(), Jobs: w.Jobs()})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		result, err := s.Run()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		editor, compiler := result.Jobs[0], result.Jobs[1]
+		if compiler.Finish != 20*time.Millisecond || editor.FirstRun != 20*time.Millisecond {
+			t.Errorf("expected the compiler to run first, got %+v and %+v", compiler, editor)
+		}
+		if editor.Name != "editor" || editor.Finish != 33*time.Millisecond {
+			t.Errorf("expected the editor to finish after its I/O at 33ms, got %+v", editor)
+		}
+	})
+}
+
+func TestWorkload_Replay(t *testing.T) {
+	t.Run("should submit processes to a dispatcher at their arrival", func(t *testing.T) {
+		w, _ := Parse("replay.yaml", []byte(`
+processes:
+  - arrival: 10ms
+    bursts: [{cpu: 2ms}, {io: 5ms}, {cpu: 2ms}]
+  - bursts: [{cpu: 3ms}]
+`))
+		manager := process.NewManager()
+		d, _ := scheduler.NewDispatcher(queue.NewRoundRobinQueue(time.Millisecond), manager)
+		d.Start()
+		defer d.Stop()
+
+		start := time.Now()
+		processes, err := w.Replay(manager, d.Submit)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+			t.Errorf("expected replay to wait for the last arrival, took %v", elapsed)
+		}
+		d.Wait()
+
+		if processes[0].GetPID() != 2 || processes[1].GetPID() != 1 {
+			t.Errorf("expected processes to be created in arrival order")
+		}
+		for _, p := range processes {
+			if p.GetState() != types.TERMINATED {
+				t.Errorf("expected process %d to terminate, got state %d", p.GetPID(), p.GetState())
+			}
+		}
+		if history := processes[0].GetBurstHistory(); len(history) != 2 || history[0] < 2*time.Millisecond {
+			t.Errorf("expected two CPU bursts of at least 2ms, got %v", history)
+		}
+	})
+
+	t.Run("should return the processes submitted before an error", func(t *testing.T) {
+		w, _ := Parse("replay.yaml", []byte(`
+processes:
+  - bursts: [{cpu: 1ms}]
+  - arrival: 1ms
+    bursts: [{cpu: 1ms}]
+  - arrival: 2ms
+    bursts: [{cpu: 1ms}]
+`))
+		manager := process.NewManager()
+		submitted := 0
+		processes, err := w.Replay(manager, func(p types.Process) error {
+			if submitted == 1 {
+				return fmt.Errorf("queue is full")
+			}
+			submitted++
+			return nil
+		})
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if len(processes) != 3 || processes[0] == nil || processes[1] != nil || processes[2] != nil {
+			t.Errorf("expected only the first process, got %v", processes)
+		}
+	})
+}